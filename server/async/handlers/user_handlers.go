@@ -3,10 +3,11 @@ package handlers
 import (
 	"ToDoList/server/async"
 	"ToDoList/server/service"
-	"ToDoList/server/utils"
+	"ToDoList/server/storage"
 	"context"
 	"encoding/json"
 	"go.uber.org/zap"
+	"time"
 )
 
 type cosDeletePayload struct {
@@ -21,6 +22,16 @@ type avatarKeyDel struct {
 	UID int `json:"uid"`
 }
 
+type touchAccessToken struct {
+	TokenID int       `json:"tokenId"`
+	At      time.Time `json:"at"`
+}
+
+type buildExport struct {
+	UID      int    `json:"uid"`
+	ExportID string `json:"exportId"`
+}
+
 type putVersion struct {
 	UID          int `json:"uid"`
 	TokenVersion int `json:"tokenVersion"`
@@ -36,7 +47,7 @@ func DeleteCosObject(ctx context.Context, job async.Job, lg *zap.Logger) error {
 		lg.Error(job.Type + job.TraceID + "cosKey is nil")
 		return nil
 	}
-	err := utils.DeleteObject(ctx, p.Key)
+	err := storage.Default.Delete(ctx, p.Key)
 	service.PutTraceID(ctx, job.Type, job.TraceID, err)
 	return err
 }
@@ -74,3 +85,33 @@ func PutVersion(ctx context.Context, job async.Job, lg *zap.Logger) error {
 	service.PutTraceID(ctx, job.Type, job.TraceID, err)
 	return err
 }
+
+func TouchAccessToken(ctx context.Context, job async.Job, lg *zap.Logger) error {
+	var p touchAccessToken
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		lg.Error(job.Type+"Payload Unmarshal is err", zap.Error(err))
+		return nil
+	}
+	if p.TokenID <= 0 {
+		lg.Error(job.Type + job.TraceID + "TokenID <= 0")
+		return nil
+	}
+	err := service.TouchAccessToken(ctx, p.TokenID, p.At)
+	service.PutTraceID(ctx, job.Type, job.TraceID, err)
+	return err
+}
+
+func BuildExport(ctx context.Context, job async.Job, lg *zap.Logger) error {
+	var p buildExport
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		lg.Error(job.Type+"Payload Unmarshal is err", zap.Error(err))
+		return nil
+	}
+	if p.UID <= 0 || p.ExportID == "" {
+		lg.Error(job.Type + job.TraceID + "UID <= 0 or exportId is nil")
+		return nil
+	}
+	err := service.BuildUserExport(ctx, lg, p.UID, p.ExportID)
+	service.PutTraceID(ctx, job.Type, job.TraceID, err)
+	return err
+}
@@ -0,0 +1,6 @@
+package config
+
+var (
+	// AccountDeletionGrace 申请注销后的宽限期，期间重新登录即撤销注销
+	AccountDeletionGrace = mustParseDuration(getenv("ACCOUNT_DELETION_GRACE", "168h"))
+)
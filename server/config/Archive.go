@@ -0,0 +1,8 @@
+package config
+
+var (
+	// TaskArchiveAfter 任务完成超过该时长后自动归档，0 表示不自动归档
+	TaskArchiveAfter = mustParseDuration(getenv("TASK_ARCHIVE_AFTER", "168h"))
+	// TaskArchiveInterval 自动归档的执行间隔
+	TaskArchiveInterval = mustParseDuration(getenv("TASK_ARCHIVE_INTERVAL", "1h"))
+)
@@ -0,0 +1,22 @@
+package config
+
+import "strconv"
+
+var (
+	// AttachmentMaxBytes 单个附件大小上限
+	AttachmentMaxBytes = mustParseInt(getenv("ATTACHMENT_MAX_BYTES", "20971520"))
+	// AttachmentMaxPerTask 单个任务的附件数量上限
+	AttachmentMaxPerTask = mustParseInt(getenv("ATTACHMENT_MAX_PER_TASK", "20"))
+	// AttachmentUserQuota 每个用户附件总空间
+	AttachmentUserQuota = mustParseInt(getenv("ATTACHMENT_USER_QUOTA", "524288000"))
+	// AssetMaxBytes 任务正文图片大小上限
+	AssetMaxBytes = mustParseInt(getenv("ASSET_MAX_BYTES", "10485760"))
+)
+
+func mustParseInt(s string) int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
@@ -10,6 +10,11 @@ var (
 	Issuer    = getenv("JWT_ISSUER", "todo-api")
 	Audience  = getenv("JWT_AUDIENCE", "todo-frontend")
 	AccessTTL = mustParseDuration(getenv("JWT_ACCESS_TTL", "24h"))
+
+	MFAIssuer   = getenv("MFA_ISSUER", "ToDoList")
+	MFATokenTTL = mustParseDuration(getenv("MFA_TOKEN_TTL", "5m"))
+	// MFARecoveryKey 计算恢复码 HMAC 的密钥，未配置时使用 JWT_SECRET；修改后已发放的恢复码失效
+	MFARecoveryKey = getenv("MFA_RECOVERY_KEY", Secret)
 )
 
 func pickSecret() string {
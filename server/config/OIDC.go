@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+type OIDCProviderConfig struct {
+	Name         string   `mapstructure:"name"`
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client-id"`
+	ClientSecret string   `mapstructure:"client-secret"`
+	RedirectURL  string   `mapstructure:"redirect-url"`
+	Scopes       []string `mapstructure:"scopes"`
+	AllowSignup  bool     `mapstructure:"allow-signup"` // 邮箱未匹配到已有账户时是否自动注册
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+func LoadOIDCConfig() (*OIDCConfig, error) {
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("yml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./server")
+	if p := os.Getenv("TODO_CONFIG_FILE"); p != "" {
+		v.SetConfigFile(p)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read config failed: %w", err)
+	}
+	var cfg OIDCConfig
+	if err := v.UnmarshalKey("oidc", &cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal oidc failed: %w", err)
+	}
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		// 密钥可通过环境变量 OIDC_<NAME>_CLIENT_SECRET 注入，避免写入配置文件
+		if sec := os.Getenv("OIDC_" + strings.ToUpper(p.Name) + "_CLIENT_SECRET"); sec != "" {
+			p.ClientSecret = sec
+		}
+	}
+
+	return &cfg, nil
+}
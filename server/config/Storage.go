@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+type COSStorageConfig struct {
+	BucketURL string `mapstructure:"bucket-url"`
+	SecretID  string `mapstructure:"secret-id"`
+	SecretKey string `mapstructure:"secret-key"`
+}
+
+type S3StorageConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access-key"`
+	SecretKey string `mapstructure:"secret-key"`
+	PathStyle bool   `mapstructure:"path-style"` // MinIO 等自建服务通常需要路径风格
+}
+
+type LocalStorageConfig struct {
+	Dir        string `mapstructure:"dir"`
+	BaseURL    string `mapstructure:"base-url"` // 签名链接前缀，指向媒体代理接口
+	SigningKey string `mapstructure:"signing-key"`
+}
+
+type StorageConfig struct {
+	Driver string             `mapstructure:"driver"` // cos | s3 | local
+	COS    COSStorageConfig   `mapstructure:"cos"`
+	S3     S3StorageConfig    `mapstructure:"s3"`
+	Local  LocalStorageConfig `mapstructure:"local"`
+}
+
+func LoadStorageConfig() (*StorageConfig, error) {
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("yml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./server")
+	if p := os.Getenv("TODO_CONFIG_FILE"); p != "" {
+		v.SetConfigFile(p)
+	}
+	v.SetDefault("storage.driver", "cos")
+	v.SetDefault("storage.s3.region", "us-east-1")
+	v.SetDefault("storage.local.dir", "./data/objects")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read config failed: %w", err)
+	}
+	var cfg StorageConfig
+	if err := v.UnmarshalKey("storage", &cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal storage failed: %w", err)
+	}
+	// 密钥优先取环境变量，避免写入配置文件
+	cfg.COS.SecretID = getenv("SECRETID", cfg.COS.SecretID)
+	cfg.COS.SecretKey = getenv("SECRETKEY", cfg.COS.SecretKey)
+	cfg.S3.AccessKey = getenv("S3_ACCESS_KEY", cfg.S3.AccessKey)
+	cfg.S3.SecretKey = getenv("S3_SECRET_KEY", cfg.S3.SecretKey)
+	cfg.Local.SigningKey = getenv("STORAGE_SIGNING_KEY", cfg.Local.SigningKey)
+	if cfg.Local.SigningKey == "" {
+		cfg.Local.SigningKey = Secret
+	}
+
+	return &cfg, nil
+}
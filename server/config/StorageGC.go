@@ -0,0 +1,10 @@
+package config
+
+var (
+	// StorageGCInterval 孤儿对象清理的执行间隔，0 表示不自动执行
+	StorageGCInterval = mustParseDuration(getenv("STORAGE_GC_INTERVAL", "24h"))
+	// StorageGCGrace 只清理早于该时长的对象，给上传后尚未写库的请求留出时间
+	StorageGCGrace = mustParseDuration(getenv("STORAGE_GC_GRACE", "72h"))
+	// StorageGCDryRun 为 true 时定时任务只输出报告，不删除对象
+	StorageGCDryRun = getenv("STORAGE_GC_DRY_RUN", "false") == "true"
+)
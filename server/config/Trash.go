@@ -0,0 +1,8 @@
+package config
+
+var (
+	// TrashRetention 项目与任务在回收站中保留的时长，超过后永久删除
+	TrashRetention = mustParseDuration(getenv("TRASH_RETENTION", "720h"))
+	// TrashPurgeInterval 回收站清理的执行间隔，0 表示不自动执行
+	TrashPurgeInterval = mustParseDuration(getenv("TRASH_PURGE_INTERVAL", "1h"))
+)
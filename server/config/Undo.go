@@ -0,0 +1,4 @@
+package config
+
+// UndoTTL 撤销令牌的有效期，略长于界面上 10 秒的撤销窗口，留出网络延迟
+var UndoTTL = mustParseDuration(getenv("UNDO_TTL", "15s"))
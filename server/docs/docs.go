@@ -15,32 +15,31 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
+        "/admin/storage/gc": {
             "post": {
-                "description": "使用用户名和密码进行身份验证，获取JWT token",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "遍历 images/ 与 attachments/ 下的对象，删除未被数据库引用且超过宽限期的对象；exports/ 下超过导出保留时长（24 小时）的导出文件一并删除。默认 dry_run=true 只返回报告不删除",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "用户登录",
+                "summary": "清理孤儿对象",
                 "parameters": [
                     {
-                        "description": "登录请求参数",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.LoginReq"
-                        }
+                        "type": "boolean",
+                        "description": "仅输出报告，默认 true",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功，返回token",
+                        "description": "执行完成",
                         "schema": {
-                            "$ref": "#/definitions/handler.LoginResponse"
+                            "$ref": "#/definitions/handler.StorageGCResponse"
                         }
                     },
                     "400": {
@@ -50,13 +49,19 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "登录失败",
+                        "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "用户不存在",
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "清理正在进行中",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -70,33 +75,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/logout": {
+        "/assets": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "需要有效的JWT token认证，无需请求体",
+                "description": "上传图片（jpeg/png/gif/webp），返回可插入任务 markdown 的引用，例如 ![](asset:123)；读取任务时引用会被替换为限时链接。未被任何任务引用的图片会在宽限期后被清理",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "用户登出",
+                "summary": "上传正文图片",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "图片",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "退出登录成功",
+                        "description": "上传成功",
                         "schema": {
-                            "$ref": "#/definitions/handler.LogoutResponse"
+                            "$ref": "#/definitions/handler.AssetUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或图片不合法",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "未授权或token无效",
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "写入Redis出错",
+                        "description": "系统错误",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -104,50 +133,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/projects": {
+        "/auth/oidc/{provider}/callback": {
             "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "获取当前用户的项目列表，支持按名称模糊搜索和分页",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "身份提供方回调，校验后按外部身份或已验证邮箱关联账户并签发JWT token",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "搜索项目列表",
+                "summary": "单点登录回调",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "项目名称关键字（模糊搜索）",
-                        "name": "name",
-                        "in": "query"
+                        "description": "提供方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "页码（默认1）",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "授权码",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "每页数量（默认20，最大100）",
-                        "name": "page_size",
-                        "in": "query"
+                        "type": "string",
+                        "description": "发起登录时生成的 state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回项目列表",
+                        "description": "登录成功，返回token或两步验证挑战",
                         "schema": {
-                            "$ref": "#/definitions/handler.ProjectListResponse"
+                            "$ref": "#/definitions/handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或用户拒绝授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "未授权或token无效",
+                        "description": "state 失效或 ID Token 校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "未配置该登录方式或未找到账户",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -159,37 +195,69 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "security": [
+            }
+        },
+        "/auth/oidc/{provider}/login": {
+            "get": {
+                "description": "生成 state/nonce/PKCE 后 302 跳转到身份提供方授权页",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "单点登录跳转",
+                "parameters": [
                     {
-                        "Bearer": []
+                        "type": "string",
+                        "description": "提供方名称（config.yml 中 oidc.providers[].name）",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "description": "创建新项目，项目名称必需且不能为空",
+                "responses": {
+                    "302": {
+                        "description": "跳转到身份提供方"
+                    },
+                    "404": {
+                        "description": "未配置该登录方式",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "身份提供方暂不可用",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "使用用户名和密码进行身份验证，获取JWT token；开启两步验证的用户返回 mfa_token，需再调用 /login/2fa",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "创建项目",
+                "summary": "用户登录",
                 "parameters": [
                     {
-                        "description": "项目创建请求体",
+                        "description": "登录请求参数",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateReq"
+                            "$ref": "#/definitions/handler.LoginReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回项目信息",
+                        "description": "登录成功，返回token或两步验证挑战",
                         "schema": {
-                            "$ref": "#/definitions/handler.ProjectCreateResponse"
+                            "$ref": "#/definitions/handler.LoginResponse"
                         }
                     },
                     "400": {
@@ -199,13 +267,19 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "未授权或token无效",
+                        "description": "登录失败",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "项目重复",
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "失败次数过多，已临时锁定",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -219,51 +293,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/projects/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "根据项目ID获取项目的详细信息，包括名称、颜色和创建时间",
+        "/login/2fa": {
+            "post": {
+                "description": "提交登录返回的 mfa_token 与认证器验证码（或恢复码），获取JWT token",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "获取项目详情",
+                "summary": "两步验证登录",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "两步验证参数",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginMFAReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回项目信息",
+                        "description": "登录成功，返回token",
                         "schema": {
-                            "$ref": "#/definitions/handler.ProjectDetailResponse"
+                            "$ref": "#/definitions/handler.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "非法的项目ID",
+                        "description": "参数错误",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "未授权或token无效",
+                        "description": "验证码错误或挑战已过期",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "项目不存在",
+                    "429": {
+                        "description": "失败次数过多，已临时锁定",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -275,41 +346,25 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/logout": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "删除项目及其关联的所有任务",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "需要有效的JWT token认证，无需请求体",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "删除项目",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "用户登出",
                 "responses": {
                     "200": {
-                        "description": "删除成功，返回删除的项目ID和受影响的行数",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ProjectDeleteResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "非法的项目ID",
+                        "description": "退出登录成功",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.LogoutResponse"
                         }
                     },
                     "401": {
@@ -318,85 +373,72 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "项目不存在",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "系统错误",
+                        "description": "写入Redis出错",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     }
                 }
-            },
-            "patch": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "更新项目的名称、颜色和排序顺序（可选项）",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/media/local/{key}": {
+            "get": {
+                "description": "仅本地存储驱动可用；校验链接中的 HMAC 签名与过期时间后返回对象内容",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
-                "summary": "更新项目信息",
+                "summary": "本地存储媒体代理",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "项目ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "对象key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "项目更新请求体",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.UpdateReq"
-                        }
+                        "type": "integer",
+                        "description": "过期时间（Unix 秒）",
+                        "name": "exp",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "签名",
+                        "name": "sig",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "下载文件名",
+                        "name": "filename",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功，返回更新后的项目信息",
+                        "description": "对象内容",
                         "schema": {
-                            "$ref": "#/definitions/handler.ProjectUpdateResponse"
+                            "type": "file"
                         }
                     },
-                    "400": {
-                        "description": "非法的项目ID或参数格式错误",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "未授权或token无效",
+                    "403": {
+                        "description": "签名无效或已过期",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "项目不存在",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "项目重复",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "系统错误",
+                        "description": "对象不存在",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -404,48 +446,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/projects/{id}/tasks/{task_id}": {
+        "/projects": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "根据项目ID和任务ID获取任务详情",
+                "description": "获取当前用户的项目列表，支持按名称模糊搜索和分页",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "获取任务详情",
+                "summary": "搜索项目列表",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目名称关键字（模糊搜索）",
+                        "name": "name",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "页码（默认1）",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "任务ID",
-                        "name": "task_id",
-                        "in": "path",
-                        "required": true
+                        "description": "每页数量（默认20，最大100）",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回任务详情",
-                        "schema": {
-                            "$ref": "#/definitions/handler.TaskDetailResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
+                        "description": "获取成功，返回项目列表",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ProjectListResponse"
                         }
                     },
                     "401": {
@@ -454,8 +494,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "任务不存在或项目不存在",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -468,50 +508,36 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "更新任务的名称、内容、状态、优先级、项目和截止时间",
+                "description": "创建新项目，项目名称必需且不能为空",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "更新任务",
+                "summary": "创建项目",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "任务ID",
-                        "name": "task_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "任务更新请求体",
+                        "description": "项目创建请求体",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.UpdateTaskRequest"
+                            "$ref": "#/definitions/handler.CreateReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功，返回任务信息",
+                        "description": "创建成功，返回项目信息",
                         "schema": {
-                            "$ref": "#/definitions/handler.TaskUpdateResponse"
+                            "$ref": "#/definitions/handler.ProjectCreateResponse"
                         }
                     },
                     "400": {
@@ -526,14 +552,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "任务不存在或项目不存在",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "任务已存在",
+                        "description": "项目重复",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -547,68 +573,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
-            "post": {
-                "description": "使用邮箱、用户名、密码和头像进行注册",
+        "/projects/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "根据项目ID获取项目的详细信息，包括名称、颜色和创建时间",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "用户注册",
+                "summary": "获取项目详情",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "邮箱地址",
-                        "name": "email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "用户名（2-64字符）",
-                        "name": "username",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "密码（8-72字符）",
-                        "name": "password",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "确认密码",
-                        "name": "confirm_password",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "头像文件",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "注册成功，返回用户信息",
+                        "description": "获取成功，返回项目信息",
                         "schema": {
-                            "$ref": "#/definitions/handler.RegisterResponse"
+                            "$ref": "#/definitions/handler.ProjectDetailResponse"
                         }
                     },
                     "400": {
-                        "description": "参数错误或头像上传失败",
+                        "description": "非法的项目ID",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "用户已存在",
+                    "401": {
+                        "description": "未授权或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -620,59 +635,39 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tasks": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "获取指定项目下的任务列表，支持状态筛选和分页",
+                "description": "将项目及其任务移入回收站，保留期内可通过 /trash/projects/{id}/restore 一并恢复",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "获取任务列表",
+                "summary": "删除项目",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "项目ID",
-                        "name": "project_id",
-                        "in": "query",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "任务状态（todo/done）",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "页码（默认1）",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量（默认20，最大100）",
-                        "name": "page_size",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回任务列表",
+                        "description": "删除成功，返回删除的项目ID和受影响的行数",
                         "schema": {
-                            "$ref": "#/definitions/handler.TaskListResponse"
+                            "$ref": "#/definitions/handler.ProjectDeleteResponse"
                         }
                     },
                     "400": {
-                        "description": "参数错误",
+                        "description": "非法的项目ID",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -683,6 +678,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "项目不存在",
                         "schema": {
@@ -697,40 +698,47 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "patch": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "在指定项目下创建新任务",
+                "description": "更新项目的名称和颜色（可选项）；不再接受 sort_order，调整顺序使用 /projects/{id}/reorder，由服务端计算排序键",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "创建任务",
+                "summary": "更新项目信息",
                 "parameters": [
                     {
-                        "description": "任务创建请求体",
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "项目更新请求体",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateTaskRequest"
+                            "$ref": "#/definitions/handler.UpdateReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回任务信息",
+                        "description": "更新成功，返回更新后的项目信息",
                         "schema": {
-                            "$ref": "#/definitions/handler.TaskCreateResponse"
+                            "$ref": "#/definitions/handler.ProjectUpdateResponse"
                         }
                     },
                     "400": {
-                        "description": "参数错误",
+                        "description": "非法的项目ID或参数格式错误",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -741,6 +749,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "项目不存在",
                         "schema": {
@@ -748,7 +762,7 @@ const docTemplate = `{
                         }
                     },
                     "409": {
-                        "description": "任务已存在",
+                        "description": "项目重复",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -762,58 +776,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}": {
-            "delete": {
+        "/projects/{id}/board": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "删除指定项目下的任务",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "按项目状态分列返回未归档的任务，列按状态顺序排列，列内按 sort_order 倒序",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "删除任务",
+                "summary": "看板",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "任务ID",
+                        "description": "项目ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "项目ID",
-                        "name": "project_id",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功，返回任务ID和受影响的行数",
+                        "description": "获取成功",
                         "schema": {
-                            "$ref": "#/definitions/handler.TaskDeleteResponse"
+                            "$ref": "#/definitions/handler.BoardResponse"
                         }
                     },
                     "400": {
-                        "description": "参数错误",
+                        "description": "非法的项目ID",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "未授权或token无效",
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "任务不存在或项目不存在",
+                        "description": "项目不存在",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -827,58 +837,123 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
-            "patch": {
+        "/projects/{id}/board/move": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "更新用户邮箱、用户名、密码和头像（头像可选）",
+                "description": "将任务移动到 status 列中 prev_id（上方相邻任务）与 next_id（下方相邻任务）之间，状态与排序在同一事务中修改；\n两者都省略时移到列首。相邻任务以服务端当前顺序为准，已不在该列或已不相邻时返回 409，客户端刷新看板后重试",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "更新用户信息",
+                "summary": "在看板中移动任务",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "邮箱地址",
-                        "name": "email",
-                        "in": "formData"
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "用户名（2-64字符）",
-                        "name": "username",
-                        "in": "formData"
+                        "description": "移动位置",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BoardMoveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "移动成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskUpdateResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "新密码（8-72字符）",
-                        "name": "password",
-                        "in": "formData"
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目、任务或状态不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "看板已变化",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}/reorder": {
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "确认新密码",
-                        "name": "confirm_password",
-                        "in": "formData"
+                        "Bearer": []
+                    }
+                ],
+                "description": "将项目放到 after_id 下方、before_id 上方，规则同调整任务顺序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "调整项目顺序",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "头像文件（可选）",
-                        "name": "file",
-                        "in": "formData"
+                        "description": "参照位置",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReorderRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功,如更新密码则刷新token",
+                        "description": "调整成功",
                         "schema": {
-                            "$ref": "#/definitions/handler.UpdateUserResponse"
+                            "$ref": "#/definitions/handler.ProjectUpdateResponse"
                         }
                     },
                     "400": {
@@ -893,8 +968,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "409": {
-                        "description": "用户名或邮箱已存在",
+                        "description": "列表已变化",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -907,98 +994,4066 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.CreateReq": {
-            "type": "object",
-            "required": [
-                "name"
-            ],
-            "properties": {
-                "color": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string",
-                    "maxLength": 128,
-                    "minLength": 1
+        },
+        "/projects/{id}/statuses": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "按顺序返回项目的任务状态；category 为 closed 的状态视为已完成",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "项目状态列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatusListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "非法的项目ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "key 为小写字母开头的字母、数字或下划线，创建后不可修改；category 默认 open；position 为空时排在最后",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "新增项目状态",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "状态",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "状态已存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "handler.CreateTaskRequest": {
-            "type": "object",
-            "required": [
-                "project_id",
-                "title"
-            ],
+        "/projects/{id}/statuses/{key}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "状态下仍有任务（包括回收站中的任务）时必须通过 move_to 指定迁移目标；撤销时以原ID重建状态并把任务移回",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "删除项目状态",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务迁移到的状态key",
+                        "name": "move_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatusDeleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "非法的项目ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目或状态不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "状态下仍有任务或为该分类的最后一个状态",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "修改名称、分类或顺序；分类改变时该状态下的任务随之变为已完成或未完成。每个项目至少保留一个 open 与一个 closed 状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "修改项目状态",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "修改内容",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目或状态不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "该分类的最后一个状态",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}/tasks/{task_id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "根据项目ID和任务ID获取任务详情",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "获取任务详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回任务详情",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在或项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "更新任务的名称、内容、状态、优先级、标签、项目和截止时间；re_due_at 格式同创建任务的 due_at\ntags 整体替换任务的标签，传空数组清空；批量增删标签使用 /tasks/bulk\n移动到其他项目时沿用同名状态，目标项目没有同名状态时使用同分类的第一个状态\n不再接受 sort_order，调整顺序使用 /tasks/{id}/reorder，由服务端计算排序键",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "更新任务",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "任务更新请求体",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功，返回任务信息",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在或项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "任务已存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "使用邮箱、用户名、密码和头像进行注册",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "邮箱地址",
+                        "name": "email",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户名（2-64字符）",
+                        "name": "username",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "密码（8-72字符）",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "确认密码",
+                        "name": "confirm_password",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "头像文件（JPEG/PNG/GIF/WebP，不超过 5MB，裁剪为 64/256/512 正方形 JPEG）",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注册成功，返回用户信息",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或头像上传失败",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "用户已存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "获取指定项目下的任务列表，支持状态、标签筛选和分页",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "获取任务列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "project_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务状态，项目中某个状态的 key",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只返回带有该标签的任务",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码（默认1）",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量（默认20，最大100）",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回任务列表",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "在指定项目下创建新任务；未指定项目时使用默认项目，未指定优先级时使用默认优先级，未指定状态时使用项目中第一个未完成状态。\ntags 最多 20 个，每个不超过 32 个字符，去除首尾空白与重复后按顺序保存。\ndue_at 支持 RFC3339，或不带时区的 \"YYYY-MM-DD HH:MM\" / \"YYYY-MM-DD\"（按用户时区解释，仅日期视为当天结束）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "创建任务",
+                "parameters": [
+                    {
+                        "description": "任务创建请求体",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回任务信息",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "任务已存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/agenda": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "按用户时区与每周起始日划分边界，返回当天或当周截止的任务",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "日程视图",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "day（默认）或 week",
+                        "name": "range",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "日期 YYYY-MM-DD，默认今天",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AgendaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/archive": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "按归档时间倒序列出已归档的任务，不含正文",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "归档视图",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID，默认全部项目",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码（默认1）",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量（默认20，最大100）",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ArchivedTaskListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "op 取值 complete、reopen、move_project（需 project_id）、set_priority（需 priority）、delete、tag（add_tags 与 remove_tags 至少给出一个，先移除再添加）。\n最多 100 个任务，在一个事务中执行；单个任务失败不影响其余任务，逐项返回结果。成功的修改共用一个撤销令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "批量操作任务",
+                "parameters": [
+                    {
+                        "description": "批量操作",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BulkTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "执行完成",
+                        "schema": {
+                            "$ref": "#/definitions/handler.BulkTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "目标项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "将指定项目下的任务移入回收站，保留期内可通过 /trash/tasks/{id}/restore 恢复",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "删除任务",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "project_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回任务ID和受影响的行数",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskDeleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或token无效",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在或项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "只能归档已完成的任务；归档后不再出现在任务列表中，可在归档视图查看。已完成超过设定时长的任务会被自动归档",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "归档任务",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "归档成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "非法的任务ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "任务未完成",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "任务附件列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AttachmentListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "以 multipart/form-data 上传附件，字段名 file；受单文件大小、单任务数量与用户总空间限制",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "上传任务附件",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "附件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上传成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AttachmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或文件过大",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "附件数量或空间已达上限",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/attachments/{attachment_id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "返回附件的短时签名下载链接；redirect=true 时直接 302 跳转",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "下载任务附件",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "附件ID",
+                        "name": "attachment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否直接跳转到链接",
+                        "name": "redirect",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AttachmentLinkResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "跳转到签名链接"
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "删除任务附件",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "附件ID",
+                        "name": "attachment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AttachmentDeleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/reorder": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "将任务放到同项目中 after_id 下方、before_id 上方，排序键由服务端计算，键过密时自动重新分配。\n至少给出一个参照任务；两者都给出时必须相邻，否则返回 409，客户端刷新列表后重试",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "调整任务顺序",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "参照位置",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReorderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "调整成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "列表已变化",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "按版本号倒序返回，不含正文；每次修改标题、正文、状态、优先级、项目或截止时间都会产生新版本",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "任务历史版本列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码（默认1）",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量（默认20，最大100）",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RevisionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "返回字段差异与正文的 unified diff",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "比较任务的两个版本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "起始版本号",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "目标版本号",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "上下文行数（默认3，最大20）",
+                        "name": "context",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RevisionDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions/{rev}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "获取任务历史版本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "版本号",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RevisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions/{rev}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "将标题与正文恢复为指定版本并重新渲染，恢复操作本身记录为新版本",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "恢复任务历史版本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "版本号",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务或版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "同项目下已有同名任务",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/unarchive": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "任务保持已完成状态回到任务列表；重新打开已归档的任务也会取消归档",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "取消归档",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已取消归档",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "非法的任务ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "按删除时间倒序列出已删除的项目与任务；随项目一起删除的任务计入项目的 task_count，不单独列出",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "回收站列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task 或 project，默认全部",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码（默认1）",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量（默认20，最大100）",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TrashListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/projects/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "一并恢复随项目删除的任务；在此之前单独删除的任务仍留在回收站",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "从回收站恢复项目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TrashRestoreProjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "非法的项目ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "回收站中没有该项目",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "同名项目已存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/tasks/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "所属项目也在回收站中时需先恢复项目",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "从回收站恢复任务",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "非法的任务ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "回收站中没有该任务",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "同名任务已存在或所属项目已删除",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/undo/{token}": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "撤销任务或项目的创建、修改（含移动、完成）、删除与恢复，以及项目状态的增删改与附件删除；令牌由对应接口返回的 undo_token 提供，短时间内有效且只能使用一次。\n撤销因记录已被再次修改而失败时令牌作废；因同名冲突或系统错误失败时令牌保留，可以重试\n操作之后记录又被修改过时返回 409，不会覆盖新的修改",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "撤销操作",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "撤销令牌",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "撤销成功，返回受影响的任务与项目ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UndoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "令牌已过期或已使用",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "记录已被再次修改或存在同名记录",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "校验密码后进入注销宽限期：立即退出全部会话并吊销个人访问令牌，宽限期内重新登录可撤销；到期后删除全部任务、项目、头像与缓存",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "注销账户",
+                "parameters": [
+                    {
+                        "description": "当前密码",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DeleteAccountReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已进入注销宽限期",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DeleteAccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "账户已在注销流程中",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "更新用户邮箱、用户名、密码和头像（头像可选）",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "更新用户信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "邮箱地址",
+                        "name": "email",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户名（2-64字符）",
+                        "name": "username",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "新密码（8-72字符）",
+                        "name": "password",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "确认新密码",
+                        "name": "confirm_password",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "头像文件（可选，要求同注册）",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功,如更新密码则刷新token",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "用户名或邮箱已存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "提交认证器上的验证码完成开启，返回一次性展示的恢复码",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "确认开启两步验证",
+                "parameters": [
+                    {
+                        "description": "验证码",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TOTPCodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "开启成功，返回恢复码",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TOTPConfirmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或未申请开启",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "验证码错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "已开启两步验证",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "需提交认证器上当前有效的验证码",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "关闭两步验证",
+                "parameters": [
+                    {
+                        "description": "验证码",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TOTPCodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "关闭成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LogoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或未开启",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "验证码错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "生成 TOTP 密钥和 otpauth:// 链接（前端渲染为二维码），需调用确认接口后才生效",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "申请开启两步验证",
+                "responses": {
+                    "200": {
+                        "description": "返回密钥与链接",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TOTPEnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "已开启两步验证",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "返回最近一次导出的状态与进度（0-100），完成后附带限时下载链接，链接过期后重新查询即可获取新链接",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "查询个人数据导出进度",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ExportStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "暂无导出任务",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "异步打包个人资料、全部项目与任务（Markdown + 元数据）以及头像为 ZIP，通过 GET /users/me/export 查询进度与下载链接",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "发起个人数据导出",
+                "responses": {
+                    "200": {
+                        "description": "已加入导出队列",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ExportStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "已有导出任务进行中",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "获取偏好设置",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "时区（IANA 名称）、语言、每周起始日（0 周日~6 周六）、默认优先级、默认项目（0 清除）及提醒默认值（是否提醒、提前分钟数、全天任务提醒时间 HH:MM）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "更新偏好设置",
+                "parameters": [
+                    {
+                        "description": "需要修改的偏好",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdatePreferencesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "默认项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "列出当前用户的全部令牌（不含明文），包括最近使用时间",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "个人访问令牌列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AccessTokenListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "供脚本与命令行使用，权限范围可选 tasks:read、tasks:write、projects:read、projects:write、projects:admin、account:read、account:write、admin:system，兼容旧权限名 read:tasks、write:tasks、admin（不能超出账户角色，也不能超出当前会话或令牌已有的权限）；令牌明文只在创建时返回一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "创建个人访问令牌",
+                "parameters": [
+                    {
+                        "description": "令牌名称、权限与有效期（天，可选，不填则长期有效）",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateAccessTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回令牌明文",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AccessTokenCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "令牌数量已达上限",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/tokens/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "吊销个人访问令牌",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "令牌ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "吊销成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LogoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "非法的令牌ID",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "令牌不存在或已吊销",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/avatar": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "返回头像的短时签名链接；redirect=true 时直接 302 跳转。仅能获取自己的头像，id 可传 me",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "获取头像限时链接",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID或me",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "期望边长（64/256/512），取不小于该值的最小尺寸，默认 256",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否直接跳转到链接",
+                        "name": "redirect",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MediaLinkResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "跳转到签名链接"
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "头像不存在",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "系统错误",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handler.AccessTokenCreateData": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "$ref": "#/definitions/models.PersonalAccessToken"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AccessTokenCreateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.AccessTokenCreateData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AccessTokenListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PersonalAccessToken"
+                    }
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AgendaResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.AgendaResult"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ArchivedTaskListData": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Task"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ArchivedTaskListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.ArchivedTaskListData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AssetUploadResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.AssetUploadResult"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AttachmentDeleteData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "undo_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AttachmentDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.AttachmentDeleteData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AttachmentLinkResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.AttachmentLink"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AttachmentListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attachment"
+                    }
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AttachmentResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/models.Attachment"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.BoardMoveRequest": {
+            "type": "object",
+            "required": [
+                "status",
+                "task_id"
+            ],
+            "properties": {
+                "next_id": {
+                    "type": "integer"
+                },
+                "prev_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "in_progress"
+                },
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.BoardResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.Board"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.BulkTaskRequest": {
+            "type": "object",
+            "required": [
+                "ids",
+                "op"
+            ],
+            "properties": {
+                "add_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "op": {
+                    "type": "string",
+                    "example": "complete"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "remove_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.BulkTaskResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.BulkResult"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateAccessTokenReq": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 1
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.CreateReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 1
+                }
+            }
+        },
+        "handler.CreateStatusRequest": {
+            "type": "object",
+            "required": [
+                "key",
+                "name"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "closed"
+                    ]
+                },
+                "key": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "in_progress"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "进行中"
+                },
+                "position": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "handler.CreateTaskRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "content_md": {
+                    "type": "string"
+                },
+                "due_at": {
+                    "type": "string",
+                    "example": "2026-01-02 18:00"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "handler.DeleteAccountData": {
+            "type": "object",
+            "properties": {
+                "deletion_scheduled_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DeleteAccountReq": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
+                }
+            }
+        },
+        "handler.DeleteAccountResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.DeleteAccountData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.FieldError"
+                    }
+                },
+                "key": {
+                    "type": "string"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ExportStatusResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.ExportView"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LoginData": {
+            "type": "object",
+            "properties": {
+                "access_expires_at": {
+                    "type": "string"
+                },
+                "access_token": {
+                    "type": "string"
+                },
+                "mfa_expires_at": {
+                    "type": "string"
+                },
+                "mfa_required": {
+                    "type": "boolean"
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LoginMFAReq": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 6
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LoginReq": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 2
+                }
+            }
+        },
+        "handler.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.LoginData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LogoutResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {},
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.MediaLinkResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.SignedLink"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.PreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.Preferences"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectCreateData": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "$ref": "#/definitions/models.Project"
+                },
+                "undo_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectCreateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.ProjectCreateData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectDeleteData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "proj_affected": {
+                    "type": "integer"
+                },
+                "task_affected": {
+                    "type": "integer"
+                },
+                "undo_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.ProjectDeleteData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectDetailData": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "$ref": "#/definitions/service.ProjectProfile"
+                }
+            }
+        },
+        "handler.ProjectDetailResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.ProjectDetailData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectListData": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ProjectSummary"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ProjectListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.ProjectListData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectUpdateData": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "$ref": "#/definitions/models.Project"
+                },
+                "undo_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.ProjectUpdateData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ReorderRequest": {
+            "type": "object",
+            "properties": {
+                "after_id": {
+                    "type": "integer"
+                },
+                "before_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.RevisionDiffResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.RevisionDiff"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RevisionListData": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskRevision"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.RevisionListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.RevisionListData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RevisionResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/models.TaskRevision"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.StatusDeleteData": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "tasks_moved": {
+                    "type": "integer"
+                },
+                "undo_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.StatusDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.StatusDeleteData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.StatusListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProjectStatus"
+                    }
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.StatusWithUndo"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.StatusWithUndo": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "undo_token": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.StorageGCResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.StorageGCReport"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TOTPCodeReq": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TOTPConfirmData": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.TOTPConfirmResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TOTPConfirmData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TOTPEnrollData": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TOTPEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TOTPEnrollData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskCreateData": {
+            "type": "object",
+            "properties": {
+                "task": {
+                    "$ref": "#/definitions/models.Task"
+                },
+                "undo_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskCreateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TaskCreateData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskDeleteData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "task_affected": {
+                    "type": "integer"
+                },
+                "undo_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TaskDeleteData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskDetailResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/service.TaskDetail"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskListData": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TaskSummary"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.TaskListResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TaskListData"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TaskWithUndo"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TaskWithUndo": {
+            "type": "object",
             "properties": {
+                "archived_at": {
+                    "description": "ArchivedAt 归档与完成状态相互独立，归档的任务不出现在列表、日程与提醒中",
+                    "type": "string"
+                },
+                "completed_at": {
+                    "description": "CompletedAt 非空表示任务处于 closed 分类的状态",
+                    "type": "string"
+                },
+                "content_html": {
+                    "type": "string"
+                },
                 "content_md": {
                     "type": "string"
                 },
+                "created_at": {
+                    "type": "string"
+                },
                 "due_at": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
+                "notified": {
+                    "type": "boolean"
+                },
                 "priority": {
                     "type": "integer"
                 },
                 "project_id": {
                     "type": "integer"
                 },
+                "remind_at": {
+                    "type": "string"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
                 "status": {
+                    "description": "Status 为所属项目中某个 ProjectStatus 的 Key",
                     "type": "string"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
-                    "type": "string",
-                    "maxLength": 200
+                    "type": "string"
+                },
+                "undo_token": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.ErrorResponse": {
+        "handler.TrashListData": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TrashEntry"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.TrashListResponse": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TrashListData"
+                },
                 "msg": {
                     "type": "string"
                 }
             }
         },
-        "handler.LoginData": {
+        "handler.TrashRestoreProjectData": {
             "type": "object",
             "properties": {
-                "access_expires_at": {
-                    "type": "string"
+                "project": {
+                    "$ref": "#/definitions/models.Project"
                 },
-                "access_token": {
-                    "type": "string"
+                "task_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "token_type": {
+                "undo_token": {
                     "type": "string"
                 }
             }
         },
-        "handler.LoginReq": {
+        "handler.TrashRestoreProjectResponse": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
-                    "type": "string",
-                    "maxLength": 72,
-                    "minLength": 8
+                "code": {
+                    "type": "integer"
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 64,
-                    "minLength": 2
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/handler.TrashRestoreProjectData"
+                },
+                "msg": {
+                    "type": "string"
                 }
             }
         },
-        "handler.LoginResponse": {
+        "handler.UndoResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1008,14 +5063,125 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "$ref": "#/definitions/handler.LoginData"
+                    "$ref": "#/definitions/models.UndoResult"
                 },
                 "msg": {
                     "type": "string"
                 }
             }
         },
-        "handler.LogoutResponse": {
+        "handler.UpdatePreferencesReq": {
+            "type": "object",
+            "properties": {
+                "all_day_reminder_time": {
+                    "type": "string",
+                    "example": "09:00"
+                },
+                "default_priority": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                },
+                "default_project_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "zh-CN",
+                        "en-US"
+                    ]
+                },
+                "reminder_enabled": {
+                    "type": "boolean"
+                },
+                "reminder_minutes": {
+                    "type": "integer",
+                    "maximum": 10080,
+                    "minimum": 0
+                },
+                "timezone": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "Asia/Shanghai"
+                },
+                "week_start": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                }
+            }
+        },
+        "handler.UpdateReq": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string",
+                    "maxLength": 16
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 1
+                }
+            }
+        },
+        "handler.UpdateStatusRequest": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "closed"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "position": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "handler.UpdateTaskRequest": {
+            "type": "object",
+            "properties": {
+                "content_md": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                },
+                "re_due_at": {
+                    "type": "string",
+                    "example": "2026-01-02 18:00"
+                },
+                "re_project_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "handler.UpdateUserResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1030,449 +5196,649 @@ const docTemplate = `{
                 }
             }
         },
-        "handler.ProjectCreateData": {
+        "models.Asset": {
             "type": "object",
             "properties": {
-                "project": {
-                    "$ref": "#/definitions/models.Project"
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ref_count": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.ProjectCreateResponse": {
+        "models.Attachment": {
             "type": "object",
             "properties": {
-                "code": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "count": {
+                "size": {
                     "type": "integer"
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.ProjectCreateData"
+                "task_id": {
+                    "type": "integer"
                 },
-                "msg": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AvatarVariants": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "object",
+                "additionalProperties": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "from": {},
+                "to": {}
+            }
+        },
+        "models.PersonalAccessToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.ProjectDeleteData": {
+        "models.Project": {
             "type": "object",
             "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "proj_affected": {
+                "name": {
+                    "type": "string"
+                },
+                "sort_order": {
                     "type": "integer"
                 },
-                "task_affected": {
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "handler.ProjectDeleteResponse": {
+        "models.ProjectStatus": {
             "type": "object",
             "properties": {
-                "code": {
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "count": {
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
                     "type": "integer"
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.ProjectDeleteData"
+                "project_id": {
+                    "type": "integer"
                 },
-                "msg": {
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "handler.ProjectDetailData": {
-            "type": "object",
-            "properties": {
-                "project": {
-                    "$ref": "#/definitions/service.ProjectProfile"
-                }
-            }
-        },
-        "handler.ProjectDetailResponse": {
+        "models.Task": {
             "type": "object",
             "properties": {
-                "code": {
+                "archived_at": {
+                    "description": "ArchivedAt 归档与完成状态相互独立，归档的任务不出现在列表、日程与提醒中",
+                    "type": "string"
+                },
+                "completed_at": {
+                    "description": "CompletedAt 非空表示任务处于 closed 分类的状态",
+                    "type": "string"
+                },
+                "content_html": {
+                    "type": "string"
+                },
+                "content_md": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "due_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "count": {
+                "notified": {
+                    "type": "boolean"
+                },
+                "priority": {
                     "type": "integer"
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.ProjectDetailData"
+                "project_id": {
+                    "type": "integer"
                 },
-                "msg": {
+                "remind_at": {
                     "type": "string"
-                }
-            }
-        },
-        "handler.ProjectListData": {
-            "type": "object",
-            "properties": {
-                "list": {
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status 为所属项目中某个 ProjectStatus 的 Key",
+                    "type": "string"
+                },
+                "tags": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.ProjectSummary"
+                        "type": "string"
                     }
                 },
-                "page": {
-                    "type": "integer"
+                "title": {
+                    "type": "string"
                 },
-                "page_size": {
-                    "type": "integer"
+                "updated_at": {
+                    "type": "string"
                 },
-                "total": {
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "handler.ProjectListResponse": {
+        "models.TaskCounts": {
             "type": "object",
             "properties": {
-                "code": {
+                "archived": {
                     "type": "integer"
                 },
-                "count": {
-                    "type": "integer"
+                "by_status": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.ProjectListData"
+                "closed": {
+                    "type": "integer"
                 },
-                "msg": {
-                    "type": "string"
-                }
-            }
-        },
-        "handler.ProjectUpdateData": {
-            "type": "object",
-            "properties": {
-                "project": {
-                    "$ref": "#/definitions/models.Project"
+                "open": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.ProjectUpdateResponse": {
+        "models.TaskRevision": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
                 },
-                "count": {
+                "content_md": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "due_at": {
+                    "type": "string"
+                },
+                "priority": {
                     "type": "integer"
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.ProjectUpdateData"
+                "project_id": {
+                    "type": "integer"
                 },
-                "msg": {
+                "reason": {
                     "type": "string"
-                }
-            }
-        },
-        "handler.RegisterResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
+                },
+                "restored_from": {
                     "type": "integer"
                 },
-                "count": {
+                "rev": {
                     "type": "integer"
                 },
-                "data": {
-                    "$ref": "#/definitions/models.User"
+                "status": {
+                    "type": "string"
                 },
-                "msg": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "handler.TaskCreateData": {
+        "models.UndoResult": {
             "type": "object",
             "properties": {
-                "task": {
-                    "$ref": "#/definitions/models.Task"
+                "project_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "task_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "handler.TaskCreateResponse": {
+        "models.User": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "avatar_url": {
+                    "type": "string"
                 },
-                "count": {
-                    "type": "integer"
+                "avatar_variants": {
+                    "$ref": "#/definitions/models.AvatarVariants"
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.TaskCreateData"
+                "created_at": {
+                    "type": "string"
                 },
-                "msg": {
+                "deletion_scheduled_at": {
                     "type": "string"
-                }
-            }
-        },
-        "handler.TaskDeleteData": {
-            "type": "object",
-            "properties": {
+                },
+                "email": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "task_affected": {
-                    "type": "integer"
+                "preferences": {
+                    "$ref": "#/definitions/models.UserPreferences"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "handler.TaskDeleteResponse": {
+        "models.UserPreferences": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "all_day_reminder_time": {
+                    "description": "仅有日期的任务当天提醒时间",
+                    "type": "string"
                 },
-                "count": {
+                "default_priority": {
                     "type": "integer"
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.TaskDeleteData"
+                "default_project_id": {
+                    "type": "integer"
                 },
-                "msg": {
+                "locale": {
                     "type": "string"
+                },
+                "reminder_enabled": {
+                    "type": "boolean"
+                },
+                "reminder_minutes": {
+                    "description": "截止前多少分钟提醒",
+                    "type": "integer"
+                },
+                "week_start": {
+                    "description": "0 周日 1 周一",
+                    "type": "integer"
                 }
             }
         },
-        "handler.TaskDetailResponse": {
+        "service.AgendaItem": {
             "type": "object",
             "properties": {
-                "code": {
+                "due_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "count": {
+                "priority": {
                     "type": "integer"
                 },
-                "data": {
-                    "$ref": "#/definitions/service.TaskDetail"
+                "project_id": {
+                    "type": "integer"
                 },
-                "msg": {
+                "status": {
+                    "type": "string"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "handler.TaskListData": {
+        "service.AgendaResult": {
             "type": "object",
             "properties": {
-                "list": {
+                "from": {
+                    "type": "string"
+                },
+                "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.TaskSummary"
+                        "$ref": "#/definitions/service.AgendaItem"
                     }
                 },
-                "page": {
-                    "type": "integer"
-                },
-                "page_size": {
-                    "type": "integer"
+                "timezone": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "to": {
+                    "type": "string"
                 }
             }
         },
-        "handler.TaskListResponse": {
+        "service.AssetUploadResult": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "asset": {
+                    "$ref": "#/definitions/models.Asset"
                 },
-                "count": {
-                    "type": "integer"
+                "expires_at": {
+                    "type": "string"
                 },
-                "data": {
-                    "$ref": "#/definitions/handler.TaskListData"
+                "markdown": {
+                    "type": "string"
                 },
-                "msg": {
+                "ref": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "handler.TaskUpdateResponse": {
+        "service.AttachmentLink": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "attachment": {
+                    "$ref": "#/definitions/models.Attachment"
                 },
-                "count": {
-                    "type": "integer"
-                },
-                "data": {
-                    "$ref": "#/definitions/models.Task"
+                "expires_at": {
+                    "type": "string"
                 },
-                "msg": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "handler.UpdateReq": {
+        "service.Board": {
             "type": "object",
             "properties": {
-                "color": {
-                    "type": "string",
-                    "maxLength": 16
-                },
-                "name": {
-                    "type": "string",
-                    "maxLength": 128,
-                    "minLength": 1
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BoardColumn"
+                    }
                 },
-                "sort_order": {
+                "project_id": {
                     "type": "integer"
                 }
             }
         },
-        "handler.UpdateTaskRequest": {
+        "service.BoardCard": {
             "type": "object",
             "properties": {
-                "content_md": {
+                "due_at": {
                     "type": "string"
                 },
-                "priority": {
-                    "type": "integer",
-                    "maximum": 5,
-                    "minimum": 1
-                },
-                "re_due_at": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "re_project_id": {
+                "priority": {
                     "type": "integer"
                 },
                 "sort_order": {
-                    "type": "integer",
-                    "minimum": 0
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string",
-                    "enum": [
-                        "todo",
-                        "done"
-                    ]
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "title": {
-                    "type": "string",
-                    "maxLength": 200
+                    "type": "string"
                 }
             }
         },
-        "handler.UpdateUserResponse": {
+        "service.BoardColumn": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "category": {
+                    "type": "string"
                 },
                 "count": {
                     "type": "integer"
                 },
-                "data": {},
-                "msg": {
+                "key": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BoardCard"
+                    }
                 }
             }
         },
-        "models.Project": {
+        "service.BulkItemResult": {
             "type": "object",
             "properties": {
-                "color": {
-                    "type": "string"
+                "changed": {
+                    "type": "boolean"
                 },
-                "created_at": {
-                    "type": "string"
+                "code": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "msg": {
                     "type": "string"
                 },
-                "sort_order": {
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.BulkResult": {
+            "type": "object",
+            "properties": {
+                "failed": {
                     "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BulkItemResult"
+                    }
                 },
-                "user_id": {
+                "succeeded": {
                     "type": "integer"
+                },
+                "undo_token": {
+                    "type": "string"
                 }
             }
         },
-        "models.Task": {
+        "service.ExportView": {
             "type": "object",
             "properties": {
-                "content_html": {
+                "created_at": {
                     "type": "string"
                 },
-                "content_md": {
+                "download_expires_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "download_url": {
                     "type": "string"
                 },
-                "due_at": {
+                "error": {
+                    "description": "失败原因的错误键",
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "expires_at": {
+                    "type": "string"
                 },
-                "notified": {
-                    "type": "boolean"
+                "finished_at": {
+                    "type": "string"
                 },
-                "priority": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
-                "project_id": {
+                "key": {
+                    "type": "string"
+                },
+                "progress": {
                     "type": "integer"
                 },
-                "sort_order": {
+                "size": {
                     "type": "integer"
                 },
                 "status": {
                     "type": "string"
-                },
-                "title": {
+                }
+            }
+        },
+        "service.OrphanObject": {
+            "type": "object",
+            "properties": {
+                "key": {
                     "type": "string"
                 },
-                "updated_at": {
+                "last_modified": {
                     "type": "string"
                 },
-                "user_id": {
+                "size": {
                     "type": "integer"
                 }
             }
         },
-        "models.User": {
+        "service.Preferences": {
             "type": "object",
             "properties": {
-                "avatar_url": {
+                "all_day_reminder_time": {
+                    "description": "仅有日期的任务当天提醒时间",
                     "type": "string"
                 },
-                "created_at": {
-                    "type": "string"
+                "default_priority": {
+                    "type": "integer"
                 },
-                "email": {
+                "default_project_id": {
+                    "type": "integer"
+                },
+                "locale": {
                     "type": "string"
                 },
-                "id": {
+                "reminder_enabled": {
+                    "type": "boolean"
+                },
+                "reminder_minutes": {
+                    "description": "截止前多少分钟提醒",
                     "type": "integer"
                 },
                 "timezone": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+                "week_start": {
+                    "description": "0 周日 1 周一",
+                    "type": "integer"
                 }
             }
         },
@@ -1494,6 +5860,14 @@ const docTemplate = `{
                 "sort_order": {
                     "type": "integer"
                 },
+                "task_counts": {
+                    "description": "TaskCounts 待办与已完成不含已归档的任务",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskCounts"
+                        }
+                    ]
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -1519,9 +5893,99 @@ const docTemplate = `{
                 }
             }
         },
+        "service.RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "diff": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "title_from": {
+                    "type": "string"
+                },
+                "title_to": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.SignedLink": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.StorageGCReport": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "expired_assets": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "grace": {
+                    "type": "string"
+                },
+                "limit_reached": {
+                    "type": "boolean"
+                },
+                "objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.OrphanObject"
+                    }
+                },
+                "orphaned": {
+                    "type": "integer"
+                },
+                "orphaned_bytes": {
+                    "type": "integer"
+                },
+                "referenced": {
+                    "type": "integer"
+                },
+                "scanned": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "too_recent": {
+                    "type": "integer"
+                }
+            }
+        },
         "service.TaskDetail": {
             "type": "object",
             "properties": {
+                "archived_at": {
+                    "type": "string"
+                },
                 "content_html": {
                     "type": "string"
                 },
@@ -1537,6 +6001,12 @@ const docTemplate = `{
                 "status": {
                     "type": "string"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 },
@@ -1557,10 +6027,56 @@ const docTemplate = `{
                 "status": {
                     "type": "string"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 }
             }
+        },
+        "service.TrashEntry": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "purge_at": {
+                    "type": "string"
+                },
+                "task_count": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "utils.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -1579,7 +6095,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/api/v1",
 	Schemes:          []string{},
 	Title:            "ToDoList API",
-	Description:      "管理API",
+	Description:      "管理API。错误默认返回 {code, key, msg}；请求头 Accept 含 application/problem+json 时返回 RFC 7807 格式",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AccessTokenHandler struct {
+	svc *service.AccessTokenService
+}
+
+func NewAccessTokenHandler(svc *service.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{svc: svc}
+}
+
+type CreateAccessTokenReq struct {
+	Name          string   `json:"name" binding:"required,min=1,max=64"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,gte=1,lte=365"`
+}
+
+// @Summary 创建个人访问令牌
+// @Description 供脚本与命令行使用，权限范围可选 tasks:read、tasks:write、projects:read、projects:write、projects:admin、account:read、account:write、admin:system，兼容旧权限名 read:tasks、write:tasks、admin（不能超出账户角色，也不能超出当前会话或令牌已有的权限）；令牌明文只在创建时返回一次
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body CreateAccessTokenReq true "令牌名称、权限与有效期（天，可选，不填则长期有效）"
+// @Success 200 {object} AccessTokenCreateResponse "创建成功，返回令牌明文"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 409 {object} ErrorResponse "令牌数量已达上限"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/tokens [post]
+func (h *AccessTokenHandler) Create(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	var req CreateAccessTokenReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("pat.create.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+
+	res, err := h.svc.Create(c.Request.Context(), lg, uid, service.CreateAccessTokenInput{
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+		Granted:       c.GetStringSlice("scopes"),
+	})
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "创建成功，请妥善保存令牌", gin.H{
+		"token":        res.Plaintext,
+		"access_token": res.Token,
+	}, 1)
+}
+
+// @Summary 个人访问令牌列表
+// @Description 列出当前用户的全部令牌（不含明文），包括最近使用时间
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} AccessTokenListResponse "获取成功"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/tokens [get]
+func (h *AccessTokenHandler) List(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	items, err := h.svc.List(c.Request.Context(), lg, uid)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", items, int64(len(items)))
+}
+
+// @Summary 吊销个人访问令牌
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "令牌ID"
+// @Success 200 {object} LogoutResponse "吊销成功"
+// @Failure 400 {object} ErrorResponse "非法的令牌ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "令牌不存在或已吊销"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/tokens/{id} [delete]
+func (h *AccessTokenHandler) Revoke(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		lg.Warn("pat.revoke.invalid_id", zap.String("id", idStr))
+		utils.ReturnError(c, utils.ErrCodeValidation, "pat.id_invalid")
+		return
+	}
+	if err := h.svc.Revoke(c.Request.Context(), lg, uid, id); err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "令牌已吊销", nil, 1)
+}
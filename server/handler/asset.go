@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AssetHandler struct {
+	svc *service.AssetService
+}
+
+func NewAssetHandler(svc *service.AssetService) *AssetHandler {
+	return &AssetHandler{svc: svc}
+}
+
+// @Summary 上传正文图片
+// @Description 上传图片（jpeg/png/gif/webp），返回可插入任务 markdown 的引用，例如 ![](asset:123)；读取任务时引用会被替换为限时链接。未被任何任务引用的图片会在宽限期后被清理
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param file formData file true "图片"
+// @Success 200 {object} AssetUploadResponse "上传成功"
+// @Failure 400 {object} ErrorResponse "参数错误或图片不合法"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /assets [post]
+func (h *AssetHandler) Upload(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.AssetMaxBytes+1<<20)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		lg.Warn("asset.upload.form_failed", zap.Error(err))
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			utils.ReturnError(c, utils.ErrCodeValidation, "asset.too_large", config.AssetMaxBytes>>20)
+			return
+		}
+		utils.ReturnError(c, utils.ErrCodeValidation, "asset.upload_failed")
+		return
+	}
+	res, err := h.svc.Upload(c.Request.Context(), lg, uid, fh)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "上传成功", res, 1)
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AttachmentHandler struct {
+	svc *service.AttachmentService
+}
+
+func NewAttachmentHandler(svc *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc}
+}
+
+// attachmentIDs 解析路径中的任务ID与附件ID，withAttachment 为 false 时只解析任务ID
+func attachmentIDs(c *gin.Context, lg *zap.Logger, withAttachment bool) (taskID, id int, ok bool) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || taskID <= 0 {
+		lg.Warn("attachment.invalid_task_id", zap.String("id", c.Param("id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "task.id_invalid")
+		return 0, 0, false
+	}
+	if !withAttachment {
+		return taskID, 0, true
+	}
+	id, err = strconv.Atoi(c.Param("attachment_id"))
+	if err != nil || id <= 0 {
+		lg.Warn("attachment.invalid_id", zap.String("attachment_id", c.Param("attachment_id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "attachment.id_invalid")
+		return 0, 0, false
+	}
+	return taskID, id, true
+}
+
+// @Summary 上传任务附件
+// @Description 以 multipart/form-data 上传附件，字段名 file；受单文件大小、单任务数量与用户总空间限制
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param file formData file true "附件"
+// @Success 200 {object} AttachmentResponse "上传成功"
+// @Failure 400 {object} ErrorResponse "参数错误或文件过大"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Failure 409 {object} ErrorResponse "附件数量或空间已达上限"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, _, ok := attachmentIDs(c, lg, false)
+	if !ok {
+		return
+	}
+	// 预留 1MB 给 multipart 头部
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.AttachmentMaxBytes+1<<20)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		lg.Warn("attachment.upload.form_failed", zap.Error(err))
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			utils.ReturnError(c, utils.ErrCodeValidation, "attachment.too_large", config.AttachmentMaxBytes>>20)
+			return
+		}
+		utils.ReturnError(c, utils.ErrCodeValidation, "attachment.upload_failed")
+		return
+	}
+	a, err := h.svc.Upload(c.Request.Context(), lg, uid, taskID, fh)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "上传成功", a, 1)
+}
+
+// @Summary 任务附件列表
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Success 200 {object} AttachmentListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/attachments [get]
+func (h *AttachmentHandler) List(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, _, ok := attachmentIDs(c, lg, false)
+	if !ok {
+		return
+	}
+	items, err := h.svc.List(c.Request.Context(), lg, uid, taskID)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", items, int64(len(items)))
+}
+
+// @Summary 下载任务附件
+// @Description 返回附件的短时签名下载链接；redirect=true 时直接 302 跳转
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param attachment_id path integer true "附件ID"
+// @Param redirect query boolean false "是否直接跳转到链接"
+// @Success 200 {object} AttachmentLinkResponse "获取成功"
+// @Success 302 "跳转到签名链接"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "附件不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/attachments/{attachment_id} [get]
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, id, ok := attachmentIDs(c, lg, true)
+	if !ok {
+		return
+	}
+	link, err := h.svc.Download(c.Request.Context(), lg, uid, taskID, id)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	setPrivateCache(c, link.ExpiresAt)
+	if c.Query("redirect") == "true" || c.Query("redirect") == "1" {
+		c.Redirect(http.StatusFound, link.URL)
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", link, 1)
+}
+
+// @Summary 删除任务附件
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param attachment_id path integer true "附件ID"
+// @Success 200 {object} AttachmentDeleteResponse "删除成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "附件不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, id, ok := attachmentIDs(c, lg, true)
+	if !ok {
+		return
+	}
+	token, err := h.svc.Delete(c.Request.Context(), lg, uid, taskID, id)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "删除成功", gin.H{"id": id, "undo_token": token}, 1)
+}
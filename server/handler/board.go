@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type BoardMoveRequest struct {
+	TaskID int    `json:"task_id" binding:"required,gt=0"`
+	Status string `json:"status"  binding:"required,max=32" example:"in_progress"`
+	PrevID int    `json:"prev_id" binding:"omitempty,gt=0"`
+	NextID int    `json:"next_id" binding:"omitempty,gt=0"`
+}
+
+// @Summary 看板
+// @Description 按项目状态分列返回未归档的任务，列按状态顺序排列，列内按 sort_order 倒序
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Success 200 {object} BoardResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "非法的项目ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /projects/{id}/board [get]
+func (t *TaskHandler) Board(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	pid, ok := projectIDParam(c, lg)
+	if !ok {
+		return
+	}
+	board, err := t.svc.Board(c.Request.Context(), lg, uid, pid)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", board, int64(len(board.Columns)))
+}
+
+// @Summary 在看板中移动任务
+// @Description 将任务移动到 status 列中 prev_id（上方相邻任务）与 next_id（下方相邻任务）之间，状态与排序在同一事务中修改；
+// @Description 两者都省略时移到列首。相邻任务以服务端当前顺序为准，已不在该列或已不相邻时返回 409，客户端刷新看板后重试
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Param body body BoardMoveRequest true "移动位置"
+// @Success 200 {object} TaskUpdateResponse "移动成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目、任务或状态不存在"
+// @Failure 409 {object} ErrorResponse "看板已变化"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /projects/{id}/board/move [post]
+func (t *TaskHandler) MoveOnBoard(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	pid, ok := projectIDParam(c, lg)
+	if !ok {
+		return
+	}
+	var req BoardMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("task.board.move.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+	res, err := t.svc.MoveOnBoard(c.Request.Context(), lg, uid, pid, service.BoardMoveInput{
+		TaskID: req.TaskID,
+		Status: req.Status,
+		PrevID: req.PrevID,
+		NextID: req.NextID,
+	})
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "移动成功", TaskWithUndo{res.Task, res.UndoToken}, res.Affected)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	svc *service.ExportService
+}
+
+func NewExportHandler(svc *service.ExportService) *ExportHandler {
+	return &ExportHandler{svc: svc}
+}
+
+// @Summary 发起个人数据导出
+// @Description 异步打包个人资料、全部项目与任务（Markdown + 元数据）以及头像为 ZIP，通过 GET /users/me/export 查询进度与下载链接
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} ExportStatusResponse "已加入导出队列"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 409 {object} ErrorResponse "已有导出任务进行中"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/export [post]
+func (h *ExportHandler) Start(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	st, err := h.svc.Start(c.Request.Context(), lg, uid)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "已开始导出", st, 1)
+}
+
+// @Summary 查询个人数据导出进度
+// @Description 返回最近一次导出的状态与进度（0-100），完成后附带限时下载链接，链接过期后重新查询即可获取新链接
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} ExportStatusResponse "获取成功"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "暂无导出任务"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/export [get]
+func (h *ExportHandler) Status(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	view, err := h.svc.Status(c.Request.Context(), lg, uid)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", view, 1)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type MediaHandler struct {
+	svc *service.MediaService
+}
+
+func NewMediaHandler(svc *service.MediaService) *MediaHandler {
+	return &MediaHandler{svc: svc}
+}
+
+// @Summary 获取头像限时链接
+// @Description 返回头像的短时签名链接；redirect=true 时直接 302 跳转。仅能获取自己的头像，id 可传 me
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户ID或me"
+// @Param size query integer false "期望边长（64/256/512），取不小于该值的最小尺寸，默认 256"
+// @Param redirect query boolean false "是否直接跳转到链接"
+// @Success 200 {object} MediaLinkResponse "获取成功"
+// @Success 302 "跳转到签名链接"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "头像不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/{id}/avatar [get]
+func (h *MediaHandler) Avatar(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	owner := uid
+	if idStr := c.Param("id"); idStr != "me" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil || id <= 0 {
+			utils.ReturnError(c, utils.ErrCodeValidation, "user.id_invalid")
+			return
+		}
+		owner = id
+	}
+	size := 256
+	if v := c.Query("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			utils.ReturnFieldErrors(c, utils.ErrCodeValidation,
+				[]utils.FieldError{utils.NewFieldError("size", "gt", "validation.gt", "0")}, "common.validation_failed")
+			return
+		}
+		size = n
+	}
+
+	link, err := h.svc.Avatar(c.Request.Context(), lg, uid, owner, size)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	setPrivateCache(c, link.ExpiresAt)
+	if c.Query("redirect") == "true" || c.Query("redirect") == "1" {
+		c.Redirect(http.StatusFound, link.URL)
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", link, 1)
+}
+
+// @Summary 本地存储媒体代理
+// @Description 仅本地存储驱动可用；校验链接中的 HMAC 签名与过期时间后返回对象内容
+// @Produce octet-stream
+// @Param key path string true "对象key"
+// @Param exp query integer true "过期时间（Unix 秒）"
+// @Param sig query string true "签名"
+// @Param filename query string false "下载文件名"
+// @Success 200 {file} binary "对象内容"
+// @Failure 403 {object} ErrorResponse "签名无效或已过期"
+// @Failure 404 {object} ErrorResponse "对象不存在"
+// @Router /media/local/{key} [get]
+func (h *MediaHandler) ServeLocal(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	exp := c.Query("exp")
+	filename := c.Query("filename")
+
+	rc, info, err := h.svc.OpenLocal(c.Request.Context(), lg, key, exp, filename, c.Query("sig"))
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	defer rc.Close()
+
+	if ts, err := strconv.ParseInt(exp, 10, 64); err == nil {
+		setPrivateCache(c, time.Unix(ts, 0))
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	if filename != "" {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	c.Header("Content-Type", info.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(info.Size, 10))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, rc); err != nil {
+		lg.Warn("media.local.copy_failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// setPrivateCache 链接在过期前可被浏览器私有缓存，不允许共享缓存
+func setPrivateCache(c *gin.Context, expiresAt time.Time) {
+	secs := int(time.Until(expiresAt).Seconds())
+	if secs <= 0 {
+		c.Header("Cache-Control", "no-store")
+		return
+	}
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(secs))
+}
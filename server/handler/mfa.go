@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type LoginMFAReq struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,min=6,max=32"`
+}
+
+type TOTPCodeReq struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// @Summary 两步验证登录
+// @Description 提交登录返回的 mfa_token 与认证器验证码（或恢复码），获取JWT token
+// @Accept json
+// @Produce json
+// @Param body body LoginMFAReq true "两步验证参数"
+// @Success 200 {object} LoginResponse "登录成功，返回token"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "验证码错误或挑战已过期"
+// @Failure 429 {object} ErrorResponse "失败次数过多，已临时锁定"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /login/2fa [post]
+func (u *UserHandler) LoginMFA(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	start := time.Now()
+	var req LoginMFAReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("user.login_mfa.param_bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+
+	res, err := u.svc.LoginMFA(c.Request.Context(), lg, req.MFAToken, req.Code, c.ClientIP())
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			lg.Warn("user.login_mfa.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			lg.Error("user.login_mfa.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+
+	lg.Info("user.login_mfa.success", zap.Duration("elapsed_ms", time.Since(start)))
+	utils.ReturnSuccess(c, utils.CodeOK, loginMessage(res), gin.H{
+		"access_token":      res.AccessToken,
+		"token_type":        "Bearer",
+		"access_expires_at": res.AccessExpireAt.UTC().Format(time.RFC3339),
+	}, 1)
+}
+
+// @Summary 申请开启两步验证
+// @Description 生成 TOTP 密钥和 otpauth:// 链接（前端渲染为二维码），需调用确认接口后才生效
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} TOTPEnrollResponse "返回密钥与链接"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 409 {object} ErrorResponse "已开启两步验证"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/2fa/enroll [post]
+func (u *UserHandler) EnrollTOTP(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+
+	res, err := u.svc.EnrollTOTP(c.Request.Context(), lg, uid)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "请使用认证器扫码后提交验证码", gin.H{
+		"secret":      res.Secret,
+		"otpauth_uri": res.URI,
+	}, 1)
+}
+
+// @Summary 确认开启两步验证
+// @Description 提交认证器上的验证码完成开启，返回一次性展示的恢复码
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body TOTPCodeReq true "验证码"
+// @Success 200 {object} TOTPConfirmResponse "开启成功，返回恢复码"
+// @Failure 400 {object} ErrorResponse "参数错误或未申请开启"
+// @Failure 401 {object} ErrorResponse "验证码错误"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 409 {object} ErrorResponse "已开启两步验证"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/2fa/confirm [post]
+func (u *UserHandler) ConfirmTOTP(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	var req TOTPCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("user.totp_confirm.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+
+	res, err := u.svc.ConfirmTOTP(c.Request.Context(), lg, uid, req.Code)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "两步验证已开启，请妥善保存恢复码", gin.H{
+		"recovery_codes": res.RecoveryCodes,
+	}, int64(len(res.RecoveryCodes)))
+}
+
+// @Summary 关闭两步验证
+// @Description 需提交认证器上当前有效的验证码
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body TOTPCodeReq true "验证码"
+// @Success 200 {object} LogoutResponse "关闭成功"
+// @Failure 400 {object} ErrorResponse "参数错误或未开启"
+// @Failure 401 {object} ErrorResponse "验证码错误"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/2fa/disable [post]
+func (u *UserHandler) DisableTOTP(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	var req TOTPCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("user.totp_disable.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+
+	if err := u.svc.DisableTOTP(c.Request.Context(), lg, uid, req.Code); err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "两步验证已关闭", nil, 1)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type OIDCHandler struct {
+	svc *service.OIDCService
+}
+
+func NewOIDCHandler(svc *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{svc: svc}
+}
+
+// @Summary 单点登录跳转
+// @Description 生成 state/nonce/PKCE 后 302 跳转到身份提供方授权页
+// @Produce json
+// @Param provider path string true "提供方名称（config.yml 中 oidc.providers[].name）"
+// @Success 302 "跳转到身份提供方"
+// @Failure 404 {object} ErrorResponse "未配置该登录方式"
+// @Failure 500 {object} ErrorResponse "身份提供方暂不可用"
+// @Router /auth/oidc/{provider}/login [get]
+func (o *OIDCHandler) Login(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	provider := c.Param("provider")
+
+	authURL, err := o.svc.BeginLogin(c.Request.Context(), lg, provider)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary 单点登录回调
+// @Description 身份提供方回调，校验后按外部身份或已验证邮箱关联账户并签发JWT token
+// @Produce json
+// @Param provider path string true "提供方名称"
+// @Param code query string true "授权码"
+// @Param state query string true "发起登录时生成的 state"
+// @Success 200 {object} LoginResponse "登录成功，返回token或两步验证挑战"
+// @Failure 400 {object} ErrorResponse "参数错误或用户拒绝授权"
+// @Failure 401 {object} ErrorResponse "state 失效或 ID Token 校验失败"
+// @Failure 404 {object} ErrorResponse "未配置该登录方式或未找到账户"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /auth/oidc/{provider}/callback [get]
+func (o *OIDCHandler) Callback(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	start := time.Now()
+	provider := c.Param("provider")
+	lg = lg.With(zap.String("provider", provider))
+
+	if e := c.Query("error"); e != "" {
+		lg.Warn("oidc.callback.provider_error", zap.String("error", e), zap.String("desc", c.Query("error_description")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "oidc.denied")
+		return
+	}
+	code := strings.TrimSpace(c.Query("code"))
+	state := strings.TrimSpace(c.Query("state"))
+	if code == "" || state == "" {
+		lg.Warn("oidc.callback.param_missing")
+		utils.ReturnError(c, utils.ErrCodeValidation, "common.bad_request")
+		return
+	}
+
+	res, err := o.svc.FinishLogin(c.Request.Context(), lg, provider, state, code, c.ClientIP())
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			lg.Warn("oidc.callback.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			lg.Error("oidc.callback.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+
+	if res.MFARequired {
+		utils.ReturnSuccess(c, utils.CodeOK, "请输入两步验证码", gin.H{
+			"mfa_required":   true,
+			"mfa_token":      res.MFAToken,
+			"mfa_expires_at": res.MFAExpireAt.UTC().Format(time.RFC3339),
+		}, 1)
+		return
+	}
+	lg.Info("oidc.callback.success", zap.Duration("elapsed_ms", time.Since(start)))
+	utils.ReturnSuccess(c, utils.CodeOK, loginMessage(res), gin.H{
+		"access_token":      res.AccessToken,
+		"token_type":        "Bearer",
+		"access_expires_at": res.AccessExpireAt.UTC().Format(time.RFC3339),
+	}, 1)
+}
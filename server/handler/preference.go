@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type UpdatePreferencesReq struct {
+	Timezone           *string `json:"timezone"              binding:"omitempty,max=64" example:"Asia/Shanghai"`
+	Locale             *string `json:"locale"                binding:"omitempty,oneof=zh-CN en-US"`
+	WeekStart          *int    `json:"week_start"            binding:"omitempty,gte=0,lte=6"`
+	DefaultPriority    *int    `json:"default_priority"      binding:"omitempty,gte=1,lte=5"`
+	DefaultProjectID   *int    `json:"default_project_id"    binding:"omitempty,gte=0"`
+	ReminderEnabled    *bool   `json:"reminder_enabled"`
+	ReminderMinutes    *int    `json:"reminder_minutes"      binding:"omitempty,gte=0,lte=10080"`
+	AllDayReminderTime *string `json:"all_day_reminder_time" binding:"omitempty,len=5" example:"09:00"`
+}
+
+// @Summary 获取偏好设置
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} PreferencesResponse "获取成功"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/preferences [get]
+func (u *UserHandler) GetPreferences(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	p, err := u.svc.GetPreferences(c.Request.Context(), lg, uid)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", p, 1)
+}
+
+// @Summary 更新偏好设置
+// @Description 时区（IANA 名称）、语言、每周起始日（0 周日~6 周六）、默认优先级、默认项目（0 清除）及提醒默认值（是否提醒、提前分钟数、全天任务提醒时间 HH:MM）
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body UpdatePreferencesReq true "需要修改的偏好"
+// @Success 200 {object} PreferencesResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "默认项目不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me/preferences [patch]
+func (u *UserHandler) UpdatePreferences(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	var req UpdatePreferencesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("user.prefs.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+	p, err := u.svc.UpdatePreferences(c.Request.Context(), lg, uid, service.UpdatePreferencesInput{
+		Timezone:           req.Timezone,
+		Locale:             req.Locale,
+		WeekStart:          req.WeekStart,
+		DefaultPriority:    req.DefaultPriority,
+		DefaultProjectID:   req.DefaultProjectID,
+		ReminderEnabled:    req.ReminderEnabled,
+		ReminderMinutes:    req.ReminderMinutes,
+		AllDayReminderTime: req.AllDayReminderTime,
+	})
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "更新成功", p, 1)
+}
@@ -26,9 +26,8 @@ type CreateReq struct {
 }
 
 type UpdateReq struct {
-	Name      *string `json:"name"  binding:"omitempty,min=1,max=128"`
-	Color     *string `json:"color" binding:"omitempty,max=16"`
-	SortOrder *int64  `json:"sort_order" binding:"omitempty"`
+	Name  *string `json:"name"  binding:"omitempty,min=1,max=128"`
+	Color *string `json:"color" binding:"omitempty,max=16"`
 }
 
 // @Summary 获取项目详情
@@ -40,6 +39,7 @@ type UpdateReq struct {
 // @Success 200 {object} ProjectDetailResponse "获取成功，返回项目信息"
 // @Failure 400 {object} ErrorResponse "非法的项目ID"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "项目不存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /projects/{id} [get]
@@ -54,10 +54,10 @@ func (p *ProjectHandler) GetProjectByID(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("project.search.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Warn("project.search.error", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -77,6 +77,7 @@ func (p *ProjectHandler) GetProjectByID(c *gin.Context) {
 // @Param page_size query integer false "每页数量（默认20，最大100）"
 // @Success 200 {object} ProjectListResponse "获取成功，返回项目列表"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /projects [get]
 func (p *ProjectHandler) Search(c *gin.Context) {
@@ -98,10 +99,10 @@ func (p *ProjectHandler) Search(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("project.list.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Error("project.list.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -123,6 +124,7 @@ func (p *ProjectHandler) Search(c *gin.Context) {
 // @Success 200 {object} ProjectCreateResponse "创建成功，返回项目信息"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 409 {object} ErrorResponse "项目重复"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /projects [post]
@@ -133,13 +135,13 @@ func (p *ProjectHandler) Create(c *gin.Context) {
 	var req CreateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		lg.Warn("project.create.param_bind_failed", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "参数格式错误："+err.Error())
+		utils.ReturnBindError(c, err)
 		return
 	}
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
 		lg.Warn("project.create.empty_name")
-		utils.ReturnError(c, utils.ErrCodeValidation, "项目名称不可为空")
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.name_required")
 		return
 	}
 	lg = lg.With(zap.Int("uid", uid), zap.String("name", name))
@@ -149,21 +151,22 @@ func (p *ProjectHandler) Create(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("project.create.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 			return
 		}
 		lg.Error("project.create.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
-		utils.ReturnError(c, utils.ErrCodeInternalServer, "保存失败，请联系管理员")
+		utils.ReturnError(c, utils.ErrCodeInternalServer, "common.save_failed")
 		return
 	}
 	lg.Info("project.create.success", zap.Int("id", res.Project.ID), zap.Duration("elapsed_ms", time.Since(start)))
 	utils.ReturnSuccess(c, utils.CodeOK, "项目创建成功", gin.H{
-		"project": res.Project,
+		"project":    res.Project,
+		"undo_token": res.UndoToken,
 	}, 1)
 }
 
 // @Summary 更新项目信息
-// @Description 更新项目的名称、颜色和排序顺序（可选项）
+// @Description 更新项目的名称和颜色（可选项）；不再接受 sort_order，调整顺序使用 /projects/{id}/reorder，由服务端计算排序键
 // @Accept json
 // @Produce json
 // @Security Bearer
@@ -172,6 +175,7 @@ func (p *ProjectHandler) Create(c *gin.Context) {
 // @Success 200 {object} ProjectUpdateResponse "更新成功，返回更新后的项目信息"
 // @Failure 400 {object} ErrorResponse "非法的项目ID或参数格式错误"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "项目不存在"
 // @Failure 409 {object} ErrorResponse "项目重复"
 // @Failure 500 {object} ErrorResponse "系统错误"
@@ -185,19 +189,18 @@ func (p *ProjectHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id == 0 {
 		lg.Warn("project.update.param_invalid", zap.String("id", idStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的项目ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
 		return
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		lg.Warn("project.update.param_bind_failed", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "参数格式错误："+err.Error())
+		utils.ReturnBindError(c, err)
 		return
 	}
 
 	in := service.UpdateProjectInput{
-		Name:      req.Name,
-		Color:     req.Color,
-		SortOrder: req.SortOrder,
+		Name:  req.Name,
+		Color: req.Color,
 	}
 	updated, err := p.svc.UpdateProject(c.Request.Context(), lg, id, uid, in)
 
@@ -205,22 +208,23 @@ func (p *ProjectHandler) Update(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("project.update.failed", zap.Int("code", ae.Code))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Error("project.update.error", zap.Error(err))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
 
 	lg.Info("project.update.success", zap.Int64("affected", updated.Affected))
 	utils.ReturnSuccess(c, utils.CodeOK, "项目信息已更新", gin.H{
-		"project": updated.Project,
+		"project":    updated.Project,
+		"undo_token": updated.UndoToken,
 	}, updated.Affected)
 }
 
 // @Summary 删除项目
-// @Description 删除项目及其关联的所有任务
+// @Description 将项目及其任务移入回收站，保留期内可通过 /trash/projects/{id}/restore 一并恢复
 // @Accept json
 // @Produce json
 // @Security Bearer
@@ -228,6 +232,7 @@ func (p *ProjectHandler) Update(c *gin.Context) {
 // @Success 200 {object} ProjectDeleteResponse "删除成功，返回删除的项目ID和受影响的行数"
 // @Failure 400 {object} ErrorResponse "非法的项目ID"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "项目不存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /projects/{id} [delete]
@@ -239,7 +244,7 @@ func (p *ProjectHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id == 0 {
 		lg.Warn("project.delete.param_invalid", zap.String("id", idStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的项目ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
 		return
 	}
 
@@ -248,10 +253,10 @@ func (p *ProjectHandler) Delete(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("project.delete.failed", zap.Int("code", ae.Code))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Error("project.delete.error", zap.Error(err))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -261,5 +266,6 @@ func (p *ProjectHandler) Delete(c *gin.Context) {
 		"id":            id,
 		"task_affected": affected.TaskAffected,
 		"proj_affected": affected.Affected,
+		"undo_token":    affected.UndoToken,
 	}, 1)
 }
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CreateStatusRequest struct {
+	Key      string `json:"key"      binding:"required,max=32" example:"in_progress"`
+	Name     string `json:"name"     binding:"required,max=64" example:"进行中"`
+	Category string `json:"category" binding:"omitempty,oneof=open closed"`
+	Position *int   `json:"position" binding:"omitempty,gte=0"`
+}
+
+type UpdateStatusRequest struct {
+	Name     *string `json:"name"     binding:"omitempty,max=64"`
+	Category *string `json:"category" binding:"omitempty,oneof=open closed"`
+	Position *int    `json:"position" binding:"omitempty,gte=0"`
+}
+
+// StatusWithUndo 直接返回状态，撤销令牌作为附加字段
+type StatusWithUndo struct {
+	models.ProjectStatus
+	UndoToken string `json:"undo_token"`
+}
+
+// projectIDParam 解析路径中的项目ID
+func projectIDParam(c *gin.Context, lg *zap.Logger) (int, bool) {
+	pid, err := strconv.Atoi(c.Param("id"))
+	if err != nil || pid <= 0 {
+		lg.Warn("project.invalid_id", zap.String("id", c.Param("id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
+		return 0, false
+	}
+	return pid, true
+}
+
+// @Summary 项目状态列表
+// @Description 按顺序返回项目的任务状态；category 为 closed 的状态视为已完成
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Success 200 {object} StatusListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "非法的项目ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /projects/{id}/statuses [get]
+func (p *ProjectHandler) ListStatuses(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	pid, ok := projectIDParam(c, lg)
+	if !ok {
+		return
+	}
+	items, err := p.svc.ListStatuses(c.Request.Context(), lg, uid, pid)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", items, int64(len(items)))
+}
+
+// @Summary 新增项目状态
+// @Description key 为小写字母开头的字母、数字或下划线，创建后不可修改；category 默认 open；position 为空时排在最后
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Param body body CreateStatusRequest true "状态"
+// @Success 200 {object} StatusResponse "创建成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Failure 409 {object} ErrorResponse "状态已存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /projects/{id}/statuses [post]
+func (p *ProjectHandler) CreateStatus(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	pid, ok := projectIDParam(c, lg)
+	if !ok {
+		return
+	}
+	var req CreateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("project.status.create.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+	created, err := p.svc.CreateStatus(c.Request.Context(), lg, uid, pid, service.CreateStatusInput{
+		Key:      req.Key,
+		Name:     req.Name,
+		Category: req.Category,
+		Position: req.Position,
+	})
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "创建成功", StatusWithUndo{created.Status, created.UndoToken}, 1)
+}
+
+// @Summary 修改项目状态
+// @Description 修改名称、分类或顺序；分类改变时该状态下的任务随之变为已完成或未完成。每个项目至少保留一个 open 与一个 closed 状态
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Param key path string true "状态key"
+// @Param body body UpdateStatusRequest true "修改内容"
+// @Success 200 {object} StatusResponse "修改成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目或状态不存在"
+// @Failure 409 {object} ErrorResponse "该分类的最后一个状态"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /projects/{id}/statuses/{key} [patch]
+func (p *ProjectHandler) UpdateStatus(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	pid, ok := projectIDParam(c, lg)
+	if !ok {
+		return
+	}
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("project.status.update.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+	updated, err := p.svc.UpdateStatus(c.Request.Context(), lg, uid, pid, c.Param("key"), service.UpdateStatusInput{
+		Name:     req.Name,
+		Category: req.Category,
+		Position: req.Position,
+	})
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "修改成功", StatusWithUndo{updated.Status, updated.UndoToken}, 1)
+}
+
+// @Summary 删除项目状态
+// @Description 状态下仍有任务（包括回收站中的任务）时必须通过 move_to 指定迁移目标；撤销时以原ID重建状态并把任务移回
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Param key path string true "状态key"
+// @Param move_to query string false "任务迁移到的状态key"
+// @Success 200 {object} StatusDeleteResponse "删除成功"
+// @Failure 400 {object} ErrorResponse "非法的项目ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目或状态不存在"
+// @Failure 409 {object} ErrorResponse "状态下仍有任务或为该分类的最后一个状态"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /projects/{id}/statuses/{key} [delete]
+func (p *ProjectHandler) DeleteStatus(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	pid, ok := projectIDParam(c, lg)
+	if !ok {
+		return
+	}
+	res, err := p.svc.DeleteStatus(c.Request.Context(), lg, uid, pid, c.Param("key"), c.Query("move_to"))
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "删除成功", gin.H{
+		"key":         c.Param("key"),
+		"tasks_moved": res.TasksMoved,
+		"undo_token":  res.UndoToken,
+	}, 1)
+}
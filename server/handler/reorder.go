@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ReorderRequest struct {
+	AfterID  int `json:"after_id"  binding:"omitempty,gt=0"`
+	BeforeID int `json:"before_id" binding:"omitempty,gt=0"`
+}
+
+// bindReorder 解析路径ID与请求体，失败时已写入响应
+func bindReorder(c *gin.Context, lg *zap.Logger, idKey string) (int, service.ReorderInput, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		lg.Warn("reorder.invalid_id", zap.String("id", c.Param("id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, idKey)
+		return 0, service.ReorderInput{}, false
+	}
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("reorder.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return 0, service.ReorderInput{}, false
+	}
+	return id, service.ReorderInput{AfterID: req.AfterID, BeforeID: req.BeforeID}, true
+}
+
+// @Summary 调整任务顺序
+// @Description 将任务放到同项目中 after_id 下方、before_id 上方，排序键由服务端计算，键过密时自动重新分配。
+// @Description 至少给出一个参照任务；两者都给出时必须相邻，否则返回 409，客户端刷新列表后重试
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param body body ReorderRequest true "参照位置"
+// @Success 200 {object} TaskUpdateResponse "调整成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Failure 409 {object} ErrorResponse "列表已变化"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/reorder [post]
+func (t *TaskHandler) Reorder(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	id, in, ok := bindReorder(c, lg, "task.id_invalid")
+	if !ok {
+		return
+	}
+	res, err := t.svc.Reorder(c.Request.Context(), lg, uid, id, in)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "调整成功", TaskWithUndo{res.Task, res.UndoToken}, res.Affected)
+}
+
+// @Summary 调整项目顺序
+// @Description 将项目放到 after_id 下方、before_id 上方，规则同调整任务顺序
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Param body body ReorderRequest true "参照位置"
+// @Success 200 {object} ProjectUpdateResponse "调整成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Failure 409 {object} ErrorResponse "列表已变化"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /projects/{id}/reorder [post]
+func (p *ProjectHandler) Reorder(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	id, in, ok := bindReorder(c, lg, "project.id_invalid")
+	if !ok {
+		return
+	}
+	res, err := p.svc.Reorder(c.Request.Context(), lg, uid, id, in)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "调整成功", gin.H{
+		"project":    res.Project,
+		"undo_token": res.UndoToken,
+	}, res.Affected)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type StorageGCHandler struct {
+	svc *service.StorageGCService
+}
+
+func NewStorageGCHandler(svc *service.StorageGCService) *StorageGCHandler {
+	return &StorageGCHandler{svc: svc}
+}
+
+// @Summary 清理孤儿对象
+// @Description 遍历 images/ 与 attachments/ 下的对象，删除未被数据库引用且超过宽限期的对象；exports/ 下超过导出保留时长（24 小时）的导出文件一并删除。默认 dry_run=true 只返回报告不删除
+// @Produce json
+// @Security Bearer
+// @Param dry_run query boolean false "仅输出报告，默认 true"
+// @Success 200 {object} StorageGCResponse "执行完成"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 409 {object} ErrorResponse "清理正在进行中"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /admin/storage/gc [post]
+func (h *StorageGCHandler) Run(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	dryRun := true
+	if v := c.Query("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.ReturnFieldErrors(c, utils.ErrCodeValidation,
+				[]utils.FieldError{utils.NewFieldError("dry_run", "type", "validation.type", "boolean")}, "common.validation_failed")
+			return
+		}
+		dryRun = b
+	}
+	lg.Info("storage_gc.manual", zap.Int("uid", c.GetInt("uid")), zap.Bool("dry_run", dryRun))
+	rep, err := h.svc.Run(c.Request.Context(), lg, dryRun)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "执行完成", rep, int64(rep.Orphaned))
+}
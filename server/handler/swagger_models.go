@@ -3,17 +3,23 @@ package handler
 import (
 	"ToDoList/server/models"
 	"ToDoList/server/service"
+	"ToDoList/server/utils"
 )
 
 type ErrorResponse struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
+	Code   int                `json:"code"`
+	Key    string             `json:"key"`
+	Msg    string             `json:"msg"`
+	Errors []utils.FieldError `json:"errors,omitempty"`
 }
 
 type LoginData struct {
 	AccessToken     string `json:"access_token"`
 	TokenType       string `json:"token_type"`
 	AccessExpiresAt string `json:"access_expires_at"`
+	MFARequired     bool   `json:"mfa_required,omitempty"`
+	MFAToken        string `json:"mfa_token,omitempty"`
+	MFAExpiresAt    string `json:"mfa_expires_at,omitempty"`
 }
 
 type LoginResponse struct {
@@ -37,6 +43,45 @@ type LogoutResponse struct {
 	Count int64       `json:"count"`
 }
 
+type MediaLinkResponse struct {
+	Code  int                `json:"code"`
+	Msg   string             `json:"msg"`
+	Data  service.SignedLink `json:"data"`
+	Count int64              `json:"count"`
+}
+
+type ExportStatusResponse struct {
+	Code  int                `json:"code"`
+	Msg   string             `json:"msg"`
+	Data  service.ExportView `json:"data"`
+	Count int64              `json:"count"`
+}
+
+type PreferencesResponse struct {
+	Code  int                 `json:"code"`
+	Msg   string              `json:"msg"`
+	Data  service.Preferences `json:"data"`
+	Count int64               `json:"count"`
+}
+
+type AgendaResponse struct {
+	Code  int                  `json:"code"`
+	Msg   string               `json:"msg"`
+	Data  service.AgendaResult `json:"data"`
+	Count int64                `json:"count"`
+}
+
+type DeleteAccountData struct {
+	DeletionScheduledAt string `json:"deletion_scheduled_at"`
+}
+
+type DeleteAccountResponse struct {
+	Code  int               `json:"code"`
+	Msg   string            `json:"msg"`
+	Data  DeleteAccountData `json:"data"`
+	Count int64             `json:"count"`
+}
+
 type UpdateUserResponse struct {
 	Code  int         `json:"code"`
 	Msg   string      `json:"msg"`
@@ -44,6 +89,48 @@ type UpdateUserResponse struct {
 	Count int64       `json:"count"`
 }
 
+type TOTPEnrollData struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type TOTPEnrollResponse struct {
+	Code  int            `json:"code"`
+	Msg   string         `json:"msg"`
+	Data  TOTPEnrollData `json:"data"`
+	Count int64          `json:"count"`
+}
+
+type TOTPConfirmData struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TOTPConfirmResponse struct {
+	Code  int             `json:"code"`
+	Msg   string          `json:"msg"`
+	Data  TOTPConfirmData `json:"data"`
+	Count int64           `json:"count"`
+}
+
+type AccessTokenCreateData struct {
+	Token       string                     `json:"token"`
+	AccessToken models.PersonalAccessToken `json:"access_token"`
+}
+
+type AccessTokenCreateResponse struct {
+	Code  int                   `json:"code"`
+	Msg   string                `json:"msg"`
+	Data  AccessTokenCreateData `json:"data"`
+	Count int64                 `json:"count"`
+}
+
+type AccessTokenListResponse struct {
+	Code  int                          `json:"code"`
+	Msg   string                       `json:"msg"`
+	Data  []models.PersonalAccessToken `json:"data"`
+	Count int64                        `json:"count"`
+}
+
 type ProjectDetailData struct {
 	Project service.ProjectProfile `json:"project"`
 }
@@ -70,7 +157,8 @@ type ProjectListResponse struct {
 }
 
 type ProjectCreateData struct {
-	Project models.Project `json:"project"`
+	Project   models.Project `json:"project"`
+	UndoToken string         `json:"undo_token"`
 }
 
 type ProjectCreateResponse struct {
@@ -81,7 +169,8 @@ type ProjectCreateResponse struct {
 }
 
 type ProjectUpdateData struct {
-	Project models.Project `json:"project"`
+	Project   models.Project `json:"project"`
+	UndoToken string         `json:"undo_token"`
 }
 
 type ProjectUpdateResponse struct {
@@ -92,9 +181,10 @@ type ProjectUpdateResponse struct {
 }
 
 type ProjectDeleteData struct {
-	ID           int   `json:"id"`
-	TaskAffected int64 `json:"task_affected"`
-	ProjAffected int64 `json:"proj_affected"`
+	ID           int    `json:"id"`
+	TaskAffected int64  `json:"task_affected"`
+	ProjAffected int64  `json:"proj_affected"`
+	UndoToken    string `json:"undo_token"`
 }
 
 type ProjectDeleteResponse struct {
@@ -105,7 +195,8 @@ type ProjectDeleteResponse struct {
 }
 
 type TaskCreateData struct {
-	Task models.Task `json:"task"`
+	Task      models.Task `json:"task"`
+	UndoToken string      `json:"undo_token"`
 }
 
 type TaskCreateResponse struct {
@@ -116,15 +207,16 @@ type TaskCreateResponse struct {
 }
 
 type TaskUpdateResponse struct {
-	Code  int         `json:"code"`
-	Msg   string      `json:"msg"`
-	Data  models.Task `json:"data"`
-	Count int64       `json:"count"`
+	Code  int          `json:"code"`
+	Msg   string       `json:"msg"`
+	Data  TaskWithUndo `json:"data"`
+	Count int64        `json:"count"`
 }
 
 type TaskDeleteData struct {
-	ID           int   `json:"id"`
-	TaskAffected int64 `json:"task_affected"`
+	ID           int    `json:"id"`
+	TaskAffected int64  `json:"task_affected"`
+	UndoToken    string `json:"undo_token"`
 }
 
 type TaskDeleteResponse struct {
@@ -154,3 +246,167 @@ type TaskListResponse struct {
 	Data  TaskListData `json:"data"`
 	Count int64        `json:"count"`
 }
+
+type AttachmentResponse struct {
+	Code  int               `json:"code"`
+	Msg   string            `json:"msg"`
+	Data  models.Attachment `json:"data"`
+	Count int64             `json:"count"`
+}
+
+type AttachmentListResponse struct {
+	Code  int                 `json:"code"`
+	Msg   string              `json:"msg"`
+	Data  []models.Attachment `json:"data"`
+	Count int64               `json:"count"`
+}
+
+type AttachmentLinkResponse struct {
+	Code  int                    `json:"code"`
+	Msg   string                 `json:"msg"`
+	Data  service.AttachmentLink `json:"data"`
+	Count int64                  `json:"count"`
+}
+
+type AttachmentDeleteData struct {
+	ID        int    `json:"id"`
+	UndoToken string `json:"undo_token"`
+}
+
+type AttachmentDeleteResponse struct {
+	Code  int                  `json:"code"`
+	Msg   string               `json:"msg"`
+	Data  AttachmentDeleteData `json:"data"`
+	Count int64                `json:"count"`
+}
+
+type StorageGCResponse struct {
+	Code  int                     `json:"code"`
+	Msg   string                  `json:"msg"`
+	Data  service.StorageGCReport `json:"data"`
+	Count int64                   `json:"count"`
+}
+
+type AssetUploadResponse struct {
+	Code  int                       `json:"code"`
+	Msg   string                    `json:"msg"`
+	Data  service.AssetUploadResult `json:"data"`
+	Count int64                     `json:"count"`
+}
+
+type RevisionListData struct {
+	List     []models.TaskRevision `json:"list"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Total    int64                 `json:"total"`
+}
+
+type RevisionListResponse struct {
+	Code  int              `json:"code"`
+	Msg   string           `json:"msg"`
+	Data  RevisionListData `json:"data"`
+	Count int64            `json:"count"`
+}
+
+type RevisionResponse struct {
+	Code  int                 `json:"code"`
+	Msg   string              `json:"msg"`
+	Data  models.TaskRevision `json:"data"`
+	Count int64               `json:"count"`
+}
+
+type RevisionDiffResponse struct {
+	Code  int                  `json:"code"`
+	Msg   string               `json:"msg"`
+	Data  service.RevisionDiff `json:"data"`
+	Count int64                `json:"count"`
+}
+
+type TrashListData struct {
+	List     []service.TrashEntry `json:"list"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+	Total    int64                `json:"total"`
+}
+
+type TrashListResponse struct {
+	Code  int           `json:"code"`
+	Msg   string        `json:"msg"`
+	Data  TrashListData `json:"data"`
+	Count int64         `json:"count"`
+}
+
+type TrashRestoreProjectData struct {
+	Project   models.Project `json:"project"`
+	TaskIDs   []int          `json:"task_ids"`
+	UndoToken string         `json:"undo_token"`
+}
+
+type TrashRestoreProjectResponse struct {
+	Code  int                     `json:"code"`
+	Msg   string                  `json:"msg"`
+	Data  TrashRestoreProjectData `json:"data"`
+	Count int64                   `json:"count"`
+}
+
+type UndoResponse struct {
+	Code  int               `json:"code"`
+	Msg   string            `json:"msg"`
+	Data  models.UndoResult `json:"data"`
+	Count int64             `json:"count"`
+}
+
+type ArchivedTaskListData struct {
+	List     []models.Task `json:"list"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	Total    int64         `json:"total"`
+}
+
+type ArchivedTaskListResponse struct {
+	Code  int                  `json:"code"`
+	Msg   string               `json:"msg"`
+	Data  ArchivedTaskListData `json:"data"`
+	Count int64                `json:"count"`
+}
+
+type StatusListResponse struct {
+	Code  int                    `json:"code"`
+	Msg   string                 `json:"msg"`
+	Data  []models.ProjectStatus `json:"data"`
+	Count int64                  `json:"count"`
+}
+
+type StatusResponse struct {
+	Code  int            `json:"code"`
+	Msg   string         `json:"msg"`
+	Data  StatusWithUndo `json:"data"`
+	Count int64          `json:"count"`
+}
+
+type StatusDeleteData struct {
+	Key        string `json:"key"`
+	TasksMoved int    `json:"tasks_moved"`
+	UndoToken  string `json:"undo_token"`
+}
+
+type StatusDeleteResponse struct {
+	Code  int              `json:"code"`
+	Msg   string           `json:"msg"`
+	Data  StatusDeleteData `json:"data"`
+	Count int64            `json:"count"`
+}
+
+type BoardResponse struct {
+	Code  int           `json:"code"`
+	Msg   string        `json:"msg"`
+	Data  service.Board `json:"data"`
+	Count int64         `json:"count"`
+}
+
+type BulkTaskResponse struct {
+	Code  int                `json:"code"`
+	Msg   string             `json:"msg"`
+	Data  service.BulkResult `json:"data"`
+	Count int64              `json:"count"`
+}
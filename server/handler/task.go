@@ -1,12 +1,12 @@
 package handler
 
 import (
+	"ToDoList/server/models"
 	"ToDoList/server/service"
 	"ToDoList/server/utils"
 	"errors"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -20,15 +20,18 @@ func NewTaskHandler(svc *service.TaskService) *TaskHandler {
 }
 type CreateTaskRequest struct {
 	Title     string     `json:"title" binding:"required,max=200"`
-	ProjectID int        `json:"project_id" binding:"required"`
+	ProjectID int        `json:"project_id" binding:"omitempty,gt=0"`
 	ContentMD *string    `json:"content_md"`
 	Priority  *int       `json:"priority"`
 	Status    *string    `json:"status"`
-	DueAt     *time.Time `json:"due_at"`
+	Tags      []string   `json:"tags"`
+	DueAt     *string    `json:"due_at" example:"2026-01-02 18:00"`
 }
 
 // @Summary 创建任务
-// @Description 在指定项目下创建新任务
+// @Description 在指定项目下创建新任务；未指定项目时使用默认项目，未指定优先级时使用默认优先级，未指定状态时使用项目中第一个未完成状态。
+// @Description tags 最多 20 个，每个不超过 32 个字符，去除首尾空白与重复后按顺序保存。
+// @Description due_at 支持 RFC3339，或不带时区的 "YYYY-MM-DD HH:MM" / "YYYY-MM-DD"（按用户时区解释，仅日期视为当天结束）
 // @Accept json
 // @Produce json
 // @Security Bearer
@@ -36,6 +39,7 @@ type CreateTaskRequest struct {
 // @Success 200 {object} TaskCreateResponse "创建成功，返回任务信息"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "项目不存在"
 // @Failure 409 {object} ErrorResponse "任务已存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
@@ -47,7 +51,7 @@ func (t *TaskHandler) Create(c *gin.Context) {
 	var req CreateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		lg.Warn("task.create.bind_failed", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "请求参数错误")
+		utils.ReturnBindError(c, err)
 		return
 	}
 
@@ -57,6 +61,7 @@ func (t *TaskHandler) Create(c *gin.Context) {
 		ContentMD: req.ContentMD,
 		Priority:  req.Priority,
 		Status:    req.Status,
+		Tags:      req.Tags,
 		DueAt:     req.DueAt,
 	}
 
@@ -64,29 +69,39 @@ func (t *TaskHandler) Create(c *gin.Context) {
 	if err != nil {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnFieldErrors(c, ae.Code, ae.Fields, ae.Key, ae.Args...)
 		} else {
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
 	utils.ReturnSuccess(c, utils.CodeOK, "创建成功", gin.H{
-		"task": created.Task,
+		"task":       created.Task,
+		"undo_token": created.UndoToken,
 	}, 1)
 }
 
+// TaskWithUndo 修改类接口直接返回任务，撤销令牌作为附加字段
+type TaskWithUndo struct {
+	models.Task
+	UndoToken string `json:"undo_token"`
+}
+
 type UpdateTaskRequest struct {
 	Title       *string    `json:"title"          binding:"omitempty,max=200"`
 	ReProjectID *int       `json:"re_project_id" binding:"omitempty,gt=0"`
 	ContentMD   *string    `json:"content_md"`
 	Priority    *int       `json:"priority"   binding:"omitempty,gte=1,lte=5"`
-	Status      *string    `json:"status"     binding:"omitempty,oneof=todo done"`
-	SortOrder   *int64     `json:"sort_order" binding:"omitempty,gte=0"`
-	ReDueAt     *time.Time `json:"re_due_at"`
+	Status      *string    `json:"status"     binding:"omitempty,max=32"`
+	Tags        *[]string  `json:"tags"`
+	ReDueAt     *string    `json:"re_due_at" example:"2026-01-02 18:00"`
 }
 
 // @Summary 更新任务
-// @Description 更新任务的名称、内容、状态、优先级、项目和截止时间
+// @Description 更新任务的名称、内容、状态、优先级、标签、项目和截止时间；re_due_at 格式同创建任务的 due_at
+// @Description tags 整体替换任务的标签，传空数组清空；批量增删标签使用 /tasks/bulk
+// @Description 移动到其他项目时沿用同名状态，目标项目没有同名状态时使用同分类的第一个状态
+// @Description 不再接受 sort_order，调整顺序使用 /tasks/{id}/reorder，由服务端计算排序键
 // @Accept json
 // @Produce json
 // @Security Bearer
@@ -96,6 +111,7 @@ type UpdateTaskRequest struct {
 // @Success 200 {object} TaskUpdateResponse "更新成功，返回任务信息"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "任务不存在或项目不存在"
 // @Failure 409 {object} ErrorResponse "任务已存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
@@ -108,19 +124,19 @@ func (t *TaskHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(taskIDStr)
 	if err != nil || id <= 0 {
 		lg.Warn("task.update.invalid_id", zap.String("id", taskIDStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的任务ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "task.id_invalid")
 		return
 	}
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil || pid <= 0 {
 		lg.Warn("task.update.invalid_pid", zap.String("pid", pidStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的项目ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
 		return
 	}
 	var req UpdateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		lg.Warn("task.update.bind_failed", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "请求参数错误")
+		utils.ReturnBindError(c, err)
 		return
 	}
 	in := service.UpdateTaskInput{
@@ -129,30 +145,31 @@ func (t *TaskHandler) Update(c *gin.Context) {
 		ContentMD: req.ContentMD,
 		Priority:  req.Priority,
 		Status:    req.Status,
+		Tags:      req.Tags,
 		ReDueAt:   req.ReDueAt,
 	}
 	updated, err := t.svc.Update(c.Request.Context(), lg, uid, pid, id, in)
 	if err != nil {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnFieldErrors(c, ae.Code, ae.Fields, ae.Key, ae.Args...)
 		} else {
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
 
 	if updated.Affected == 0 {
 		lg.Info("task.update.no_rows_affected", zap.Int("task_id", id))
-		utils.ReturnSuccess(c, utils.CodeOK, "未修改任何字段", updated.Task, updated.Affected)
+		utils.ReturnSuccess(c, utils.CodeOK, "未修改任何字段", TaskWithUndo{updated.Task, updated.UndoToken}, updated.Affected)
 		return
 	}
 	lg.Info("task.update.success", zap.Int("task_id", id), zap.Int64("affected", updated.Affected))
-	utils.ReturnSuccess(c, utils.CodeOK, "任务更新成功", updated.Task, updated.Affected)
+	utils.ReturnSuccess(c, utils.CodeOK, "任务更新成功", TaskWithUndo{updated.Task, updated.UndoToken}, updated.Affected)
 }
 
 // @Summary 删除任务
-// @Description 删除指定项目下的任务
+// @Description 将指定项目下的任务移入回收站，保留期内可通过 /trash/tasks/{id}/restore 恢复
 // @Accept json
 // @Produce json
 // @Security Bearer
@@ -161,6 +178,7 @@ func (t *TaskHandler) Update(c *gin.Context) {
 // @Success 200 {object} TaskDeleteResponse "删除成功，返回任务ID和受影响的行数"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "任务不存在或项目不存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /tasks/{id} [delete]
@@ -171,29 +189,30 @@ func (t *TaskHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
 		lg.Warn("task.delete.invalid_id", zap.String("id", idStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的任务ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "task.id_invalid")
 		return
 	}
 	pidStr := strings.TrimSpace(c.Query("project_id"))
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil || pid <= 0 {
 		lg.Warn("task.delete.project_id_invalid", zap.String("project_id", pidStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的项目ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
 		return
 	}
-	affected, err := t.svc.Delete(c.Request.Context(), lg, uid, pid, id)
+	res, err := t.svc.Delete(c.Request.Context(), lg, uid, pid, id)
 	if err != nil {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
 	utils.ReturnSuccess(c, utils.CodeOK, "删除成功", gin.H{
 		"id":            id,
-		"task_affected": affected,
+		"task_affected": res.Affected,
+		"undo_token":    res.UndoToken,
 	}, 1)
 }
 
@@ -207,6 +226,7 @@ func (t *TaskHandler) Delete(c *gin.Context) {
 // @Success 200 {object} TaskDetailResponse "获取成功，返回任务详情"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "任务不存在或项目不存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /projects/{id}/tasks/{task_id} [get]
@@ -218,22 +238,22 @@ func (t *TaskHandler) Search(c *gin.Context) {
 	id, err := strconv.Atoi(taskIDStr)
 	if err != nil || id == 0 {
 		lg.Warn("task.search.invalid_id", zap.String("id", taskIDStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的任务ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "task.id_invalid")
 		return
 	}
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil || pid <= 0 {
 		lg.Warn("task.search.invalid_pid", zap.String("pid", pidStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的项目ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
 		return
 	}
 	task, err := t.svc.Search(c.Request.Context(), lg, id, uid, pid)
 	if err != nil {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -242,17 +262,19 @@ func (t *TaskHandler) Search(c *gin.Context) {
 }
 
 // @Summary 获取任务列表
-// @Description 获取指定项目下的任务列表，支持状态筛选和分页
+// @Description 获取指定项目下的任务列表，支持状态、标签筛选和分页
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param project_id query integer true "项目ID"
-// @Param status query string false "任务状态（todo/done）"
+// @Param status query string false "任务状态，项目中某个状态的 key"
+// @Param tag query string false "只返回带有该标签的任务"
 // @Param page query integer false "页码（默认1）"
 // @Param page_size query integer false "每页数量（默认20，最大100）"
 // @Success 200 {object} TaskListResponse "获取成功，返回任务列表"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 404 {object} ErrorResponse "项目不存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /tasks [get]
@@ -267,13 +289,14 @@ func (t *TaskHandler) List(c *gin.Context) {
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil || pid <= 0 {
 		lg.Warn("task.list.project_id_invalid", zap.String("project_id", pidStr), zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "非法的项目ID")
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
 		return
 	}
 	in := service.TaskListInput{
 		Page:   page,
 		Size:   size,
 		Status: status,
+		Tag:    c.Query("tag"),
 		Pid:    pid,
 	}
 	res, err := t.svc.List(c.Request.Context(), lg, uid, in)
@@ -281,9 +304,9 @@ func (t *TaskHandler) List(c *gin.Context) {
 	if err != nil {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -296,3 +319,35 @@ func (t *TaskHandler) List(c *gin.Context) {
 	}, res.Total)
 
 }
+
+// @Summary 日程视图
+// @Description 按用户时区与每周起始日划分边界，返回当天或当周截止的任务
+// @Produce json
+// @Security Bearer
+// @Param range query string false "day（默认）或 week"
+// @Param date query string false "日期 YYYY-MM-DD，默认今天"
+// @Success 200 {object} AgendaResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/agenda [get]
+func (t *TaskHandler) Agenda(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	in := service.AgendaInput{
+		Range: strings.TrimSpace(c.DefaultQuery("range", service.AgendaDay)),
+		Date:  c.Query("date"),
+	}
+	res, err := t.svc.Agenda(c.Request.Context(), lg, uid, in)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", res, int64(len(res.Items)))
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary 归档任务
+// @Description 只能归档已完成的任务；归档后不再出现在任务列表中，可在归档视图查看。已完成超过设定时长的任务会被自动归档
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Success 200 {object} TaskUpdateResponse "归档成功"
+// @Failure 400 {object} ErrorResponse "非法的任务ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Failure 409 {object} ErrorResponse "任务未完成"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/archive [post]
+func (t *TaskHandler) Archive(c *gin.Context) {
+	t.setArchived(c, true)
+}
+
+// @Summary 取消归档
+// @Description 任务保持已完成状态回到任务列表；重新打开已归档的任务也会取消归档
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Success 200 {object} TaskUpdateResponse "已取消归档"
+// @Failure 400 {object} ErrorResponse "非法的任务ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/unarchive [post]
+func (t *TaskHandler) Unarchive(c *gin.Context) {
+	t.setArchived(c, false)
+}
+
+func (t *TaskHandler) setArchived(c *gin.Context, archived bool) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		lg.Warn("task.archive.invalid_id", zap.String("id", c.Param("id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "task.id_invalid")
+		return
+	}
+	var res *service.UpdateTaskResult
+	if archived {
+		res, err = t.svc.Archive(c.Request.Context(), lg, uid, id)
+	} else {
+		res, err = t.svc.Unarchive(c.Request.Context(), lg, uid, id)
+	}
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	msg := "归档成功"
+	if !archived {
+		msg = "已取消归档"
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, msg, TaskWithUndo{res.Task, res.UndoToken}, res.Affected)
+}
+
+// @Summary 归档视图
+// @Description 按归档时间倒序列出已归档的任务，不含正文
+// @Produce json
+// @Security Bearer
+// @Param project_id query integer false "项目ID，默认全部项目"
+// @Param page query integer false "页码（默认1）"
+// @Param page_size query integer false "每页数量（默认20，最大100）"
+// @Success 200 {object} ArchivedTaskListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/archive [get]
+func (t *TaskHandler) ListArchived(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	pid := 0
+	if s := c.Query("project_id"); s != "" {
+		var err error
+		if pid, err = strconv.Atoi(s); err != nil || pid <= 0 {
+			lg.Warn("task.archived.invalid_project_id", zap.String("project_id", s))
+			utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
+			return
+		}
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	res, err := t.svc.ListArchived(c.Request.Context(), lg, uid, pid, page, size)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", gin.H{
+		"list":      res.Tasks,
+		"page":      page,
+		"page_size": size,
+		"total":     res.Total,
+	}, res.Total)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type BulkTaskRequest struct {
+	Op         string   `json:"op"          binding:"required"                example:"complete"`
+	IDs        []int    `json:"ids"         binding:"required"`
+	ProjectID  int      `json:"project_id"  binding:"omitempty,gt=0"`
+	Priority   int      `json:"priority"`
+	AddTags    []string `json:"add_tags"`
+	RemoveTags []string `json:"remove_tags"`
+}
+
+// @Summary 批量操作任务
+// @Description op 取值 complete、reopen、move_project（需 project_id）、set_priority（需 priority）、delete、tag（add_tags 与 remove_tags 至少给出一个，先移除再添加）。
+// @Description 最多 100 个任务，在一个事务中执行；单个任务失败不影响其余任务，逐项返回结果。成功的修改共用一个撤销令牌
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body BulkTaskRequest true "批量操作"
+// @Success 200 {object} BulkTaskResponse "执行完成"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "目标项目不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/bulk [post]
+func (t *TaskHandler) Bulk(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	var req BulkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("task.bulk.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+	res, err := t.svc.Bulk(c.Request.Context(), lg, uid, service.BulkInput{
+		Op:         req.Op,
+		IDs:        req.IDs,
+		ProjectID:  req.ProjectID,
+		Priority:   req.Priority,
+		AddTags:    req.AddTags,
+		RemoveTags: req.RemoveTags,
+	})
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	locale := utils.RequestLocale(c)
+	for i, r := range res.Results {
+		if r.Key != "" {
+			res.Results[i].Msg = utils.Translate(locale, r.Key, r.Args...)
+		}
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "执行完成", res, int64(res.Succeeded))
+}
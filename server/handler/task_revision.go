@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// revisionIDs 解析路径中的任务ID与版本号
+func revisionIDs(c *gin.Context, lg *zap.Logger, withRev bool) (taskID, rev int, ok bool) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || taskID <= 0 {
+		lg.Warn("task.revision.invalid_task_id", zap.String("id", c.Param("id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "task.id_invalid")
+		return 0, 0, false
+	}
+	if !withRev {
+		return taskID, 0, true
+	}
+	rev, err = strconv.Atoi(c.Param("rev"))
+	if err != nil || rev <= 0 {
+		lg.Warn("task.revision.invalid_rev", zap.String("rev", c.Param("rev")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "revision.invalid")
+		return 0, 0, false
+	}
+	return taskID, rev, true
+}
+
+// @Summary 任务历史版本列表
+// @Description 按版本号倒序返回，不含正文；每次修改标题、正文、状态、优先级、项目或截止时间都会产生新版本
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param page query integer false "页码（默认1）"
+// @Param page_size query integer false "每页数量（默认20，最大100）"
+// @Success 200 {object} RevisionListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/revisions [get]
+func (t *TaskHandler) ListRevisions(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, _, ok := revisionIDs(c, lg, false)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	res, err := t.svc.ListRevisions(c.Request.Context(), lg, uid, taskID, page, size)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", gin.H{
+		"list":      res.Revisions,
+		"page":      page,
+		"page_size": size,
+		"total":     res.Total,
+	}, res.Total)
+}
+
+// @Summary 获取任务历史版本
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param rev path integer true "版本号"
+// @Success 200 {object} RevisionResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "版本不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/revisions/{rev} [get]
+func (t *TaskHandler) GetRevision(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, rev, ok := revisionIDs(c, lg, true)
+	if !ok {
+		return
+	}
+	r, err := t.svc.GetRevision(c.Request.Context(), lg, uid, taskID, rev)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", r, 1)
+}
+
+type revisionDiffQuery struct {
+	From    int  `form:"from" binding:"required,gt=0"`
+	To      int  `form:"to" binding:"required,gt=0"`
+	Context *int `form:"context" binding:"omitempty,gte=0,lte=20"`
+}
+
+// @Summary 比较任务的两个版本
+// @Description 返回字段差异与正文的 unified diff
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param from query integer true "起始版本号"
+// @Param to query integer true "目标版本号"
+// @Param context query integer false "上下文行数（默认3，最大20）"
+// @Success 200 {object} RevisionDiffResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "版本不存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/revisions/diff [get]
+func (t *TaskHandler) DiffRevisions(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, _, ok := revisionIDs(c, lg, false)
+	if !ok {
+		return
+	}
+	var q revisionDiffQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		lg.Warn("task.revision.diff.bind_failed", zap.Error(err))
+		utils.ReturnBindError(c, err)
+		return
+	}
+	contextLines := 3
+	if q.Context != nil {
+		contextLines = *q.Context
+	}
+	d, err := t.svc.DiffRevisions(c.Request.Context(), lg, uid, taskID, q.From, q.To, contextLines)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", d, 1)
+}
+
+// @Summary 恢复任务历史版本
+// @Description 将标题与正文恢复为指定版本并重新渲染，恢复操作本身记录为新版本
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Param rev path integer true "版本号"
+// @Success 200 {object} TaskUpdateResponse "恢复成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "任务或版本不存在"
+// @Failure 409 {object} ErrorResponse "同项目下已有同名任务"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /tasks/{id}/revisions/{rev}/restore [post]
+func (t *TaskHandler) RestoreRevision(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	taskID, rev, ok := revisionIDs(c, lg, true)
+	if !ok {
+		return
+	}
+	res, err := t.svc.RestoreRevision(c.Request.Context(), lg, uid, taskID, rev)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "恢复成功", TaskWithUndo{res.Task, res.UndoToken}, res.Affected)
+}
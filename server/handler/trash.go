@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TrashHandler struct {
+	svc *service.TrashService
+}
+
+func NewTrashHandler(svc *service.TrashService) *TrashHandler {
+	return &TrashHandler{svc: svc}
+}
+
+// @Summary 回收站列表
+// @Description 按删除时间倒序列出已删除的项目与任务；随项目一起删除的任务计入项目的 task_count，不单独列出
+// @Produce json
+// @Security Bearer
+// @Param type query string false "task 或 project，默认全部"
+// @Param page query integer false "页码（默认1）"
+// @Param page_size query integer false "每页数量（默认20，最大100）"
+// @Success 200 {object} TrashListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /trash [get]
+func (h *TrashHandler) List(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	res, err := h.svc.List(c.Request.Context(), lg, uid, strings.TrimSpace(c.Query("type")), page, size)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "获取成功", gin.H{
+		"list":      res.Items,
+		"page":      page,
+		"page_size": size,
+		"total":     res.Total,
+	}, res.Total)
+}
+
+// @Summary 从回收站恢复任务
+// @Description 所属项目也在回收站中时需先恢复项目
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "任务ID"
+// @Success 200 {object} TaskCreateResponse "恢复成功"
+// @Failure 400 {object} ErrorResponse "非法的任务ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "回收站中没有该任务"
+// @Failure 409 {object} ErrorResponse "同名任务已存在或所属项目已删除"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /trash/tasks/{id}/restore [post]
+func (h *TrashHandler) RestoreTask(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		lg.Warn("trash.restore_task.invalid_id", zap.String("id", c.Param("id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "task.id_invalid")
+		return
+	}
+	res, err := h.svc.RestoreTask(c.Request.Context(), lg, uid, id)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "恢复成功", gin.H{
+		"task":       res.Task,
+		"undo_token": res.UndoToken,
+	}, 1)
+}
+
+// @Summary 从回收站恢复项目
+// @Description 一并恢复随项目删除的任务；在此之前单独删除的任务仍留在回收站
+// @Produce json
+// @Security Bearer
+// @Param id path integer true "项目ID"
+// @Success 200 {object} TrashRestoreProjectResponse "恢复成功"
+// @Failure 400 {object} ErrorResponse "非法的项目ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "回收站中没有该项目"
+// @Failure 409 {object} ErrorResponse "同名项目已存在"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /trash/projects/{id}/restore [post]
+func (h *TrashHandler) RestoreProject(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		lg.Warn("trash.restore_project.invalid_id", zap.String("id", c.Param("id")))
+		utils.ReturnError(c, utils.ErrCodeValidation, "project.id_invalid")
+		return
+	}
+	res, err := h.svc.RestoreProject(c.Request.Context(), lg, uid, id)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "恢复成功", gin.H{
+		"project":    res.Project,
+		"task_ids":   res.TaskIDs,
+		"undo_token": res.UndoToken,
+	}, 1)
+}
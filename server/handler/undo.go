@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"ToDoList/server/service"
+	"ToDoList/server/utils"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UndoHandler struct {
+	svc *service.UndoService
+}
+
+func NewUndoHandler(svc *service.UndoService) *UndoHandler {
+	return &UndoHandler{svc: svc}
+}
+
+// @Summary 撤销操作
+// @Description 撤销任务或项目的创建、修改（含移动、完成）、删除与恢复，以及项目状态的增删改与附件删除；令牌由对应接口返回的 undo_token 提供，短时间内有效且只能使用一次。
+// @Description 撤销因记录已被再次修改而失败时令牌作废；因同名冲突或系统错误失败时令牌保留，可以重试
+// @Description 操作之后记录又被修改过时返回 409，不会覆盖新的修改
+// @Produce json
+// @Security Bearer
+// @Param token path string true "撤销令牌"
+// @Success 200 {object} UndoResponse "撤销成功，返回受影响的任务与项目ID"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 404 {object} ErrorResponse "令牌已过期或已使用"
+// @Failure 409 {object} ErrorResponse "记录已被再次修改或存在同名记录"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /undo/{token} [post]
+func (h *UndoHandler) Undo(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	uid := c.GetInt("uid")
+	res, err := h.svc.Undo(c.Request.Context(), lg, uid, c.GetStringSlice("scopes"), c.Param("token"))
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+	utils.ReturnSuccess(c, utils.CodeOK, "撤销成功", res, int64(len(res.TaskIDs)+len(res.ProjectIDs)))
+}
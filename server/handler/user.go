@@ -32,6 +32,10 @@ type UpdateUserReq struct {
 	ConfirmPassword *string `json:"confirm_password" form:"confirm_password" binding:"omitempty,required_with=Password,eqfield=Password"`
 }
 
+type DeleteAccountReq struct {
+	Password string `json:"password" binding:"required,max=72"`
+}
+
 type UserHandler struct {
 	svc *service.UserService
 }
@@ -41,14 +45,15 @@ func NewUserHandler(svc *service.UserService) *UserHandler {
 }
 
 // @Summary 用户登录
-// @Description 使用用户名和密码进行身份验证，获取JWT token
+// @Description 使用用户名和密码进行身份验证，获取JWT token；开启两步验证的用户返回 mfa_token，需再调用 /login/2fa
 // @Accept json
 // @Produce json
 // @Param body body LoginReq true "登录请求参数"
-// @Success 200 {object} LoginResponse "登录成功，返回token"
+// @Success 200 {object} LoginResponse "登录成功，返回token或两步验证挑战"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 401 {object} ErrorResponse "登录失败"
 // @Failure 404 {object} ErrorResponse "用户不存在"
+// @Failure 429 {object} ErrorResponse "失败次数过多，已临时锁定"
 // @Failure 500 {object} ErrorResponse "系统错误"
 // @Router /login [post]
 func (u *UserHandler) Login(c *gin.Context) {
@@ -57,26 +62,36 @@ func (u *UserHandler) Login(c *gin.Context) {
 	var req LoginReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		lg.Warn("user.login.param_bind_failed", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "参数格式有误，请重新输入")
+		utils.ReturnBindError(c, err)
 		return
 	}
 	lg = lg.With(zap.String("username", req.Username))
 
-	res, err := u.svc.Login(c.Request.Context(), lg, req.Username, req.Password)
+	res, err := u.svc.Login(c.Request.Context(), lg, req.Username, req.Password, c.ClientIP())
 	if err != nil {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("user.login.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Error("user.login.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
 
+	if res.MFARequired {
+		lg.Info("user.login.mfa_required", zap.Duration("elapsed_ms", time.Since(start)))
+		utils.ReturnSuccess(c, utils.CodeOK, "请输入两步验证码", gin.H{
+			"mfa_required":   true,
+			"mfa_token":      res.MFAToken,
+			"mfa_expires_at": res.MFAExpireAt.UTC().Format(time.RFC3339),
+		}, 1)
+		return
+	}
+
 	lg.Info("user.login.success", zap.Duration("elapsed_ms", time.Since(start)))
-	utils.ReturnSuccess(c, utils.CodeOK, "登陆成功", gin.H{
+	utils.ReturnSuccess(c, utils.CodeOK, loginMessage(res), gin.H{
 		"access_token":      res.AccessToken,
 		"token_type":        "Bearer",
 		"access_expires_at": res.AccessExpireAt.UTC().Format(time.RFC3339),
@@ -91,7 +106,7 @@ func (u *UserHandler) Login(c *gin.Context) {
 // @Param username formData string true "用户名（2-64字符）"
 // @Param password formData string true "密码（8-72字符）"
 // @Param confirm_password formData string true "确认密码"
-// @Param file formData file true "头像文件"
+// @Param file formData file true "头像文件（JPEG/PNG/GIF/WebP，不超过 5MB，裁剪为 64/256/512 正方形 JPEG）"
 // @Success 200 {object} RegisterResponse "注册成功，返回用户信息"
 // @Failure 400 {object} ErrorResponse "参数错误或头像上传失败"
 // @Failure 409 {object} ErrorResponse "用户已存在"
@@ -104,14 +119,14 @@ func (u *UserHandler) Register(c *gin.Context) {
 	var req RegisterReq
 	if err := c.ShouldBindWith(&req, binding.FormMultipart); err != nil {
 		lg.Warn("user.register.param_bind_failed", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "参数格式错误："+err.Error())
+		utils.ReturnBindError(c, err)
 		return
 	}
 	lg = lg.With(zap.String("username", req.Username), zap.String("email", req.Email))
 	fh, err := c.FormFile("file")
 	if err != nil {
 		lg.Warn("user.register.avatar_missing", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "头像上传失败："+err.Error())
+		utils.ReturnError(c, utils.ErrCodeValidation, "user.avatar_upload_failed")
 		return
 	}
 	res, err := u.svc.Register(c.Request.Context(), lg, req.Email, req.Username, req.Password, fh)
@@ -119,10 +134,10 @@ func (u *UserHandler) Register(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("user.register.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Error("user.register.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -137,6 +152,7 @@ func (u *UserHandler) Register(c *gin.Context) {
 // @Security Bearer
 // @Success 200 {object} LogoutResponse "退出登录成功"
 // @Failure 401 {object} ErrorResponse "未授权或token无效"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 500 {object} ErrorResponse "写入Redis出错"
 // @Router /logout [post]
 func (u *UserHandler) Logout(c *gin.Context) {
@@ -146,26 +162,26 @@ func (u *UserHandler) Logout(c *gin.Context) {
 	uidAny, ok := c.Get("uid")
 	if !ok {
 		lg.Warn("user.logout.uid_missing")
-		utils.ReturnError(c, utils.ErrCodeAuthFailed, "缺失用户参数")
+		utils.ReturnError(c, utils.ErrCodeAuthFailed, "auth.unauthorized")
 		return
 	}
 	uid, ok := uidAny.(int)
 	if !ok || uid <= 0 {
 		lg.Warn("user.logout.uid_invalid", zap.Any("uid_any", uidAny))
-		utils.ReturnError(c, utils.ErrCodeAuthFailed, "用户参数格式出错")
+		utils.ReturnError(c, utils.ErrCodeAuthFailed, "auth.unauthorized")
 		return
 	}
 	lg = lg.With(zap.Int("uid", uid))
 	v, ok := c.Get("claims")
 	if !ok {
 		lg.Warn("user.logout.claims_missing")
-		utils.ReturnError(c, utils.ErrCodeAuthFailed, "用户未授权")
+		utils.ReturnError(c, utils.ErrCodeAuthFailed, "auth.unauthorized")
 		return
 	}
 	claims, ok := v.(*utils.Claims)
 	if !ok {
 		lg.Warn("user.logout.claims_invalid", zap.Any("claims_type", v))
-		utils.ReturnError(c, utils.ErrCodeAuthFailed, "用户未授权")
+		utils.ReturnError(c, utils.ErrCodeAuthFailed, "auth.unauthorized")
 		return
 	}
 
@@ -173,10 +189,10 @@ func (u *UserHandler) Logout(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("user.logout.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Error("user.logout.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -194,9 +210,10 @@ func (u *UserHandler) Logout(c *gin.Context) {
 // @Param username formData string false "用户名（2-64字符）"
 // @Param password formData string false "新密码（8-72字符）"
 // @Param confirm_password formData string false "确认新密码"
-// @Param file formData file false "头像文件（可选）"
+// @Param file formData file false "头像文件（可选，要求同注册）"
 // @Success 200 {object} UpdateUserResponse "更新成功,如更新密码则刷新token"
 // @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "权限不足"
 // @Failure 400 {object} ErrorResponse "参数错误"
 // @Failure 409 {object} ErrorResponse "用户名或邮箱已存在"
 // @Failure 500 {object} ErrorResponse "系统错误"
@@ -209,7 +226,7 @@ func (u *UserHandler) Update(c *gin.Context) {
 	var req UpdateUserReq
 	if err := c.ShouldBindWith(&req, binding.FormMultipart); err != nil {
 		lg.Warn("user.update.param_bind_failed", zap.Error(err))
-		utils.ReturnError(c, utils.ErrCodeValidation, "参数格式有误，请重新输入"+err.Error())
+		utils.ReturnBindError(c, err)
 		return
 	}
 
@@ -220,7 +237,7 @@ func (u *UserHandler) Update(c *gin.Context) {
 			fh = nil
 		} else {
 			lg.Warn("user.update.avatar_read_failed", zap.Error(err))
-			utils.ReturnError(c, utils.ErrCodeValidation, "头像上传失败："+err.Error())
+			utils.ReturnError(c, utils.ErrCodeValidation, "user.avatar_upload_failed")
 			return
 		}
 	} else {
@@ -240,10 +257,10 @@ func (u *UserHandler) Update(c *gin.Context) {
 		var ae *service.AppError
 		if errors.As(err, &ae) {
 			lg.Warn("user.update.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, ae.Code, ae.Message)
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 		} else {
 			lg.Error("user.update.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
-			utils.ReturnError(c, utils.ErrCodeInternalServer, "系统错误")
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
 		}
 		return
 	}
@@ -260,3 +277,55 @@ func (u *UserHandler) Update(c *gin.Context) {
 		"user":              res.User,
 	}, res.Affected)
 }
+
+// loginMessage 宽限期内登录会撤销注销申请，需提示用户
+func loginMessage(res *service.LoginResult) string {
+	if res.DeletionCanceled {
+		return "登陆成功，已撤销账户注销申请"
+	}
+	return "登陆成功"
+}
+
+// @Summary 注销账户
+// @Description 校验密码后进入注销宽限期：立即退出全部会话并吊销个人访问令牌，宽限期内重新登录可撤销；到期后删除全部任务、项目、头像与缓存
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body DeleteAccountReq true "当前密码"
+// @Success 200 {object} DeleteAccountResponse "已进入注销宽限期"
+// @Failure 400 {object} ErrorResponse "参数错误"
+// @Failure 401 {object} ErrorResponse "未授权或密码错误"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Failure 409 {object} ErrorResponse "账户已在注销流程中"
+// @Failure 500 {object} ErrorResponse "系统错误"
+// @Router /users/me [delete]
+func (u *UserHandler) Delete(c *gin.Context) {
+	lg := utils.CtxLogger(c)
+	start := time.Now()
+	uid := c.GetInt("uid")
+	lg = lg.With(zap.Int("uid", uid))
+	var req DeleteAccountReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn("user.delete.param_bind_failed", zap.Error(err))
+		utils.ReturnError(c, utils.ErrCodeValidation, "account.password_required")
+		return
+	}
+
+	at, err := u.svc.RequestDeletion(c.Request.Context(), lg, uid, req.Password)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			lg.Warn("user.delete.failed", zap.Int("code", ae.Code), zap.Duration("elapsed_ms", time.Since(start)))
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			lg.Error("user.delete.error", zap.Error(err), zap.Duration("elapsed_ms", time.Since(start)))
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+		}
+		return
+	}
+
+	lg.Info("user.delete.scheduled", zap.Duration("elapsed_ms", time.Since(start)))
+	utils.ReturnSuccess(c, utils.CodeOK, "账户将在宽限期后注销，期间重新登录可撤销", gin.H{
+		"deletion_scheduled_at": at.UTC().Format(time.RFC3339),
+	}, 1)
+}
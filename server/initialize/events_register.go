@@ -36,5 +36,15 @@ func InitAsyncHandlers(d *async.Dispatcher) {
 			JobTimeout:     5 * time.Second,
 			AttemptTimeout: 1 * time.Second,
 		})
+	d.Register("TouchAccessToken", handlers.TouchAccessToken,
+		async.TimeoutPolicy{
+			JobTimeout:     5 * time.Second,
+			AttemptTimeout: 1 * time.Second,
+		})
+	d.Register("BuildExport", handlers.BuildExport,
+		async.TimeoutPolicy{
+			JobTimeout:     10 * time.Minute,
+			AttemptTimeout: 3 * time.Minute,
+		})
 
 }
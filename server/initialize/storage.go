@@ -0,0 +1,30 @@
+package initialize
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/storage"
+	"fmt"
+)
+
+func InitStorage() error {
+	cfg, err := config.LoadStorageConfig()
+	if err != nil {
+		return err
+	}
+	var s storage.Storage
+	switch cfg.Driver {
+	case "cos":
+		s, err = storage.NewCOS(cfg.COS.BucketURL, cfg.COS.SecretID, cfg.COS.SecretKey)
+	case "s3":
+		s, err = storage.NewS3(cfg.S3.Endpoint, cfg.S3.Region, cfg.S3.Bucket, cfg.S3.AccessKey, cfg.S3.SecretKey, cfg.S3.PathStyle)
+	case "local":
+		s, err = storage.NewLocal(cfg.Local.Dir, cfg.Local.BaseURL, cfg.Local.SigningKey)
+	default:
+		return fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("init %s storage failed: %w", cfg.Driver, err)
+	}
+	storage.Default = s
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"ToDoList/server/initialize"
 	"ToDoList/server/models"
 	"ToDoList/server/service"
-	"ToDoList/server/utils"
 	"context"
 	"log"
 	"net/http"
@@ -17,7 +16,7 @@ import (
 
 // @title ToDoList API
 // @version 1.0
-// @description 管理API
+// @description 管理API。错误默认返回 {code, key, msg}；请求头 Accept 含 application/problem+json 时返回 RFC 7807 格式
 // @basePath /api/v1
 // @securityDefinitions.apikey Bearer
 // @in header
@@ -25,11 +24,13 @@ import (
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
     defer stop()
-	utils.InitCos()
+	if err := initialize.InitStorage(); err != nil {
+		panic(err)
+	}
 	if err := initialize.InitMySQL(); err != nil {
 		panic(err)
 	}
-	if err := initialize.Db.AutoMigrate(&models.User{}, &models.Task{}, &models.Project{}); err != nil {
+	if err := initialize.Db.AutoMigrate(&models.User{}, &models.Task{}, &models.Project{}, &models.RecoveryCode{}, &models.UserIdentity{}, &models.PersonalAccessToken{}, &models.Attachment{}, &models.Asset{}, &models.TaskAsset{}, &models.TaskRevision{}, &models.ProjectStatus{}); err != nil {
 		panic(err)
 	}
 
@@ -37,6 +38,21 @@ func main() {
 		panic(err)
 	}
 	models.NewDB(initialize.Db)
+	if _, err := models.BackfillRemindAt(ctx); err != nil {
+		panic(err)
+	}
+	if err := models.MigrateSoftDeleteIndexes(ctx); err != nil {
+		panic(err)
+	}
+	if err := models.DropObsoleteTaskIndexes(ctx); err != nil {
+		panic(err)
+	}
+	if _, err := models.SeedProjectStatuses(ctx); err != nil {
+		panic(err)
+	}
+	if _, err := models.BackfillCompletedAt(ctx); err != nil {
+		panic(err)
+	}
 	service.NewCache(initialize.Rdb)
 	dispatcher := async.NewDispatcher(256)
 	dispatcher.Start(4)
@@ -14,15 +14,19 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		lg := utils.CtxLogger(c)
 		authz := c.GetHeader("Authorization")
 		if !strings.HasPrefix(authz, "Bearer ") {
-			utils.ReturnError(c, 4001, "没有授权token")
+			utils.ReturnError(c, utils.ErrCodeAuthFailed, "auth.token_missing")
 			c.Abort()
 			return
 		}
 
 		tokenStr := strings.TrimSpace(strings.TrimPrefix(authz, "Bearer "))
+		if strings.HasPrefix(tokenStr, utils.PATPrefix) {
+			authAccessToken(c, authService, lg, tokenStr)
+			return
+		}
 		claims, err := utils.Parse(tokenStr)
 		if err != nil {
-			utils.ReturnError(c, 4001, "token已不可用")
+			utils.ReturnError(c, utils.ErrCodeAuthFailed, "auth.token_invalid")
 			c.Abort()
 			return
 		}
@@ -31,10 +35,10 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		if err != nil {
 			var ae *service.AppError
 			if errors.As(err, &ae) {
-				utils.ReturnError(c, ae.Code, ae.Message)
+				utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 			} else {
 				lg.Error("auth_Validate_Jti", zap.Error(err))
-				utils.ReturnError(c, 5001, "服务忙，请稍后重试")
+				utils.ReturnError(c, utils.ErrCodeInternalServer, "common.busy")
 			}
 			c.Abort()
 			return
@@ -44,10 +48,10 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		if err != nil {
 			var ae *service.AppError
 			if errors.As(err, &ae) {
-				utils.ReturnError(c, ae.Code, ae.Message)
+				utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
 			} else {
 				lg.Error("auth_Validate_version", zap.Error(err))
-				utils.ReturnError(c, 5001, "服务忙，请稍后重试")
+				utils.ReturnError(c, utils.ErrCodeInternalServer, "common.busy")
 			}
 			c.Abort()
 			return
@@ -56,6 +60,38 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		c.Set("uid", claims.UID)
 		c.Set("username", claims.Username)
 		c.Set("claims", claims)
+		c.Set("scopes", claims.GrantedScopes())
+		setUserLocale(c, lg, claims.UID)
 		c.Next()
 	}
 }
+
+// authAccessToken 个人访问令牌认证，权限取令牌 scopes 与账户角色的交集
+func authAccessToken(c *gin.Context, authService *service.AuthService, lg *zap.Logger, tokenStr string) {
+	p, err := authService.ValidateAccessToken(c.Request.Context(), lg, tokenStr)
+	if err != nil {
+		var ae *service.AppError
+		if errors.As(err, &ae) {
+			utils.ReturnError(c, ae.Code, ae.Key, ae.Args...)
+		} else {
+			lg.Error("auth_Validate_pat", zap.Error(err))
+			utils.ReturnError(c, utils.ErrCodeInternalServer, "common.busy")
+		}
+		c.Abort()
+		return
+	}
+	c.Set("uid", p.UID)
+	c.Set("username", p.Username)
+	c.Set("token_id", p.TokenID)
+	c.Set("scopes", utils.LimitScopes(p.Scopes, p.Role))
+	setUserLocale(c, lg, p.UID)
+	c.Next()
+}
+
+// setUserLocale 请求未指定可用的 Accept-Language 时，错误文案使用用户偏好语言
+func setUserLocale(c *gin.Context, lg *zap.Logger, uid int) {
+	if utils.MatchAcceptLanguage(c.GetHeader("Accept-Language")) != "" {
+		return
+	}
+	c.Set("locale", service.UserLocale(c.Request.Context(), lg, uid))
+}
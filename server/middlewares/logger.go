@@ -2,10 +2,10 @@ package middlewares
 
 import (
 	"ToDoList/server/reqctx"
+	"ToDoList/server/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"net/http"
 	"time"
 )
 
@@ -58,7 +58,8 @@ func RecoveryWithZap() gin.HandlerFunc {
 					zap.Any("panic", rec),
 					zap.Stack("stack"),
 				)
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"code": 5000, "msg": "Internal Server Error"})
+				utils.ReturnError(c, utils.ErrCodeInternalServer, "common.internal")
+				c.Abort()
 			}
 		}()
 		c.Next()
@@ -1,7 +1,7 @@
 package middlewares
 
 import (
-    "net/http"
+    "ToDoList/server/utils"
     "strconv"
     "sync"
     "time"
@@ -73,10 +73,8 @@ func RateLimitMiddleware(rps int, burst int) gin.HandlerFunc {
         }
         limiter := getVisitor(key+"|"+route, rps, burst)
         if !limiter.Allow() {
-            c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-                "code":    429,
-                "message": "too many requests, please try again later",
-            })
+            utils.ReturnError(c, utils.ErrCodeTooManyRequests, "common.rate_limited")
+            c.Abort()
             return
         }
         c.Next()
@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"ToDoList/server/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequireScope 要求当前令牌具备指定权限，需挂在 AuthMiddleware 之后
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes := c.GetStringSlice("scopes")
+		if !utils.HasScope(scopes, scope) {
+			utils.CtxLogger(c).Warn("auth.scope_denied",
+				zap.Int("uid", c.GetInt("uid")),
+				zap.String("required", scope),
+				zap.Strings("scopes", scopes))
+			utils.ReturnError(c, utils.ErrCodeForbidden, "auth.forbidden")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken 个人访问令牌，只保存 SHA-256 哈希
+type PersonalAccessToken struct {
+	ID         int        `gorm:"primaryKey"                  json:"id"`
+	UserID     int        `gorm:"not null;index"              json:"user_id"`
+	Name       string     `gorm:"size:64;not null"            json:"name"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Prefix     string     `gorm:"size:16;not null"            json:"prefix"`
+	Scopes     string     `gorm:"size:255;not null"           json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func AddAccessToken(ctx context.Context, t PersonalAccessToken) (PersonalAccessToken, error) {
+	if err := d.Db.WithContext(ctx).Create(&t).Error; err != nil {
+		return PersonalAccessToken{}, err
+	}
+	return t, nil
+}
+
+func ListAccessTokens(ctx context.Context, uid int) ([]PersonalAccessToken, error) {
+	var items []PersonalAccessToken
+	err := d.Db.WithContext(ctx).Where("user_id = ?", uid).Order("id DESC").Find(&items).Error
+	return items, err
+}
+
+func CountActiveAccessTokens(ctx context.Context, uid int) (int64, error) {
+	var n int64
+	err := d.Db.WithContext(ctx).Model(&PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", uid, time.Now()).
+		Count(&n).Error
+	return n, err
+}
+
+func GetAccessTokenByHash(ctx context.Context, hash string) (PersonalAccessToken, error) {
+	var t PersonalAccessToken
+	err := d.Db.WithContext(ctx).Where("token_hash = ?", hash).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return PersonalAccessToken{}, nil
+	}
+	return t, err
+}
+
+// RevokeAccessToken 吊销令牌，返回被吊销令牌的哈希用于清理缓存
+func RevokeAccessToken(ctx context.Context, uid, id int) (string, error) {
+	var t PersonalAccessToken
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, uid).First(&t).Error; err != nil {
+			return err
+		}
+		return tx.Model(&t).Update("revoked_at", time.Now()).Error
+	})
+	return t.TokenHash, err
+}
+
+func TouchAccessToken(ctx context.Context, id int, at time.Time) error {
+	return d.Db.WithContext(ctx).Model(&PersonalAccessToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDeletionPending = errors.New("账户已在注销流程中")
+
+// ScheduleUserDeletion 标记账户待注销：递增 token_version 让所有会话失效，并吊销全部个人访问令牌
+// 返回被吊销令牌的哈希用于清理缓存
+func ScheduleUserDeletion(ctx context.Context, uid int, at time.Time) (User, []string, error) {
+	var user User
+	var hashes []string
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&User{}).
+			Where("id = ? AND deletion_scheduled_at IS NULL", uid).
+			Updates(map[string]interface{}{
+				"deletion_scheduled_at": at,
+				"token_version":         gorm.Expr("token_version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrDeletionPending
+		}
+		if err := tx.Model(&PersonalAccessToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", uid).
+			Pluck("token_hash", &hashes).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&PersonalAccessToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", uid).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", uid).First(&user).Error
+	})
+	return user, hashes, err
+}
+
+// CancelUserDeletion 宽限期内撤销注销申请
+func CancelUserDeletion(ctx context.Context, uid int) (int64, error) {
+	res := d.Db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", uid).
+		Update("deletion_scheduled_at", nil)
+	return res.RowsAffected, res.Error
+}
+
+// FindUsersDueForPurge 查找宽限期已过的账户
+func FindUsersDueForPurge(ctx context.Context, now time.Time, limit int) ([]User, error) {
+	var users []User
+	err := d.Db.WithContext(ctx).
+		Select("id, username").
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// PurgedUser 账户删除前的快照，用于清理缓存和对象存储
+type PurgedUser struct {
+	User        User
+	TokenHashes []string
+	ObjectKeys  []string // 任务附件与正文图片
+}
+
+// PurgeUser 在同一事务中删除账户及其全部数据
+// 账户不存在或已撤销注销时返回 gorm.ErrRecordNotFound
+func PurgeUser(ctx context.Context, uid int, now time.Time) (PurgedUser, error) {
+	var res PurgedUser
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", uid, now).
+			First(&res.User).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&PersonalAccessToken{}).Where("user_id = ?", uid).
+			Pluck("token_hash", &res.TokenHashes).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Attachment{}).Where("user_id = ?", uid).
+			Pluck("object_key", &res.ObjectKeys).Error; err != nil {
+			return err
+		}
+		assetKeys, err := deleteAssetsWhere(tx, "user_id = ?", uid)
+		if err != nil {
+			return err
+		}
+		res.ObjectKeys = append(res.ObjectKeys, assetKeys...)
+		for _, m := range []interface{}{&Attachment{}, &TaskRevision{}, &Task{}, &ProjectStatus{}, &Project{}, &RecoveryCode{}, &UserIdentity{}, &PersonalAccessToken{}} {
+			if err := tx.Unscoped().Where("user_id = ?", uid).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&User{}, uid).Error
+	})
+	return res, err
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskCounts 项目下的任务数，Open、Closed 与 ByStatus 不含已归档的任务
+type TaskCounts struct {
+	Open     int64            `json:"open"`
+	Closed   int64            `json:"closed"`
+	Archived int64            `json:"archived"`
+	ByStatus map[string]int64 `json:"by_status"`
+}
+
+// ArchiveDoneTasks 归档完成时间早于 before 的任务（即处于 closed 分类的状态），返回被归档任务的 id、user_id、project_id 用于清理缓存
+// 只写 archived_at，不改变 updated_at
+func ArchiveDoneTasks(ctx context.Context, before time.Time, limit int) ([]Task, error) {
+	var items []Task
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id, user_id, project_id").
+			Where("archived_at IS NULL AND completed_at < ?", before).
+			Order("completed_at ASC").Limit(limit).Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		ids := make([]int, len(items))
+		for i, t := range items {
+			ids[i] = t.ID
+		}
+		return tx.Model(&Task{}).
+			Where("id IN ? AND archived_at IS NULL AND completed_at IS NOT NULL", ids).
+			UpdateColumn("archived_at", time.Now()).Error
+	})
+	return items, err
+}
+
+// ListArchivedTasks 按归档时间倒序分页，pid 为 0 时列出全部项目，不含正文
+func ListArchivedTasks(ctx context.Context, uid, pid, offset, limit int) ([]Task, int64, error) {
+	var items []Task
+	var total int64
+	tx := d.Db.WithContext(ctx).Model(&Task{}).Where("user_id = ? AND archived_at IS NOT NULL", uid)
+	if pid > 0 {
+		tx = tx.Where("project_id = ?", pid)
+	}
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := tx.Omit("content_md", "content_html").Order("archived_at DESC, id DESC").
+		Offset(offset).Limit(limit).Find(&items).Error
+	return items, total, err
+}
+
+// CountProjectTasks 统计项目下未归档任务在各状态与分类中的数量以及已归档任务数
+func CountProjectTasks(ctx context.Context, uid, pid int) (TaskCounts, error) {
+	var rows []struct {
+		Status   string
+		Closed   bool
+		Archived bool
+		N        int64
+	}
+	err := d.Db.WithContext(ctx).Model(&Task{}).
+		Select("status, completed_at IS NOT NULL AS closed, archived_at IS NOT NULL AS archived, COUNT(*) AS n").
+		Where("user_id = ? AND project_id = ?", uid, pid).
+		Group("status, closed, archived").Scan(&rows).Error
+	c := TaskCounts{ByStatus: map[string]int64{}}
+	for _, r := range rows {
+		switch {
+		case r.Archived:
+			c.Archived += r.N
+			continue
+		case r.Closed:
+			c.Closed += r.N
+		default:
+			c.Open += r.N
+		}
+		c.ByStatus[r.Status] += r.N
+	}
+	return c, err
+}
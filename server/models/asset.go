@@ -0,0 +1,239 @@
+package models
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Asset 任务正文中以 asset:<id> 引用的图片
+// RefCount 为引用它的任务数，降为 0 时记录 UnreferencedAt，超过宽限期且不被历史版本引用时由对象清理任务删除
+type Asset struct {
+	ID             int        `gorm:"primaryKey"                    json:"id"`
+	UserID         int        `gorm:"not null;index"                json:"user_id"`
+	ObjectKey      string     `gorm:"size:512;not null;uniqueIndex" json:"-"`
+	ContentType    string     `gorm:"size:64;not null"              json:"content_type"`
+	Size           int64      `gorm:"not null"                      json:"size"`
+	Width          int        `gorm:"not null"                      json:"width"`
+	Height         int        `gorm:"not null"                      json:"height"`
+	RefCount       int        `gorm:"not null;default:0"            json:"ref_count"`
+	UnreferencedAt *time.Time `gorm:"index"                         json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TaskAsset 任务与图片的引用关系
+type TaskAsset struct {
+	TaskID  int `gorm:"primaryKey;autoIncrement:false"`
+	AssetID int `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+// CreateAsset 新上传的图片尚未被引用，从上传时刻开始计算宽限期
+func CreateAsset(ctx context.Context, a Asset) (Asset, error) {
+	now := time.Now()
+	a.ID = 0
+	a.RefCount = 0
+	a.UnreferencedAt = &now
+	err := d.Db.WithContext(ctx).Create(&a).Error
+	return a, err
+}
+
+// GetAssetsByIDs 只返回属于该用户的图片
+func GetAssetsByIDs(ctx context.Context, uid int, ids []int) ([]Asset, error) {
+	var items []Asset
+	if len(ids) == 0 {
+		return items, nil
+	}
+	err := d.Db.WithContext(ctx).Where("user_id = ? AND id IN ?", uid, ids).Find(&items).Error
+	return items, err
+}
+
+// SyncTaskAssets 将任务的引用关系更新为 ids（忽略不属于该用户的图片），并重算受影响图片的引用数
+func SyncTaskAssets(ctx context.Context, uid, taskID int, ids []int) error {
+	return d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var owned []int
+		if len(ids) > 0 {
+			if err := tx.Model(&Asset{}).Where("user_id = ? AND id IN ?", uid, ids).
+				Pluck("id", &owned).Error; err != nil {
+				return err
+			}
+		}
+		var old []int
+		if err := tx.Model(&TaskAsset{}).Where("task_id = ?", taskID).Pluck("asset_id", &old).Error; err != nil {
+			return err
+		}
+		keep := make(map[int]bool, len(owned))
+		for _, id := range owned {
+			keep[id] = true
+		}
+		had := make(map[int]bool, len(old))
+		var removed, changed []int
+		for _, id := range old {
+			had[id] = true
+			if !keep[id] {
+				removed = append(removed, id)
+				changed = append(changed, id)
+			}
+		}
+		var added []TaskAsset
+		for _, id := range owned {
+			if !had[id] {
+				added = append(added, TaskAsset{TaskID: taskID, AssetID: id})
+				changed = append(changed, id)
+			}
+		}
+		if len(removed) > 0 {
+			if err := tx.Where("task_id = ? AND asset_id IN ?", taskID, removed).Delete(&TaskAsset{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			if err := tx.Create(&added).Error; err != nil {
+				return err
+			}
+		}
+		return recountAssets(tx, changed)
+	})
+}
+
+// releaseTaskAssets 删除任务时解除其图片引用，taskIDs 可以是子查询
+func releaseTaskAssets(tx *gorm.DB, taskIDs interface{}) error {
+	var ids []int
+	if err := tx.Model(&TaskAsset{}).Distinct("asset_id").Where("task_id IN (?)", taskIDs).
+		Pluck("asset_id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("task_id IN (?)", taskIDs).Delete(&TaskAsset{}).Error; err != nil {
+		return err
+	}
+	return recountAssets(tx, ids)
+}
+
+// recountAssets 按引用关系重算引用数，引用关系是唯一的事实来源
+func recountAssets(tx *gorm.DB, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Model(&Asset{}).Where("id IN ?", ids).
+		Update("ref_count", gorm.Expr("(SELECT COUNT(*) FROM task_assets WHERE task_assets.asset_id = assets.id)")).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&Asset{}).Where("id IN ? AND ref_count > 0", ids).
+		Update("unreferenced_at", nil).Error; err != nil {
+		return err
+	}
+	return tx.Model(&Asset{}).Where("id IN ? AND ref_count = 0 AND unreferenced_at IS NULL", ids).
+		Update("unreferenced_at", time.Now()).Error
+}
+
+// DeleteExpiredAssets 删除未被引用且超过宽限期的图片记录，返回其对象 key
+// 仍被历史版本正文引用的图片不删除，宽限期从本次清理重新计算，恢复版本后图片依然可用
+func DeleteExpiredAssets(ctx context.Context, before time.Time) ([]string, error) {
+	var keys []string
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var items []Asset
+		if err := tx.Select("id, user_id, object_key").
+			Where("ref_count = 0 AND unreferenced_at < ?", before).Find(&items).Error; err != nil {
+			return err
+		}
+		refs, err := revisionAssetRefs(tx, items)
+		if err != nil {
+			return err
+		}
+		expired, kept := splitExpiredAssets(items, refs)
+		if len(kept) > 0 {
+			if err := tx.Model(&Asset{}).Where("id IN ?", kept).
+				Update("unreferenced_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+		keys, err = deleteAssets(tx, expired)
+		return err
+	})
+	return keys, err
+}
+
+// assetRefMDRe 匹配 Markdown 正文中的 asset:<id>；宁可多保留，不要求出现在图片语法中
+var assetRefMDRe = regexp.MustCompile(`asset:(\d+)`)
+
+// revisionAssetRefs 返回 items 所属用户的历史版本正文中引用的图片，按用户分组
+func revisionAssetRefs(tx *gorm.DB, items []Asset) (map[int]map[int]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	uids := make([]int, 0, len(items))
+	seen := make(map[int]bool, len(items))
+	for _, a := range items {
+		if !seen[a.UserID] {
+			seen[a.UserID] = true
+			uids = append(uids, a.UserID)
+		}
+	}
+	refs := make(map[int]map[int]bool)
+	var revs []TaskRevision
+	err := tx.Model(&TaskRevision{}).Select("id, user_id, content_md").
+		Where("user_id IN ? AND content_md LIKE ?", uids, "%asset:%").
+		FindInBatches(&revs, 500, func(tx *gorm.DB, batch int) error {
+			for _, r := range revs {
+				addMarkdownAssetRefs(refs, r.UserID, r.ContentMD)
+			}
+			return nil
+		}).Error
+	return refs, err
+}
+
+func addMarkdownAssetRefs(refs map[int]map[int]bool, uid int, md string) {
+	for _, m := range assetRefMDRe.FindAllStringSubmatch(md, -1) {
+		id, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		if refs[uid] == nil {
+			refs[uid] = make(map[int]bool)
+		}
+		refs[uid][id] = true
+	}
+}
+
+// splitExpiredAssets 区分可以删除的图片与仍被同一用户的历史版本引用的图片
+func splitExpiredAssets(items []Asset, refs map[int]map[int]bool) (expired []Asset, kept []int) {
+	for _, a := range items {
+		if refs[a.UserID][a.ID] {
+			kept = append(kept, a.ID)
+		} else {
+			expired = append(expired, a)
+		}
+	}
+	return expired, kept
+}
+
+func deleteAssetsWhere(tx *gorm.DB, query string, args ...interface{}) ([]string, error) {
+	var items []Asset
+	if err := tx.Select("id, object_key").Where(query, args...).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return deleteAssets(tx, items)
+}
+
+func deleteAssets(tx *gorm.DB, items []Asset) ([]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	ids := make([]int, len(items))
+	keys := make([]string, len(items))
+	for i, a := range items {
+		ids[i], keys[i] = a.ID, a.ObjectKey
+	}
+	if err := tx.Where("asset_id IN ?", ids).Delete(&TaskAsset{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&Asset{}).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"ToDoList/server/utils"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddMarkdownAssetRefs(t *testing.T) {
+	refs := make(map[int]map[int]bool)
+	addMarkdownAssetRefs(refs, 1, "![a](asset:7) text [link](asset:12)\n<img src=\"asset:7\">")
+	addMarkdownAssetRefs(refs, 2, "no images, just asset: mentioned")
+	addMarkdownAssetRefs(refs, 2, "![](asset:3)")
+	want := map[int]map[int]bool{1: {7: true, 12: true}, 2: {3: true}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Fatalf("refs = %v, want %v", refs, want)
+	}
+}
+
+// 正文删掉图片后跑一次过了宽限期的清理，再恢复旧版本，图片仍应能解析为链接
+func TestExpiredAssetKeptForRevisionRestore(t *testing.T) {
+	const uid, other = 1, 2
+	revisions := []TaskRevision{
+		{UserID: uid, Rev: 1, ContentMD: "before\n\n![](asset:7)\n"},
+		{UserID: uid, Rev: 2, ContentMD: "before\n"},
+		{UserID: uid, Rev: 1, ContentMD: "![](asset:9)"},
+	}
+	// 当前正文已不引用任何图片，三张都已过宽限期
+	candidates := []Asset{
+		{ID: 7, UserID: uid, ObjectKey: "assets/1/7.png"},
+		{ID: 8, UserID: uid, ObjectKey: "assets/1/8.png"},
+		{ID: 9, UserID: other, ObjectKey: "assets/2/9.png"},
+	}
+
+	refs := make(map[int]map[int]bool)
+	for _, r := range revisions {
+		addMarkdownAssetRefs(refs, r.UserID, r.ContentMD)
+	}
+	expired, kept := splitExpiredAssets(candidates, refs)
+	if !reflect.DeepEqual(kept, []int{7}) {
+		t.Fatalf("kept = %v, want [7]", kept)
+	}
+	var gone []int
+	for _, a := range expired {
+		gone = append(gone, a.ID)
+	}
+	// 其他用户的图片即使 ID 出现在该用户的版本里也照常清理
+	if !reflect.DeepEqual(gone, []int{8, 9}) {
+		t.Fatalf("expired = %v, want [8 9]", gone)
+	}
+
+	remaining := make(map[int]Asset)
+	for _, a := range candidates {
+		remaining[a.ID] = a
+	}
+	for _, a := range expired {
+		delete(remaining, a.ID)
+	}
+	html, err := utils.RenderSafeHTML([]byte(revisions[0].ContentMD))
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	ids := utils.AssetIDs(html)
+	if !reflect.DeepEqual(ids, []int{7}) {
+		t.Fatalf("restored asset ids = %v, want [7]", ids)
+	}
+	resolved := utils.RewriteAssetURLs(html, func(id int) (string, bool) {
+		a, ok := remaining[id]
+		if !ok || a.UserID != uid {
+			return "", false
+		}
+		return "https://cdn.example/" + a.ObjectKey, true
+	})
+	if !strings.Contains(resolved, `src="https://cdn.example/assets/1/7.png"`) {
+		t.Fatalf("restored image not resolved: %s", resolved)
+	}
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAttachmentLimit = errors.New("任务附件数量已达上限")
+	ErrAttachmentQuota = errors.New("附件存储空间不足")
+)
+
+// Attachment 任务附件，文件本体存放在对象存储
+type Attachment struct {
+	ID          int       `gorm:"primaryKey"                  json:"id"`
+	UserID      int       `gorm:"not null;index"              json:"user_id"`
+	TaskID      int       `gorm:"not null;index"              json:"task_id"`
+	FileName    string    `gorm:"size:255;not null"           json:"file_name"`
+	ContentType string    `gorm:"size:128;not null"           json:"content_type"`
+	Size        int64     `gorm:"not null"                    json:"size"`
+	ObjectKey   string    `gorm:"size:512;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentLimits 附件数量与空间限制
+type AttachmentLimits struct {
+	MaxPerTask int64
+	UserQuota  int64
+}
+
+// AddAttachment 在事务内复核配额后写入；锁住用户行，避免并发上传突破配额
+func AddAttachment(ctx context.Context, a Attachment, lim AttachmentLimits) (Attachment, error) {
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var u User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&u, a.UserID).Error; err != nil {
+			return err
+		}
+		var n int64
+		if err := tx.Model(&Attachment{}).Where("task_id = ?", a.TaskID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n >= lim.MaxPerTask {
+			return ErrAttachmentLimit
+		}
+		used, err := attachmentUsage(tx, a.UserID)
+		if err != nil {
+			return err
+		}
+		if used+a.Size > lim.UserQuota {
+			return ErrAttachmentQuota
+		}
+		return tx.Create(&a).Error
+	})
+	return a, err
+}
+
+// AttachmentUsage 用户附件已用空间
+func AttachmentUsage(ctx context.Context, uid int) (int64, error) {
+	return attachmentUsage(d.Db.WithContext(ctx), uid)
+}
+
+func attachmentUsage(tx *gorm.DB, uid int) (int64, error) {
+	var used int64
+	err := tx.Model(&Attachment{}).Where("user_id = ?", uid).
+		Select("COALESCE(SUM(size), 0)").Scan(&used).Error
+	return used, err
+}
+
+func ListAttachments(ctx context.Context, uid, taskID int) ([]Attachment, error) {
+	var items []Attachment
+	err := d.Db.WithContext(ctx).Where("user_id = ? AND task_id = ?", uid, taskID).
+		Order("id ASC").Find(&items).Error
+	return items, err
+}
+
+func GetAttachment(ctx context.Context, uid, taskID, id int) (Attachment, error) {
+	var a Attachment
+	err := d.Db.WithContext(ctx).Where("id = ? AND user_id = ? AND task_id = ?", id, uid, taskID).First(&a).Error
+	return a, err
+}
+
+// DeleteAttachment 删除附件记录，返回被删除的记录
+func DeleteAttachment(ctx context.Context, uid, taskID, id int) (Attachment, error) {
+	var a Attachment
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ? AND task_id = ?", id, uid, taskID).First(&a).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&a).Error
+	})
+	return a, err
+}
+
+// AttachmentObjectInUse 对象 key 是否仍被附件引用
+func AttachmentObjectInUse(ctx context.Context, key string) (bool, error) {
+	var n int64
+	err := d.Db.WithContext(ctx).Model(&Attachment{}).Where("object_key = ?", key).Count(&n).Error
+	return n > 0, err
+}
+
+// restoreAttachment 以原ID恢复被删除的附件记录；恢复的是撤销窗口内刚删除的附件，不再复核数量与空间配额
+func restoreAttachment(tx *gorm.DB, uid int, st UndoStep) error {
+	s := st.Attachment
+	var t Task
+	if err := tx.Unscoped().Select("id").Where("id = ? AND user_id = ?", s.TaskID, uid).First(&t).Error; err != nil {
+		return err
+	}
+	a := Attachment{
+		ID:          st.ID,
+		UserID:      uid,
+		TaskID:      s.TaskID,
+		FileName:    s.FileName,
+		ContentType: s.ContentType,
+		Size:        s.Size,
+		ObjectKey:   s.ObjectKey,
+		CreatedAt:   s.CreatedAt,
+	}
+	if err := tx.Create(&a).Error; err != nil {
+		if isDuplicateKey(err) {
+			return ErrUndoStale
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteAttachmentsWhere 删除匹配的附件记录并返回其对象 key，供任务、项目、账户删除时在同一事务内调用
+func deleteAttachmentsWhere(tx *gorm.DB, query string, args ...interface{}) ([]string, error) {
+	var keys []string
+	if err := tx.Model(&Attachment{}).Where(query, args...).Pluck("object_key", &keys).Error; err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where(query, args...).Delete(&Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetTaskByIDAndUID 校验任务归属
+func GetTaskByIDAndUID(ctx context.Context, id, uid int) (Task, error) {
+	var t Task
+	err := d.Db.WithContext(ctx).Where("id = ? AND user_id = ?", id, uid).First(&t).Error
+	return t, err
+}
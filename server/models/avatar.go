@@ -0,0 +1,74 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AvatarVariants 头像各尺寸、各格式的对象 key：边长 -> 格式 -> key，以 JSON 文本存储
+type AvatarVariants map[int]map[string]string
+
+func (v AvatarVariants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (v *AvatarVariants) Scan(src any) error {
+	var b []byte
+	switch t := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		b = t
+	case string:
+		b = []byte(t)
+	default:
+		return fmt.Errorf("avatar variants: unsupported type %T", src)
+	}
+	if len(b) == 0 {
+		*v = nil
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+// AvatarObjectKeys 用户头像占用的全部对象 key（含旧版单文件头像），去重
+func (u User) AvatarObjectKeys() []string {
+	sizes := make([]int, 0, len(u.AvatarVariants))
+	for s := range u.AvatarVariants {
+		sizes = append(sizes, s)
+	}
+	sort.Ints(sizes)
+
+	seen := map[string]bool{}
+	var keys []string
+	for _, k := range append([]string{u.AvatarURL}, variantKeys(u.AvatarVariants, sizes)...) {
+		if k = strings.TrimSpace(k); k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func variantKeys(v AvatarVariants, sizes []int) []string {
+	out := make([]string, 0, len(sizes)*2)
+	for _, s := range sizes {
+		formats := make([]string, 0, len(v[s]))
+		for f := range v[s] {
+			formats = append(formats, f)
+		}
+		sort.Strings(formats)
+		for _, f := range formats {
+			out = append(out, v[s][f])
+		}
+	}
+	return out
+}
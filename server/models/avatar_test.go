@@ -0,0 +1,67 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAvatarVariantsScan(t *testing.T) {
+	cases := []struct {
+		name string
+		src  any
+		want AvatarVariants
+	}{
+		{"nil", nil, nil},
+		{"empty", []byte{}, nil},
+		{"string", `{"64":{"jpeg":"a/64.jpg"},"512":{"jpeg":"a/512.jpg"}}`,
+			AvatarVariants{64: {"jpeg": "a/64.jpg"}, 512: {"jpeg": "a/512.jpg"}}},
+		{"bytes", []byte(`{"64":{"jpeg":"a/64.jpg"}}`),
+			AvatarVariants{64: {"jpeg": "a/64.jpg"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var v AvatarVariants
+			if err := v.Scan(tc.src); err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if !reflect.DeepEqual(v, tc.want) {
+				t.Fatalf("Scan = %v, want %v", v, tc.want)
+			}
+		})
+	}
+	var v AvatarVariants
+	for _, bad := range []string{`{"64":1}`, `{"64":"a/64.jpg"}`} {
+		if err := v.Scan(bad); err == nil {
+			t.Fatalf("Scan accepted %s", bad)
+		}
+	}
+}
+
+func TestAvatarVariantsRoundTrip(t *testing.T) {
+	in := AvatarVariants{256: {"jpeg": "a/256.jpg"}, 64: {"jpeg": "a/64.jpg"}}
+	val, err := in.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out AvatarVariants
+	if err := out.Scan(val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip = %v, want %v", out, in)
+	}
+}
+
+func TestAvatarObjectKeys(t *testing.T) {
+	u := User{
+		AvatarURL: "a/512.jpg",
+		AvatarVariants: AvatarVariants{
+			512: {"jpeg": "a/512.jpg"},
+			64:  {"jpeg": "a/64.jpg"},
+		},
+	}
+	want := []string{"a/512.jpg", "a/64.jpg"}
+	if got := u.AvatarObjectKeys(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("AvatarObjectKeys = %v, want %v", got, want)
+	}
+}
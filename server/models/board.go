@@ -0,0 +1,106 @@
+package models
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListBoardTasks 项目下未归档的任务，按展示顺序排列，不含正文
+func ListBoardTasks(ctx context.Context, uid, pid int) ([]Task, error) {
+	var items []Task
+	err := d.Db.WithContext(ctx).Omit("content_md", "content_html").
+		Where("user_id = ? AND project_id = ? AND archived_at IS NULL", uid, pid).
+		Order("sort_order DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+// BoardMove 将任务移动到 Status 列中 PrevID 之后、NextID 之前；两者都为 0 时移动到列首
+type BoardMove struct {
+	TaskID int
+	Status string
+	PrevID int
+	NextID int
+}
+
+// MoveTask 在一个事务中修改任务的状态与排序键，返回修改前后的任务
+// 锁定项目行使同一项目的移动串行执行，相邻任务在锁内重新读取，不依赖客户端看到的排序键
+func MoveTask(ctx context.Context, uid, pid int, mv BoardMove) (Task, Task, error) {
+	var before, after Task
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, uid, pid); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ? AND project_id = ? AND archived_at IS NULL", mv.TaskID, uid, pid).
+			First(&before).Error; err != nil {
+			return err
+		}
+		statuses, err := listProjectStatuses(tx, uid, pid)
+		if err != nil {
+			return err
+		}
+		st, ok := FindStatus(statuses, mv.Status)
+		if !ok {
+			return ErrStatusNotFound
+		}
+		var column []sortSlot
+		if err := tx.Model(&Task{}).Select("id, sort_order").
+			Where("user_id = ? AND project_id = ? AND status = ? AND archived_at IS NULL AND id <> ?", uid, pid, st.Key, mv.TaskID).
+			Order("sort_order DESC, id DESC").Scan(&column).Error; err != nil {
+			return err
+		}
+		idx, err := boardIndex(column, mv.PrevID, mv.NextID)
+		if err != nil {
+			return err
+		}
+		key, changed := placeSlot(column, idx)
+		for _, s := range changed {
+			if err := tx.Model(&Task{}).Where("id = ?", s.ID).UpdateColumn("sort_order", s.SortOrder).Error; err != nil {
+				return err
+			}
+		}
+		update := StatusColumns(st)
+		update["sort_order"] = key
+		if err := tx.Model(&Task{}).Where("id = ?", mv.TaskID).Updates(update).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", mv.TaskID).First(&after).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, &before, after, RevisionMeta{Reason: RevisionUpdate})
+	})
+	if err != nil {
+		return Task{}, Task{}, err
+	}
+	return before, after, nil
+}
+
+// boardIndex 根据相邻任务确定插入位置；同时给出两者时必须仍然相邻
+func boardIndex(column []sortSlot, prevID, nextID int) (int, error) {
+	find := func(id int) int {
+		for i, s := range column {
+			if s.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+	idx := 0
+	if prevID != 0 {
+		i := find(prevID)
+		if i < 0 {
+			return 0, ErrOrderStale
+		}
+		idx = i + 1
+	}
+	if nextID != 0 {
+		j := find(nextID)
+		if j < 0 || (prevID != 0 && j != idx) {
+			return 0, ErrOrderStale
+		}
+		idx = j
+	}
+	return idx, nil
+}
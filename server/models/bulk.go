@@ -0,0 +1,159 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// 批量操作
+const (
+	BulkComplete    = "complete"
+	BulkReopen      = "reopen"
+	BulkMoveProject = "move_project"
+	BulkSetPriority = "set_priority"
+	BulkDelete      = "delete"
+	BulkTag         = "tag"
+)
+
+// BulkOp 一次批量操作，ProjectID 与 Priority 分别用于 move_project 与 set_priority，
+// AddTags 与 RemoveTags 用于 tag
+type BulkOp struct {
+	Op         string
+	ProjectID  int
+	Priority   int
+	AddTags    []string
+	RemoveTags []string
+}
+
+// BulkItem 单个任务的执行结果；Err 为该任务失败的原因，Changed 为 false 表示任务已处于目标状态
+// 删除时只填充 Before
+type BulkItem struct {
+	ID      int
+	Err     error
+	Changed bool
+	Before  Task
+	After   Task
+}
+
+// bulkItemError 只影响单个任务的错误，其余错误使整个批量操作回滚
+func bulkItemError(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrTaskExists) || errors.Is(err, ErrStatusNotFound) ||
+		errors.Is(err, ErrTooManyTags)
+}
+
+// BulkUpdateTasks 在一个事务中对多个任务执行同一操作，每个任务使用独立的保存点，单个任务失败时只回滚该任务
+// ids 需已去重并按升序排列，使并发的批量操作按相同顺序加锁
+func BulkUpdateTasks(ctx context.Context, uid int, ids []int, op BulkOp) ([]BulkItem, error) {
+	items := make([]BulkItem, len(ids))
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定目标项目，避免移动过程中目标项目的状态被修改
+		if op.Op == BulkMoveProject {
+			if err := lockProject(tx, uid, op.ProjectID); err != nil {
+				return err
+			}
+		}
+		statuses := map[int][]ProjectStatus{}
+		projectStatuses := func(pid int) ([]ProjectStatus, error) {
+			if s, ok := statuses[pid]; ok {
+				return s, nil
+			}
+			s, err := listProjectStatuses(tx, uid, pid)
+			if err == nil {
+				statuses[pid] = s
+			}
+			return s, err
+		}
+		for i, id := range ids {
+			item := BulkItem{ID: id}
+			err := tx.Transaction(func(itx *gorm.DB) error {
+				return bulkApply(itx, uid, op, &item, projectStatuses)
+			})
+			if err != nil {
+				if !bulkItemError(err) {
+					return err
+				}
+				item = BulkItem{ID: id, Err: err}
+			}
+			items[i] = item
+		}
+		return nil
+	})
+	return items, err
+}
+
+func bulkApply(tx *gorm.DB, uid int, op BulkOp, item *BulkItem, projectStatuses func(int) ([]ProjectStatus, error)) error {
+	var cur Task
+	if err := tx.Select("id, project_id, status, priority, tags, completed_at").
+		Where("id = ? AND user_id = ?", item.ID, uid).First(&cur).Error; err != nil {
+		return err
+	}
+	item.Before = cur
+	update := map[string]interface{}{}
+	switch op.Op {
+	case BulkDelete:
+		if _, err := softDeleteTask(tx, item.ID, uid); err != nil {
+			return err
+		}
+		item.Changed = true
+		return nil
+	case BulkComplete, BulkReopen:
+		category := StatusOpen
+		if op.Op == BulkComplete {
+			category = StatusClosed
+		}
+		if (cur.CompletedAt != nil) == (category == StatusClosed) {
+			return nil
+		}
+		statuses, err := projectStatuses(cur.ProjectID)
+		if err != nil {
+			return err
+		}
+		st, ok := FirstStatus(statuses, category)
+		if !ok {
+			return ErrStatusNotFound
+		}
+		update = StatusColumns(st)
+	case BulkMoveProject:
+		if cur.ProjectID == op.ProjectID {
+			return nil
+		}
+		statuses, err := projectStatuses(op.ProjectID)
+		if err != nil {
+			return err
+		}
+		// 目标项目有同名状态时沿用，否则取同分类的第一个状态
+		st, ok := FindStatus(statuses, cur.Status)
+		if !ok {
+			category := StatusOpen
+			if cur.CompletedAt != nil {
+				category = StatusClosed
+			}
+			if st, ok = FirstStatus(statuses, category); !ok {
+				return ErrStatusNotFound
+			}
+		}
+		update = StatusColumns(st)
+		update["project_id"] = op.ProjectID
+	case BulkSetPriority:
+		if cur.Priority == op.Priority {
+			return nil
+		}
+		update["priority"] = op.Priority
+	case BulkTag:
+		tags, changed, err := cur.Tags.Apply(op.AddTags, op.RemoveTags)
+		if err != nil || !changed {
+			return err
+		}
+		update["tags"] = tags
+	default:
+		return errors.New("unknown bulk op: " + op.Op)
+	}
+	before, after, affected, err := updateTaskWithRevision(tx, update, item.ID, uid, RevisionMeta{Reason: RevisionUpdate})
+	if err != nil {
+		return err
+	}
+	item.Before, item.After, item.Changed = before, after, affected > 0
+	return nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+var ErrIdentityExists = errors.New("外部账户已绑定")
+
+// UserIdentity 外部身份提供方账户与本地用户的绑定关系
+type UserIdentity struct {
+	ID        int       `gorm:"primaryKey"                                                json:"id"`
+	UserID    int       `gorm:"not null;index"                                            json:"user_id"`
+	Provider  string    `gorm:"size:64;not null;uniqueIndex:ux_provider_subject,priority:1" json:"provider"`
+	Subject   string    `gorm:"size:255;not null;uniqueIndex:ux_provider_subject,priority:2" json:"subject"`
+	Email     string    `gorm:"size:255"                                                  json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func GetIdentity(ctx context.Context, provider, subject string) (UserIdentity, error) {
+	var ident UserIdentity
+	err := d.Db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&ident).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return UserIdentity{}, nil
+	}
+	return ident, err
+}
+
+func AddIdentity(ctx context.Context, ident UserIdentity) (UserIdentity, error) {
+	if err := d.Db.WithContext(ctx).Create(&ident).Error; err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) && me.Number == 1062 {
+			return UserIdentity{}, ErrIdentityExists
+		}
+		return UserIdentity{}, err
+	}
+	return ident, nil
+}
+
+// AddUserWithIdentity 通过外部身份首次登录时同时创建用户与绑定
+func AddUserWithIdentity(ctx context.Context, user User, ident UserIdentity) (User, error) {
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		ident.UserID = user.ID
+		return tx.Create(&ident).Error
+	})
+	if err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) && me.Number == 1062 {
+			return User{}, ErrUserExists
+		}
+		return User{}, err
+	}
+	return user, nil
+}
+
+func ListIdentities(ctx context.Context, uid int) ([]UserIdentity, error) {
+	var items []UserIdentity
+	err := d.Db.WithContext(ctx).Where("user_id = ?", uid).Find(&items).Error
+	return items, err
+}
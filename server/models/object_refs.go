@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ObjectKeyRefs 收集数据库中引用的全部对象 key（头像各尺寸、任务附件与正文图片）
+// 在 assetCutoff 之前就已无人引用、且不被历史版本引用的图片视为待清理，不计入
+func ObjectKeyRefs(ctx context.Context, assetCutoff time.Time) (map[string]struct{}, error) {
+	refs := make(map[string]struct{})
+	var users []User
+	err := d.Db.WithContext(ctx).Model(&User{}).
+		Select("id, avatar_url, avatar_variants").
+		Where("avatar_url <> '' OR avatar_variants IS NOT NULL").
+		FindInBatches(&users, 500, func(tx *gorm.DB, batch int) error {
+			for _, u := range users {
+				for _, k := range u.AvatarObjectKeys() {
+					refs[normalizeObjectKey(k)] = struct{}{}
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	var atts []Attachment
+	err = d.Db.WithContext(ctx).Model(&Attachment{}).Select("id, object_key").
+		FindInBatches(&atts, 1000, func(tx *gorm.DB, batch int) error {
+			for _, a := range atts {
+				refs[a.ObjectKey] = struct{}{}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	var assets []Asset
+	err = d.Db.WithContext(ctx).Model(&Asset{}).Select("id, object_key").
+		Where("NOT (ref_count = 0 AND unreferenced_at IS NOT NULL AND unreferenced_at < ?)", assetCutoff).
+		FindInBatches(&assets, 1000, func(tx *gorm.DB, batch int) error {
+			for _, a := range assets {
+				refs[a.ObjectKey] = struct{}{}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	// 已过宽限期但仍被历史版本引用的图片会被保留（试运行时尚未顺延宽限期）
+	var expired []Asset
+	if err := d.Db.WithContext(ctx).Select("id, user_id, object_key").
+		Where("ref_count = 0 AND unreferenced_at < ?", assetCutoff).Find(&expired).Error; err != nil {
+		return nil, err
+	}
+	revRefs, err := revisionAssetRefs(d.Db.WithContext(ctx), expired)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range expired {
+		if revRefs[a.UserID][a.ID] {
+			refs[a.ObjectKey] = struct{}{}
+		}
+	}
+	return refs, nil
+}
+
+// normalizeObjectKey 早期头像字段可能保存完整 URL，取路径部分作为 key
+func normalizeObjectKey(k string) string {
+	k = strings.TrimSpace(k)
+	if strings.Contains(k, "://") {
+		if u, err := url.Parse(k); err == nil {
+			return strings.TrimPrefix(u.Path, "/")
+		}
+	}
+	return strings.TrimPrefix(k, "/")
+}
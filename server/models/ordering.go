@@ -0,0 +1,280 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOrderStale 参照记录已不在列表中或已不相邻，客户端需刷新后重试
+var ErrOrderStale = errors.New("排序已变化")
+
+// sortGap 新排序键与相邻记录之间的默认间隔，重新分配时也以此为最小间隔
+const sortGap int64 = 1 << 20
+
+// sortSlot 参与排序计算的记录，按 sort_order 倒序展示
+type sortSlot struct {
+	ID        int
+	SortOrder int64
+}
+
+// keyBetween 计算展示在 prev 之后、next 之前的排序键；prev、next 为 nil 表示位于开头或末尾
+// 没有可用的整数间隔时返回 false，由调用方重新分配
+func keyBetween(prev, next *int64) (int64, bool) {
+	switch {
+	case prev == nil && next == nil:
+		return sortGap, true
+	case prev == nil:
+		if *next > math.MaxInt64-sortGap {
+			return 0, false
+		}
+		return *next + sortGap, true
+	case next == nil:
+		if *prev > sortGap {
+			return *prev - sortGap, true
+		}
+		if *prev >= 2 {
+			return *prev / 2, true
+		}
+		return 0, false
+	default:
+		if *prev-*next < 2 {
+			return 0, false
+		}
+		return *next + (*prev-*next)/2, true
+	}
+}
+
+// spreadKeys 为 n 条按展示顺序排列的记录分配等间隔的键，尽量保持在原有键的范围 [lo, hi] 内
+func spreadKeys(n int, lo, hi int64) []int64 {
+	slots := int64(n) + 1
+	if (hi-lo)/slots < 2 {
+		if lo > math.MaxInt64-slots*sortGap {
+			lo = 0
+		}
+		hi = lo + slots*sortGap
+	}
+	step := (hi - lo) / slots
+	keys := make([]int64, n)
+	for i := range keys {
+		keys[i] = hi - step*int64(i)
+	}
+	return keys
+}
+
+// placeSlot 在 slots 中 idx 处为记录计算排序键，间隔不足时重新分配整组的键
+// 返回记录的新键以及需要改写的其他记录
+func placeSlot(slots []sortSlot, idx int) (int64, []sortSlot) {
+	var prev, next *int64
+	if idx > 0 {
+		prev = &slots[idx-1].SortOrder
+	}
+	if idx < len(slots) {
+		next = &slots[idx].SortOrder
+	}
+	if key, ok := keyBetween(prev, next); ok {
+		return key, nil
+	}
+	lo, hi := int64(math.MaxInt64), int64(0)
+	for _, s := range slots {
+		lo = min(lo, s.SortOrder)
+		hi = max(hi, s.SortOrder)
+	}
+	keys := spreadKeys(len(slots)+1, lo, hi)
+	var changed []sortSlot
+	for i, s := range slots {
+		k := keys[i]
+		if i >= idx {
+			k = keys[i+1]
+		}
+		if k != s.SortOrder {
+			changed = append(changed, sortSlot{ID: s.ID, SortOrder: k})
+		}
+	}
+	return keys[idx], changed
+}
+
+// sortList 一个按 sort_order 倒序、id 倒序展示的列表，where 限定列表范围
+type sortList struct {
+	model interface{}
+	where string
+	args  []interface{}
+}
+
+func (l sortList) query(tx *gorm.DB) *gorm.DB {
+	return tx.Model(l.model).Where(l.where, l.args...)
+}
+
+// nextKey 列表中新记录排在最前时使用的键；键已接近上限时与当前最大键相同，由 id 决定先后
+// 取最大键时包含已删除的记录，使查询只需读取索引
+func (l sortList) nextKey(tx *gorm.DB) (int64, error) {
+	var top int64
+	if err := l.query(tx.Unscoped()).Select("COALESCE(MAX(sort_order), 0)").Scan(&top).Error; err != nil {
+		return 0, err
+	}
+	if key, ok := keyBetween(nil, &top); ok {
+		return key, nil
+	}
+	return top, nil
+}
+
+// neighbor 取展示在 anchor 紧邻上方（above 为 true）或下方的记录，跳过 skipID；
+// 条件与排序都落在 sort_order 上，可以使用 (user_id, project_id, sort_order) 之类的索引
+func (l sortList) neighbor(tx *gorm.DB, anchor sortSlot, above bool, skipID int) (*sortSlot, error) {
+	q := l.query(tx).Select("id, sort_order").Where("id <> ?", skipID)
+	if above {
+		q = q.Where("sort_order > ? OR (sort_order = ? AND id > ?)", anchor.SortOrder, anchor.SortOrder, anchor.ID).
+			Order("sort_order ASC, id ASC")
+	} else {
+		q = q.Where("sort_order < ? OR (sort_order = ? AND id < ?)", anchor.SortOrder, anchor.SortOrder, anchor.ID).
+			Order("sort_order DESC, id DESC")
+	}
+	var rows []sortSlot
+	if err := q.Limit(1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// place 计算记录 id 移动到 afterID 下方、beforeID 上方时的新键，两者至少给出一个；
+// 同时给出时两者必须相邻。相邻键之间没有整数间隔时重新分配整个列表的键
+// 调用方需在事务中并已锁定列表，保证读取到的相邻记录不会被并发修改
+func (l sortList) place(tx *gorm.DB, id, afterID, beforeID int) (int64, error) {
+	anchorID := afterID
+	if anchorID == 0 {
+		anchorID = beforeID
+	}
+	var anchors []sortSlot
+	if err := l.query(tx).Select("id, sort_order").Where("id = ? AND id <> ?", anchorID, id).
+		Limit(1).Scan(&anchors).Error; err != nil {
+		return 0, err
+	}
+	if len(anchors) == 0 {
+		return 0, ErrOrderStale
+	}
+	anchor := anchors[0]
+	prev, next := &anchor, (*sortSlot)(nil)
+	var err error
+	if afterID != 0 {
+		if next, err = l.neighbor(tx, anchor, false, id); err != nil {
+			return 0, err
+		}
+		if beforeID != 0 && (next == nil || next.ID != beforeID) {
+			return 0, ErrOrderStale
+		}
+	} else {
+		next = &anchor
+		if prev, err = l.neighbor(tx, anchor, true, id); err != nil {
+			return 0, err
+		}
+	}
+	var pk, nk *int64
+	if prev != nil {
+		pk = &prev.SortOrder
+	}
+	if next != nil {
+		nk = &next.SortOrder
+	}
+	if key, ok := keyBetween(pk, nk); ok {
+		return key, nil
+	}
+
+	var slots []sortSlot
+	if err := l.query(tx).Select("id, sort_order").Where("id <> ?", id).
+		Order("sort_order DESC, id DESC").Scan(&slots).Error; err != nil {
+		return 0, err
+	}
+	idx := -1
+	for i, s := range slots {
+		if s.ID == anchor.ID {
+			idx = i
+		}
+	}
+	if afterID != 0 {
+		idx++
+	}
+	key, changed := placeSlot(slots, idx)
+	for _, s := range changed {
+		if err := tx.Model(l.model).Where("id = ?", s.ID).UpdateColumn("sort_order", s.SortOrder).Error; err != nil {
+			return 0, err
+		}
+	}
+	return key, nil
+}
+
+// taskSortList 项目任务列表，与任务列表接口的展示范围一致
+func taskSortList(uid, pid int) sortList {
+	return sortList{model: &Task{}, where: "user_id = ? AND project_id = ? AND archived_at IS NULL", args: []interface{}{uid, pid}}
+}
+
+// newTaskSortList 计算新任务排序键时的范围，条件与 idx_user_proj_sort 的前缀一致
+func newTaskSortList(uid, pid int) sortList {
+	return sortList{model: &Task{}, where: "user_id = ? AND project_id = ?", args: []interface{}{uid, pid}}
+}
+
+func projectSortList(uid int) sortList {
+	return sortList{model: &Project{}, where: "user_id = ?", args: []interface{}{uid}}
+}
+
+// ReorderTask 将任务移动到同项目中 afterID 下方、beforeID 上方，只修改排序键；返回修改前后的任务
+func ReorderTask(ctx context.Context, uid, id, afterID, beforeID int) (Task, Task, error) {
+	var before, after Task
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var t Task
+		if err := tx.Select("id, project_id").Where("id = ? AND user_id = ?", id, uid).First(&t).Error; err != nil {
+			return err
+		}
+		if err := lockProject(tx, uid, t.ProjectID); err != nil {
+			return err
+		}
+		// 加锁后按原项目重新读取，期间被移到其他项目时视为不存在
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ? AND project_id = ?", id, uid, t.ProjectID).First(&before).Error; err != nil {
+			return err
+		}
+		key, err := taskSortList(uid, before.ProjectID).place(tx, id, afterID, beforeID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Task{}).Where("id = ?", id).Updates(map[string]interface{}{"sort_order": key}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).First(&after).Error
+	})
+	if err != nil {
+		return Task{}, Task{}, err
+	}
+	return before, after, nil
+}
+
+// ReorderProject 将项目移动到 afterID 下方、beforeID 上方；锁定用户行串行化同一用户的项目排序
+func ReorderProject(ctx context.Context, uid, id, afterID, beforeID int) (Project, Project, error) {
+	var before, after Project
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var u User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&u, uid).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND user_id = ?", id, uid).First(&before).Error; err != nil {
+			return err
+		}
+		key, err := projectSortList(uid).place(tx, id, afterID, beforeID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Project{}).Where("id = ?", id).Updates(map[string]interface{}{"sort_order": key}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).First(&after).Error
+	})
+	if err != nil {
+		return Project{}, Project{}, err
+	}
+	return before, after, nil
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func ptr(v int64) *int64 { return &v }
+
+func TestKeyBetween(t *testing.T) {
+	cases := []struct {
+		name       string
+		prev, next *int64
+		want       int64
+		ok         bool
+	}{
+		{"empty list", nil, nil, sortGap, true},
+		{"head", nil, ptr(10), 10 + sortGap, true},
+		{"head at limit", nil, ptr(math.MaxInt64 - sortGap), math.MaxInt64, true},
+		{"head past limit", nil, ptr(math.MaxInt64 - sortGap + 1), 0, false},
+		{"head at max", nil, ptr(math.MaxInt64), 0, false},
+		{"tail", ptr(3 * sortGap), nil, 2 * sortGap, true},
+		{"tail halves below gap", ptr(sortGap), nil, sortGap / 2, true},
+		{"tail at 2", ptr(2), nil, 1, true},
+		{"tail at 1", ptr(1), nil, 0, false},
+		{"tail at 0", ptr(0), nil, 0, false},
+		{"middle", ptr(10), ptr(4), 7, true},
+		{"one between", ptr(6), ptr(4), 5, true},
+		{"adjacent", ptr(5), ptr(4), 0, false},
+		{"equal", ptr(5), ptr(5), 0, false},
+		{"near max", ptr(math.MaxInt64), ptr(math.MaxInt64 - 2), math.MaxInt64 - 1, true},
+		{"adjacent at max", ptr(math.MaxInt64), ptr(math.MaxInt64 - 1), 0, false},
+		{"full range", ptr(math.MaxInt64), ptr(0), math.MaxInt64 / 2, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := keyBetween(tc.prev, tc.next)
+			if ok != tc.ok || (ok && got != tc.want) {
+				t.Fatalf("keyBetween = (%d, %v), want (%d, %v)", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestSpreadKeys(t *testing.T) {
+	cases := []struct {
+		name   string
+		n      int
+		lo, hi int64
+		first  int64
+	}{
+		{"keeps range", 3, 0, 4 * sortGap, 4 * sortGap},
+		{"single", 1, 100, 100 + 2*sortGap, 100 + 2*sortGap},
+		{"tight range grows", 3, 10, 12, 10 + 4*sortGap},
+		{"collapsed range", 4, 7, 7, 7 + 5*sortGap},
+		{"near max restarts at zero", 3, math.MaxInt64 - 3, math.MaxInt64, 4 * sortGap},
+		{"wide range near max", 2, math.MaxInt64 - 9*sortGap, math.MaxInt64, math.MaxInt64},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys := spreadKeys(tc.n, tc.lo, tc.hi)
+			if len(keys) != tc.n {
+				t.Fatalf("len = %d, want %d", len(keys), tc.n)
+			}
+			if keys[0] != tc.first {
+				t.Fatalf("keys[0] = %d, want %d", keys[0], tc.first)
+			}
+			for i, k := range keys {
+				if k <= 0 {
+					t.Fatalf("keys[%d] = %d, want > 0", i, k)
+				}
+				if i > 0 && keys[i-1]-k < 2 {
+					t.Fatalf("keys %v leave no gap at %d", keys, i)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceSlot(t *testing.T) {
+	const top = math.MaxInt64
+	cases := []struct {
+		name      string
+		keys      []int64
+		idx       int
+		rebalance bool
+	}{
+		{"empty", nil, 0, false},
+		{"head with room", []int64{30, 20, 10}, 0, false},
+		{"tail with room", []int64{30, 20, 10}, 3, false},
+		{"middle with room", []int64{30, 20, 10}, 1, false},
+		{"head at max", []int64{top, top - 1, top - 2}, 0, true},
+		{"tail at 1", []int64{3, 2, 1}, 3, true},
+		{"tail at 0", []int64{2, 1, 0}, 3, true},
+		{"adjacent middle", []int64{9, 5, 4, 1}, 2, true},
+		{"adjacent near max", []int64{top, top - 1}, 1, true},
+		{"equal keys", []int64{7, 7, 7}, 1, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			slots := make([]sortSlot, len(tc.keys))
+			for i, k := range tc.keys {
+				slots[i] = sortSlot{ID: i + 1, SortOrder: k}
+			}
+			key, changed := placeSlot(slots, tc.idx)
+			if rebalanced := changed != nil; rebalanced != tc.rebalance {
+				t.Fatalf("changed = %v, want rebalance %v", changed, tc.rebalance)
+			}
+
+			// 应用改写后，插入的记录必须恰好位于 idx 处，且所有键严格递减
+			final := make(map[int]int64, len(slots))
+			for _, s := range slots {
+				final[s.ID] = s.SortOrder
+			}
+			for _, s := range changed {
+				if _, ok := final[s.ID]; !ok {
+					t.Fatalf("changed unknown id %d", s.ID)
+				}
+				final[s.ID] = s.SortOrder
+			}
+			order := make([]int64, 0, len(slots)+1)
+			for i, s := range slots {
+				if i == tc.idx {
+					order = append(order, key)
+				}
+				order = append(order, final[s.ID])
+			}
+			if tc.idx == len(slots) {
+				order = append(order, key)
+			}
+			for i := 1; i < len(order); i++ {
+				if order[i-1] <= order[i] {
+					t.Fatalf("keys %v not strictly descending (new key %d at %d)", order, key, tc.idx)
+				}
+			}
+		})
+	}
+}
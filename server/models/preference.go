@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrProjectNotFound = errors.New("项目不存在")
+
+// UserPreferences 用户偏好，时区沿用 User.Timezone
+type UserPreferences struct {
+	Locale             string `gorm:"size:16;not null;default:zh-CN"   json:"locale"`
+	WeekStart          int    `gorm:"type:tinyint;not null;default:1"  json:"week_start"` // 0 周日 1 周一
+	DefaultPriority    int    `gorm:"type:tinyint;not null;default:3"  json:"default_priority"`
+	DefaultProjectID   *int   `json:"default_project_id"`
+	ReminderEnabled    bool   `gorm:"not null;default:true"            json:"reminder_enabled"`
+	ReminderMinutes    int    `gorm:"not null;default:0"               json:"reminder_minutes"`      // 截止前多少分钟提醒
+	AllDayReminderTime string `gorm:"size:5;not null;default:'09:00'"  json:"all_day_reminder_time"` // 仅有日期的任务当天提醒时间
+}
+
+// UpdatePreferences 更新偏好，默认项目需属于该用户
+func UpdatePreferences(ctx context.Context, uid int, update map[string]interface{}) (User, error) {
+	var user User
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if pid, ok := update["default_project_id"].(int); ok {
+			var n int64
+			if err := tx.Model(&Project{}).Where("id = ? AND user_id = ?", pid, uid).Count(&n).Error; err != nil {
+				return err
+			}
+			if n == 0 {
+				return ErrProjectNotFound
+			}
+		}
+		if err := tx.Model(&User{}).Where("id = ?", uid).Updates(update).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", uid).First(&user).Error
+	})
+	return user, err
+}
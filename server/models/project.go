@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strings"
 	"time"
 )
@@ -13,24 +14,40 @@ var ErrProjectExists = errors.New("项目已存在")
 
 type Project struct {
 	ID        int            `gorm:"primaryKey"                               json:"id"`
-	UserID    int            `gorm:"index;not null;uniqueIndex:ux_user_name,priority:1" json:"user_id"`
+	UserID    int            `gorm:"index;not null;uniqueIndex:ux_user_name,priority:1;index:idx_projects_user_sort,priority:1" json:"user_id"`
 	Name      string         `gorm:"size:128;not null;uniqueIndex:ux_user_name,priority:2" json:"name"`
 	Color string `gorm:"size:16;not null;default:'#9b6d6d'" json:"color"`
-	SortOrder int64          `gorm:"not null;default:0"                        json:"sort_order"`
+	SortOrder int64          `gorm:"not null;default:0;index:idx_projects_user_sort,priority:2" json:"sort_order"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	// DelFlag 未删除为 0，删除后为自身 ID，使已删除的项目不占用名称
+	DelFlag   int            `gorm:"not null;default:0;uniqueIndex:ux_user_name,priority:3" json:"-"`
 }
 
+// BeforeCreate 新项目排在最前
 func (t *Project) BeforeCreate(tx *gorm.DB) error {
-	if t.SortOrder == 0 {
-		t.SortOrder = time.Now().UnixNano()
+	if t.SortOrder != 0 {
+		return nil
+	}
+	key, err := projectSortList(t.UserID).nextKey(tx.Session(&gorm.Session{NewDB: true}))
+	if err != nil {
+		return err
 	}
+	t.SortOrder = key
 	return nil
 }
 
+// AddProject 创建项目及其默认状态
 func AddProject(ctx context.Context, project Project) (Project, error) {
-
-	if err := d.Db.WithContext(ctx).Create(&project).Error; err != nil {
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		statuses := defaultStatuses(project.UserID, project.ID)
+		return tx.Create(&statuses).Error
+	})
+	if err != nil {
 		var me *mysql.MySQLError
 		if errors.As(err, &me) && me.Number == 1062 {
 			return Project{}, ErrProjectExists
@@ -59,48 +76,70 @@ func ProjectList(ctx context.Context, userID int, page, size int) ([]Project, in
 	return items, total, err
 }
 
-func DeleteProjectAndTasks(ctx context.Context, projectID, userID int) (projAffected int64, taskAffected int64, err error) {
+// DeleteProjectAndTasks 将项目及其任务以同一删除时间移入回收站，恢复项目时据此一并恢复任务
+// taskIDs 为随项目删除的任务，用于清理详情缓存
+func DeleteProjectAndTasks(ctx context.Context, projectID, userID int) (projAffected int64, taskIDs []int, err error) {
 	err = d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-
-		resTask := tx.Where("user_id = ? AND project_id = ?", userID, projectID).Delete(&Task{})
-		if resTask.Error != nil {
-			return resTask.Error
-		}
-		taskAffected = resTask.RowsAffected
-
-		resProj := tx.Where("id = ? AND user_id = ?", projectID, userID).Delete(&Project{})
-		if resProj.Error != nil {
-			return resProj.Error
-		}
-		if resProj.RowsAffected == 0 {
-			return gorm.ErrRecordNotFound
-		}
-		projAffected = resProj.RowsAffected
-		return nil
+		projAffected, taskIDs, err = deleteProject(tx, projectID, userID, time.Now())
+		return err
 	})
 	return
 }
 
+func deleteProject(tx *gorm.DB, projectID, userID int, now time.Time) (int64, []int, error) {
+	resProj := tx.Model(&Project{}).Where("id = ? AND user_id = ?", projectID, userID).
+		UpdateColumns(softDeleteColumns(now))
+	if resProj.Error != nil {
+		return 0, nil, resProj.Error
+	}
+	if resProj.RowsAffected == 0 {
+		return 0, nil, gorm.ErrRecordNotFound
+	}
+	var taskIDs []int
+	if err := tx.Model(&Task{}).Where("user_id = ? AND project_id = ?", userID, projectID).
+		Pluck("id", &taskIDs).Error; err != nil {
+		return 0, nil, err
+	}
+	if len(taskIDs) > 0 {
+		if err := tx.Model(&Task{}).Where("id IN ?", taskIDs).
+			UpdateColumns(softDeleteColumns(now)).Error; err != nil {
+			return 0, nil, err
+		}
+	}
+	err := tx.Model(&User{}).
+		Where("id = ? AND default_project_id = ?", userID, projectID).
+		Update("default_project_id", nil).Error
+	return resProj.RowsAffected, taskIDs, err
+}
+
 func GetProjectInfoByIDAndUserID(ctx context.Context, id int, userid int) (Project, error) {
 	var project Project
 	err := d.Db.WithContext(ctx).Where("id = ? And user_id = ?", id, userid).First(&project).Error
 	return project, err
 }
 
-func UpdateProjectByIDAndUserID(ctx context.Context, update map[string]interface{}, id int, userid int) (Project, int64, error) {
-	var project Project
-	res := d.Db.WithContext(ctx).Model(&Project{}).Where("id = ? And user_id = ?", id, userid).Updates(update)
-	if err := res.Error; err != nil {
-		var me *mysql.MySQLError
-		if errors.As(err, &me) && me.Number == 1062 {
-			return Project{}, 0, ErrProjectExists
+// UpdateProjectByIDAndUserID 锁定项目行后修改，同时返回修改前的项目
+func UpdateProjectByIDAndUserID(ctx context.Context, update map[string]interface{}, id int, userid int) (before Project, after Project, affected int64, err error) {
+	err = d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&before, "id = ? And user_id = ?", id, userid).Error; err != nil {
+			return err
 		}
-		return Project{}, 0, err
-	}
-	if err := d.Db.WithContext(ctx).First(&project, "id = ? And user_id = ?", id, userid).Error; err != nil {
-		return project, 0, err
+		res := tx.Model(&Project{}).Where("id = ? And user_id = ?", id, userid).Updates(update)
+		if err := res.Error; err != nil {
+			var me *mysql.MySQLError
+			if errors.As(err, &me) && me.Number == 1062 {
+				return ErrProjectExists
+			}
+			return err
+		}
+		affected = res.RowsAffected
+		return tx.First(&after, "id = ? And user_id = ?", id, userid).Error
+	})
+	if err != nil {
+		return Project{}, Project{}, 0, err
 	}
-	return project, res.RowsAffected, nil
+	return before, after, affected, nil
 }
 
 func GetProjectListByUserIDAndName(ctx context.Context, UserID int, name string, page, size int) ([]Project, int64, error) {
@@ -123,3 +162,10 @@ func GetProjectListByUserIDAndName(ctx context.Context, UserID int, name string,
 
 	return items, total, err
 }
+
+// ListAllProjects 用户全部项目，用于数据导出
+func ListAllProjects(ctx context.Context, uid int) ([]Project, error) {
+	var items []Project
+	err := d.Db.WithContext(ctx).Where("user_id = ?", uid).Order("id ASC").Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,463 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 状态分类：进入 closed 分类的状态视为已完成
+const (
+	StatusOpen   = "open"
+	StatusClosed = "closed"
+)
+
+var (
+	ErrStatusExists   = errors.New("状态已存在")
+	ErrStatusNotFound = errors.New("状态不存在")
+	// ErrStatusLastOfCategory 每个项目至少保留一个 open 与一个 closed 状态
+	ErrStatusLastOfCategory = errors.New("至少保留一个未完成与一个已完成状态")
+	// ErrStatusInUse 状态下仍有任务且未指定迁移目标
+	ErrStatusInUse = errors.New("状态下仍有任务")
+)
+
+// ProjectStatus 项目的任务状态，任务的 status 字段保存 Key
+// Key 创建后不可修改，名称、分类与顺序可以调整
+type ProjectStatus struct {
+	ID        int       `gorm:"primaryKey"                                                    json:"id"`
+	ProjectID int       `gorm:"not null;uniqueIndex:ux_project_status_key,priority:1"         json:"project_id"`
+	UserID    int       `gorm:"not null;index"                                                json:"-"`
+	Key       string    `gorm:"column:status_key;size:32;not null;uniqueIndex:ux_project_status_key,priority:2" json:"key"`
+	Name      string    `gorm:"size:64;not null"                                              json:"name"`
+	Category  string    `gorm:"size:16;not null;default:'open'"                               json:"category"`
+	Position  int       `gorm:"not null;default:0"                                            json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// defaultStatuses 新建项目以及迁移已有项目时使用的状态，与原先的 todo/done 对应
+func defaultStatuses(uid, pid int) []ProjectStatus {
+	return []ProjectStatus{
+		{ProjectID: pid, UserID: uid, Key: TaskTodo, Name: "待办", Category: StatusOpen, Position: 0},
+		{ProjectID: pid, UserID: uid, Key: TaskDone, Name: "已完成", Category: StatusClosed, Position: 1},
+	}
+}
+
+// SeedProjectStatuses 为引入自定义状态之前创建的项目补齐默认状态，回收站中的项目同样处理
+func SeedProjectStatuses(ctx context.Context) (int64, error) {
+	res := d.Db.WithContext(ctx).Exec(`INSERT INTO project_statuses (project_id, user_id, status_key, name, category, position, created_at, updated_at)
+SELECT p.id, p.user_id, s.status_key, s.name, s.category, s.position, NOW(3), NOW(3)
+FROM projects p
+CROSS JOIN (SELECT ? AS status_key, ? AS name, ? AS category, 0 AS position
+            UNION ALL SELECT ?, ?, ?, 1) s
+WHERE NOT EXISTS (SELECT 1 FROM project_statuses ps WHERE ps.project_id = p.id)`,
+		TaskTodo, "待办", StatusOpen, TaskDone, "已完成", StatusClosed)
+	return res.RowsAffected, res.Error
+}
+
+// ListProjectStatuses 按顺序列出项目的状态
+func ListProjectStatuses(ctx context.Context, uid, pid int) ([]ProjectStatus, error) {
+	return listProjectStatuses(d.Db.WithContext(ctx), uid, pid)
+}
+
+func listProjectStatuses(tx *gorm.DB, uid, pid int) ([]ProjectStatus, error) {
+	items := []ProjectStatus{}
+	err := tx.Where("user_id = ? AND project_id = ?", uid, pid).
+		Order("position ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// FindStatus 在状态列表中按 Key 查找
+func FindStatus(statuses []ProjectStatus, key string) (ProjectStatus, bool) {
+	for _, s := range statuses {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return ProjectStatus{}, false
+}
+
+// FirstStatus 返回某分类中排在最前的状态
+func FirstStatus(statuses []ProjectStatus, category string) (ProjectStatus, bool) {
+	for _, s := range statuses {
+		if s.Category == category {
+			return s, true
+		}
+	}
+	return ProjectStatus{}, false
+}
+
+// CreateProjectStatus 新增状态，position 为 nil 时排在最后
+func CreateProjectStatus(ctx context.Context, s ProjectStatus, position *int) (ProjectStatus, error) {
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, s.UserID, s.ProjectID); err != nil {
+			return err
+		}
+		statuses, err := listProjectStatuses(tx, s.UserID, s.ProjectID)
+		if err != nil {
+			return err
+		}
+		idx := len(statuses)
+		if position != nil && *position >= 0 && *position < idx {
+			idx = *position
+		}
+		s, err = insertStatus(tx, statuses, s, idx)
+		return err
+	})
+	return s, err
+}
+
+// insertStatus 在第 idx 位插入状态并重排顺序，调用方需已锁定项目
+func insertStatus(tx *gorm.DB, statuses []ProjectStatus, s ProjectStatus, idx int) (ProjectStatus, error) {
+	if _, ok := FindStatus(statuses, s.Key); ok {
+		return s, ErrStatusExists
+	}
+	idx = min(max(idx, 0), len(statuses))
+	s.Position = idx
+	if err := tx.Create(&s).Error; err != nil {
+		if isDuplicateKey(err) {
+			return s, ErrStatusExists
+		}
+		return s, err
+	}
+	ordered := append(append(append([]ProjectStatus{}, statuses[:idx]...), s), statuses[idx:]...)
+	return s, renumberStatuses(tx, ordered)
+}
+
+// StatusTaskState 状态修改前任务的状态与完成时间；UpdatedAt 为修改后的时间，撤销前据此判断任务是否又被修改
+type StatusTaskState struct {
+	ID          int        `json:"id"`
+	Status      string     `json:"status"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// StatusChange 修改或删除状态的结果；Before 的 Position 为修改前的排序下标，Tasks 为随之改写的任务
+type StatusChange struct {
+	Before ProjectStatus
+	After  ProjectStatus
+	Tasks  []StatusTaskState
+}
+
+// TaskIDs 随状态改写的任务ID
+func (c StatusChange) TaskIDs() []int {
+	ids := make([]int, 0, len(c.Tasks))
+	for _, t := range c.Tasks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+// StatusUpdate 修改状态的可选字段
+type StatusUpdate struct {
+	Name     *string
+	Category *string
+	Position *int
+}
+
+// UpdateProjectStatus 修改状态；分类变化时同步该状态下任务的完成时间
+func UpdateProjectStatus(ctx context.Context, uid, pid int, key string, in StatusUpdate) (StatusChange, error) {
+	out := StatusChange{Tasks: []StatusTaskState{}}
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, uid, pid); err != nil {
+			return err
+		}
+		statuses, err := listProjectStatuses(tx, uid, pid)
+		if err != nil {
+			return err
+		}
+		idx := -1
+		for i, s := range statuses {
+			if s.Key == key {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return ErrStatusNotFound
+		}
+		cur := statuses[idx]
+		out.Before = cur
+		out.Before.Position = idx
+		update := map[string]interface{}{}
+		if in.Name != nil {
+			update["name"] = *in.Name
+			cur.Name = *in.Name
+		}
+		if in.Category != nil && *in.Category != cur.Category {
+			if countCategory(statuses, cur.Category) == 1 {
+				return ErrStatusLastOfCategory
+			}
+			update["category"] = *in.Category
+			cur.Category = *in.Category
+			if out.Tasks, err = syncStatusCategory(tx, uid, pid, key, cur.Category); err != nil {
+				return err
+			}
+		}
+		if len(update) > 0 {
+			if err := tx.Model(&ProjectStatus{}).Where("id = ?", cur.ID).Updates(update).Error; err != nil {
+				return err
+			}
+		}
+		if in.Position != nil && *in.Position != idx {
+			if err := moveStatus(tx, statuses, idx, *in.Position); err != nil {
+				return err
+			}
+		}
+		return tx.First(&out.After, cur.ID).Error
+	})
+	return out, err
+}
+
+// DeleteProjectStatus 删除状态；仍有任务（含回收站中的任务）时需指定 moveTo，任务整体迁移到目标状态
+func DeleteProjectStatus(ctx context.Context, uid, pid int, key, moveTo string) (StatusChange, error) {
+	out := StatusChange{Tasks: []StatusTaskState{}}
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, uid, pid); err != nil {
+			return err
+		}
+		statuses, err := listProjectStatuses(tx, uid, pid)
+		if err != nil {
+			return err
+		}
+		idx := statusIndex(statuses, func(s ProjectStatus) bool { return s.Key == key })
+		if idx < 0 {
+			return ErrStatusNotFound
+		}
+		cur := statuses[idx]
+		out.Before = cur
+		out.Before.Position = idx
+		if countCategory(statuses, cur.Category) == 1 {
+			return ErrStatusLastOfCategory
+		}
+		if out.Tasks, err = statusTasks(tx, uid, pid, key); err != nil {
+			return err
+		}
+		if len(out.Tasks) > 0 {
+			target, ok := FindStatus(statuses, moveTo)
+			if moveTo == "" || moveTo == key {
+				return ErrStatusInUse
+			}
+			if !ok {
+				return ErrStatusNotFound
+			}
+			now := time.Now()
+			if err := tx.Unscoped().Model(&Task{}).Where("id IN ?", out.TaskIDs()).
+				UpdateColumns(bulkStatusColumns(target, now)).Error; err != nil {
+				return err
+			}
+			for i := range out.Tasks {
+				out.Tasks[i].UpdatedAt = now
+			}
+		}
+		return tx.Delete(&ProjectStatus{}, cur.ID).Error
+	})
+	return out, err
+}
+
+// StatusColumns 进入某状态时一并写入的列：closed 分类保留已有完成时间，open 分类清除完成与归档时间
+func StatusColumns(s ProjectStatus) map[string]interface{} {
+	if s.Category == StatusClosed {
+		return map[string]interface{}{"status": s.Key, "completed_at": gorm.Expr("COALESCE(completed_at, ?)", time.Now())}
+	}
+	return map[string]interface{}{"status": s.Key, "completed_at": nil, "archived_at": nil}
+}
+
+// bulkStatusColumns 批量改写任务状态时同时更新 updated_at，使此前签发的撤销令牌失效
+func bulkStatusColumns(s ProjectStatus, now time.Time) map[string]interface{} {
+	cols := StatusColumns(s)
+	cols["updated_at"] = now
+	return cols
+}
+
+// statusTasks 某状态下的全部任务（含回收站中的任务）修改前的字段
+func statusTasks(tx *gorm.DB, uid, pid int, key string) ([]StatusTaskState, error) {
+	tasks := []StatusTaskState{}
+	err := tx.Unscoped().Model(&Task{}).Select("id, status, completed_at, archived_at").
+		Where("user_id = ? AND project_id = ? AND status = ?", uid, pid, key).Order("id ASC").Scan(&tasks).Error
+	return tasks, err
+}
+
+// syncStatusCategory 状态分类变化后更新该状态下全部任务的完成时间，返回任务修改前的字段
+func syncStatusCategory(tx *gorm.DB, uid, pid int, key, category string) ([]StatusTaskState, error) {
+	tasks, err := statusTasks(tx, uid, pid, key)
+	if err != nil || len(tasks) == 0 {
+		return tasks, err
+	}
+	now := time.Now()
+	for i := range tasks {
+		tasks[i].UpdatedAt = now
+	}
+	cols := bulkStatusColumns(ProjectStatus{Key: key, Category: category}, now)
+	return tasks, tx.Unscoped().Model(&Task{}).Where("id IN ?", StatusChange{Tasks: tasks}.TaskIDs()).UpdateColumns(cols).Error
+}
+
+func statusIndex(statuses []ProjectStatus, match func(ProjectStatus) bool) int {
+	for i, s := range statuses {
+		if match(s) {
+			return i
+		}
+	}
+	return -1
+}
+
+func countCategory(statuses []ProjectStatus, category string) int {
+	n := 0
+	for _, s := range statuses {
+		if s.Category == category {
+			n++
+		}
+	}
+	return n
+}
+
+// moveStatus 把第 idx 个状态移到第 pos 位并重排顺序
+func moveStatus(tx *gorm.DB, statuses []ProjectStatus, idx, pos int) error {
+	rest := append(append([]ProjectStatus{}, statuses[:idx]...), statuses[idx+1:]...)
+	pos = min(max(pos, 0), len(rest))
+	ordered := append(append(append([]ProjectStatus{}, rest[:pos]...), statuses[idx]), rest[pos:]...)
+	return renumberStatuses(tx, ordered)
+}
+
+func renumberStatuses(tx *gorm.DB, ordered []ProjectStatus) error {
+	for i, s := range ordered {
+		if s.Position == i {
+			continue
+		}
+		if err := tx.Model(&ProjectStatus{}).Where("id = ?", s.ID).UpdateColumn("position", i).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// undoStatusCreate 删除新建的状态；状态已被修改、已有任务使用或成为该分类的最后一个状态时视为记录已变化
+func undoStatusCreate(tx *gorm.DB, uid int, st UndoStep) error {
+	pid := st.Status.ProjectID
+	if err := lockProject(tx, uid, pid); err != nil {
+		return err
+	}
+	statuses, err := listProjectStatuses(tx, uid, pid)
+	if err != nil {
+		return err
+	}
+	idx := statusIndex(statuses, func(s ProjectStatus) bool { return s.ID == st.ID })
+	if idx < 0 || !sameInstant(statuses[idx].UpdatedAt, st.UpdatedAt) || countCategory(statuses, statuses[idx].Category) == 1 {
+		return ErrUndoStale
+	}
+	var n int64
+	if err := tx.Unscoped().Model(&Task{}).Where("user_id = ? AND project_id = ? AND status = ?", uid, pid, statuses[idx].Key).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrUndoStale
+	}
+	if err := tx.Delete(&ProjectStatus{}, st.ID).Error; err != nil {
+		return err
+	}
+	return renumberStatuses(tx, append(append([]ProjectStatus{}, statuses[:idx]...), statuses[idx+1:]...))
+}
+
+// undoStatusUpdate 写回状态修改前的名称、分类与顺序，以及随分类改写的任务
+func undoStatusUpdate(tx *gorm.DB, uid int, st UndoStep) error {
+	pid := st.Status.ProjectID
+	if err := lockProject(tx, uid, pid); err != nil {
+		return err
+	}
+	statuses, err := listProjectStatuses(tx, uid, pid)
+	if err != nil {
+		return err
+	}
+	idx := statusIndex(statuses, func(s ProjectStatus) bool { return s.ID == st.ID })
+	if idx < 0 || !sameInstant(statuses[idx].UpdatedAt, st.UpdatedAt) {
+		return ErrUndoStale
+	}
+	cur := statuses[idx]
+	if cur.Category != st.Status.Category && countCategory(statuses, cur.Category) == 1 {
+		return ErrUndoStale
+	}
+	if err := restoreStatusTasks(tx, uid, pid, st.Status.Tasks); err != nil {
+		return err
+	}
+	if err := tx.Model(&ProjectStatus{}).Where("id = ?", cur.ID).
+		Updates(map[string]interface{}{"name": st.Status.Name, "category": st.Status.Category}).Error; err != nil {
+		return err
+	}
+	if idx == st.Status.Position {
+		return nil
+	}
+	return moveStatus(tx, statuses, idx, st.Status.Position)
+}
+
+// undoStatusDelete 以原ID重建被删除的状态，并把迁走的任务移回；期间已建立同 Key 的状态时返回 ErrStatusExists
+func undoStatusDelete(tx *gorm.DB, uid int, st UndoStep) error {
+	pid := st.Status.ProjectID
+	if err := lockProject(tx, uid, pid); err != nil {
+		return err
+	}
+	statuses, err := listProjectStatuses(tx, uid, pid)
+	if err != nil {
+		return err
+	}
+	s := ProjectStatus{
+		ID:        st.ID,
+		ProjectID: pid,
+		UserID:    uid,
+		Key:       st.Status.Key,
+		Name:      st.Status.Name,
+		Category:  st.Status.Category,
+	}
+	if _, err := insertStatus(tx, statuses, s, st.Status.Position); err != nil {
+		return err
+	}
+	return restoreStatusTasks(tx, uid, pid, st.Status.Tasks)
+}
+
+// restoreStatusTasks 写回任务修改前的状态与完成时间，任务已被再次修改或移到其他项目时返回 ErrUndoStale
+func restoreStatusTasks(tx *gorm.DB, uid, pid int, tasks []StatusTaskState) error {
+	now := time.Now()
+	for _, t := range tasks {
+		var cur Task
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id, project_id, updated_at").
+			Where("id = ? AND user_id = ?", t.ID, uid).First(&cur).Error; err != nil {
+			return err
+		}
+		if cur.ProjectID != pid || !sameInstant(cur.UpdatedAt, t.UpdatedAt) {
+			return ErrUndoStale
+		}
+		if err := tx.Unscoped().Model(&Task{}).Where("id = ?", t.ID).UpdateColumns(map[string]interface{}{
+			"status":       t.Status,
+			"completed_at": t.CompletedAt,
+			"archived_at":  t.ArchivedAt,
+			"updated_at":   now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockProject 锁定项目行，串行化同一项目的状态修改与看板移动
+func lockProject(tx *gorm.DB, uid, pid int) error {
+	var p Project
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where("id = ? AND user_id = ?", pid, uid).First(&p).Error
+}
+
+// DropObsoleteTaskIndexes 状态改为自定义后，按 status 建立的提醒与归档索引由按完成时间的索引取代
+func DropObsoleteTaskIndexes(ctx context.Context) error {
+	m := d.Db.WithContext(ctx).Migrator()
+	for _, name := range []string{"idx_tasks_due_watch", "idx_tasks_remind_watch", "idx_tasks_archive"} {
+		if !m.HasIndex(&Task{}, name) {
+			continue
+		}
+		if err := m.DropIndex(&Task{}, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode 两步验证恢复码，只保存按用户计算的 HMAC，唯一性限定在同一用户内
+type RecoveryCode struct {
+	ID        int    `gorm:"primaryKey"`
+	UserID    int    `gorm:"not null;uniqueIndex:ux_recovery_user_code,priority:1"`
+	CodeHash  string `gorm:"size:64;not null;uniqueIndex:ux_recovery_user_code,priority:2"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// SetTOTPSecret 写入待确认的密钥，已开启两步验证的用户不会被覆盖
+func SetTOTPSecret(ctx context.Context, uid int, secret string) (int64, error) {
+	res := d.Db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND totp_enabled = ?", uid, false).
+		Update("totp_secret", secret)
+	return res.RowsAffected, res.Error
+}
+
+// EnableTOTP 开启两步验证并替换全部恢复码
+func EnableTOTP(ctx context.Context, uid int, codeHashes []string) error {
+	return d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&User{}).Where("id = ? AND totp_enabled = ?", uid, false).Update("totp_enabled", true)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("user_id = ?", uid).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]RecoveryCode, len(codeHashes))
+		for i, h := range codeHashes {
+			codes[i] = RecoveryCode{UserID: uid, CodeHash: h}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// DisableTOTP 关闭两步验证，清空密钥与恢复码
+func DisableTOTP(ctx context.Context, uid int) error {
+	return d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&User{}).Where("id = ?", uid).Updates(map[string]interface{}{
+			"totp_enabled": false,
+			"totp_secret":  "",
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		return tx.Where("user_id = ?", uid).Delete(&RecoveryCode{}).Error
+	})
+}
+
+// UseRecoveryCode 消费一个未使用的恢复码，返回是否命中
+func UseRecoveryCode(ctx context.Context, uid int, codeHash string) (bool, error) {
+	res := d.Db.WithContext(ctx).Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", uid, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
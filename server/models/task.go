@@ -15,23 +15,40 @@ type Task struct {
 	ProjectID   int        `gorm:"not null;index;index:idx_user_proj_sort,priority:2;uniqueIndex:ux_task_user_proj_title,priority:2"                                   json:"project_id"`
 	Title       string     `gorm:"size:200;not null;uniqueIndex:ux_task_user_proj_title,priority:3" json:"title"`
 	ContentMD   string     `gorm:"type:longtext"                         json:"content_md"`
-	Status      string     `gorm:"type:enum('todo','done');not null;default:'todo';index:idx_tasks_due_watch,priority:1" json:"status"`
+	// Status 为所属项目中某个 ProjectStatus 的 Key
+	Status      string     `gorm:"size:32;not null;default:'todo'" json:"status"`
 	Priority    int        `gorm:"type:tinyint;not null;default:3"       json:"priority"`
+	Tags        TaskTags   `gorm:"type:text"                             json:"tags"`
 	SortOrder   int64      `gorm:"not null;default:0;index:idx_user_sort,priority:2;index:idx_user_proj_sort,priority:3" json:"sort_order"`
-	DueAt       *time.Time `gorm:"index:idx_tasks_due_watch,priority:2" json:"due_at"`
+	DueAt       *time.Time `json:"due_at"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	ContentHtml string     `gorm:"type:longtext"                         json:"content_html"`
-	Notified    bool       `gorm:"not null;default:false;index:idx_tasks_due_watch,priority:3"`
+	Notified    bool       `gorm:"not null;default:false;index:idx_tasks_remind_open,priority:2"`
+	RemindAt    *time.Time `gorm:"index:idx_tasks_remind_open,priority:3" json:"remind_at"`
+	// CompletedAt 非空表示任务处于 closed 分类的状态
+	CompletedAt *time.Time `gorm:"index:idx_tasks_remind_open,priority:1;index:idx_tasks_archive_due,priority:2" json:"completed_at"`
+	// ArchivedAt 归档与完成状态相互独立，归档的任务不出现在列表、日程与提醒中
+	ArchivedAt  *time.Time `gorm:"index:idx_tasks_archive_due,priority:1" json:"archived_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	// DelFlag 未删除为 0，删除后为自身 ID，使已删除的任务不占用唯一索引
+	DelFlag     int        `gorm:"not null;default:0;uniqueIndex:ux_task_user_proj_title,priority:4" json:"-"`
 }
 
+// BeforeCreate 新任务排在项目列表最前，键与上一条之间留出间隔供之后插入
 func (t *Task) BeforeCreate(tx *gorm.DB) error {
-	if t.SortOrder == 0 {
-		t.SortOrder = time.Now().UnixNano()
+	if t.SortOrder != 0 {
+		return nil
 	}
+	key, err := newTaskSortList(t.UserID, t.ProjectID).nextKey(tx.Session(&gorm.Session{NewDB: true}))
+	if err != nil {
+		return err
+	}
+	t.SortOrder = key
 	return nil
 }
 
+// 默认状态的 Key
 const (
 	TaskTodo = "todo"
 	TaskDone = "done"
@@ -52,7 +69,13 @@ func CreateTaskByUidAndTask(uid int, t Task) (Task, error) {
 	t.UserID = uid
 	t.ID = 0
 
-	if err := d.Db.Create(&t).Error; err != nil {
+	err := d.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&t).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, nil, t, RevisionMeta{Reason: RevisionCreate})
+	})
+	if err != nil {
 		var me *mysql.MySQLError
 		if errors.As(err, &me) && me.Number == 1062 {
 			return Task{}, ErrTaskExists
@@ -68,17 +91,21 @@ func GetProjectByID(uid int, pid int) (Project, error) {
 	return project, err
 }
 
-func TaskListAll(uid int, pid int, status string) ([]Task, int64, error) {
+// TaskListAll 项目中未归档的任务，status、tag 非空时只返回该状态、带有该标签的任务
+func TaskListAll(uid int, pid int, status, tag string) ([]Task, int64, error) {
 	var (
 		task  []Task
 		total int64
 		tx    *gorm.DB
 	)
 
-	tx = d.Db.Model(&Task{}).Where("user_id = ? AND project_id = ?", uid, pid)
+	tx = d.Db.Model(&Task{}).Where("user_id = ? AND project_id = ? AND archived_at IS NULL", uid, pid)
 	if status != "" {
 		tx = tx.Where("status = ?", status)
 	}
+	if tag != "" {
+		tx = tx.Where("JSON_CONTAINS(tags, JSON_QUOTE(?))", tag)
+	}
 	if err := tx.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -92,12 +119,21 @@ func TaskListAll(uid int, pid int, status string) ([]Task, int64, error) {
 	return task, total, nil
 }
 
+// DeleteByIDAndProjectIDAndUID 将任务移入回收站，附件、图片引用与历史版本保留到永久删除时再清理
 func DeleteByIDAndProjectIDAndUID(id int, pid int, uid int) (int64, error) {
-	res := d.Db.Where("user_id = ? And project_id = ? And id = ? ", uid, pid, id).Delete(&Task{})
+	return softDeleteTask(d.Db.Where("project_id = ?", pid), id, uid)
+}
+
+func softDeleteTask(tx *gorm.DB, id, uid int) (int64, error) {
+	res := tx.Model(&Task{}).Where("user_id = ? And id = ?", uid, id).
+		UpdateColumns(softDeleteColumns(time.Now()))
+	if res.Error != nil {
+		return 0, res.Error
+	}
 	if res.RowsAffected == 0 {
 		return 0, gorm.ErrRecordNotFound
 	}
-	return res.RowsAffected, res.Error
+	return res.RowsAffected, nil
 }
 
 func GetTaskByIDAndProjectIDAndUID(id int, uid int, pid int) (Task, error) {
@@ -107,11 +143,12 @@ func GetTaskByIDAndProjectIDAndUID(id int, uid int, pid int) (Task, error) {
 	return t, err
 }
 
+// FindDueTasks 按提醒时间查找待提醒任务，处于 closed 分类状态的任务视为已完成，不再提醒
 func FindDueTasks(ctx context.Context, from, to time.Time, limit int) ([]Task, error) {
 	var tasks []Task
-	err := d.Db.WithContext(ctx).Where("status = ? AND notified = ? AND due_at IS NOT NULL AND due_at >= ? AND due_at < ?",
-		"todo", false, from, to).
-		Order("due_at ASC").
+	err := d.Db.WithContext(ctx).Where("completed_at IS NULL AND notified = ? AND remind_at IS NOT NULL AND remind_at >= ? AND remind_at < ? AND archived_at IS NULL",
+		false, from, to).
+		Order("remind_at ASC").
 		Limit(limit).
 		Find(&tasks).Error
 	if err != nil {
@@ -128,18 +165,36 @@ func UpdatedDueTasks(ctx context.Context, id int) (int64, error) {
     return res.RowsAffected, res.Error
 }
 
-func UpdateTaskByIDAndUID(update map[string]interface{}, id int, uid int) (Task, int64, error) {
-	var t Task
-	res := d.Db.Model(&Task{}).Where("id = ? AND user_id = ?", id, uid).Updates(update)
-	if err := res.Error; err != nil {
-		var me *mysql.MySQLError
-		if errors.As(err, &me) && me.Number == 1062 {
-			return Task{}, 0, ErrTaskExists
-		}
-		return Task{}, 0, err
-	}
-	if err := d.Db.Where("id = ? AND user_id = ?", id, uid).First(&t).Error; err != nil {
-		return t, 0, err
-	}
-	return t, res.RowsAffected, nil
+// ListAllTasks 用户全部任务，用于数据导出
+func ListAllTasks(ctx context.Context, uid int) ([]Task, error) {
+	var items []Task
+	err := d.Db.WithContext(ctx).Where("user_id = ?", uid).Order("project_id ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// BackfillRemindAt 为引入提醒时间之前创建的任务补齐 remind_at
+func BackfillRemindAt(ctx context.Context) (int64, error) {
+	res := d.Db.WithContext(ctx).Model(&Task{}).
+		Where("remind_at IS NULL AND due_at IS NOT NULL AND notified = ?", false).
+		Update("remind_at", gorm.Expr("due_at"))
+	return res.RowsAffected, res.Error
+}
+
+// ListTasksDueBetween 截止时间在 [from, to) 内的任务，用于日程视图
+func ListTasksDueBetween(ctx context.Context, uid int, from, to time.Time) ([]Task, error) {
+	var items []Task
+	err := d.Db.WithContext(ctx).
+		Where("user_id = ? AND due_at >= ? AND due_at < ? AND archived_at IS NULL", uid, from, to).
+		Order("due_at ASC, priority DESC").
+		Find(&items).Error
+	return items, err
+}
+
+// BackfillCompletedAt 引入完成时间之前已完成的任务以最后修改时间作为完成时间，需在补齐项目状态之后执行
+func BackfillCompletedAt(ctx context.Context) (int64, error) {
+	res := d.Db.WithContext(ctx).Unscoped().Model(&Task{}).
+		Where("completed_at IS NULL AND EXISTS (SELECT 1 FROM project_statuses ps "+
+			"WHERE ps.project_id = tasks.project_id AND ps.status_key = tasks.status AND ps.category = ?)", StatusClosed).
+		UpdateColumn("completed_at", gorm.Expr("updated_at"))
+	return res.RowsAffected, res.Error
 }
@@ -0,0 +1,239 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionRestore = "restore"
+	RevisionUndo    = "undo"
+	RevisionBase    = "baseline" // 引入历史记录前创建的任务，首次修改时补记修改前的内容
+)
+
+// taskRevisionLimit 每个任务保留的历史版本数
+const taskRevisionLimit = 100
+
+// FieldChange 一次修改中某个字段的前后值
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// FieldChanges 以 JSON 文本存储
+type FieldChanges []FieldChange
+
+func (v FieldChanges) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (v *FieldChanges) Scan(src any) error {
+	var b []byte
+	switch t := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		b = t
+	case string:
+		b = []byte(t)
+	default:
+		return fmt.Errorf("field changes: unsupported type %T", src)
+	}
+	if len(b) == 0 {
+		*v = nil
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+// TaskRevision 任务每次修改后的快照，Rev 在任务内从 1 递增
+type TaskRevision struct {
+	ID           int          `gorm:"primaryKey"                                       json:"-"`
+	TaskID       int          `gorm:"not null;uniqueIndex:ux_task_rev,priority:1"      json:"task_id"`
+	UserID       int          `gorm:"not null;index"                                   json:"-"`
+	Rev          int          `gorm:"not null;uniqueIndex:ux_task_rev,priority:2"      json:"rev"`
+	Reason       string       `gorm:"size:16;not null"                                 json:"reason"`
+	RestoredFrom *int         `json:"restored_from,omitempty"`
+	Title        string       `gorm:"size:200;not null"                                json:"title"`
+	ContentMD    string       `gorm:"type:longtext"                                    json:"content_md"`
+	Status       string       `gorm:"size:32;not null"                                 json:"status"`
+	Priority     int          `gorm:"not null"                                         json:"priority"`
+	Tags         TaskTags     `gorm:"type:text"                                        json:"tags"`
+	ProjectID    int          `gorm:"not null"                                         json:"project_id"`
+	DueAt        *time.Time   `json:"due_at"`
+	Changes      FieldChanges `gorm:"type:text"                                        json:"changes"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// RevisionMeta 记录修改来源
+type RevisionMeta struct {
+	Reason       string
+	RestoredFrom *int
+}
+
+func snapshotRevision(t Task, rev int, meta RevisionMeta, changes FieldChanges) TaskRevision {
+	return TaskRevision{
+		TaskID:       t.ID,
+		UserID:       t.UserID,
+		Rev:          rev,
+		Reason:       meta.Reason,
+		RestoredFrom: meta.RestoredFrom,
+		Title:        t.Title,
+		ContentMD:    t.ContentMD,
+		Status:       t.Status,
+		Priority:     t.Priority,
+		Tags:         t.Tags,
+		ProjectID:    t.ProjectID,
+		DueAt:        t.DueAt,
+		Changes:      changes,
+	}
+}
+
+// diffTask 比较会记录到历史中的字段；排序与提醒状态的变化不单独成为版本
+func diffTask(before, after Task) FieldChanges {
+	var ch FieldChanges
+	add := func(field string, from, to any) {
+		ch = append(ch, FieldChange{Field: field, From: from, To: to})
+	}
+	if before.Title != after.Title {
+		add("title", before.Title, after.Title)
+	}
+	if before.ContentMD != after.ContentMD {
+		// 正文可能很长，前后内容通过 diff 接口查看
+		add("content_md", nil, nil)
+	}
+	if before.Status != after.Status {
+		add("status", before.Status, after.Status)
+	}
+	if before.Priority != after.Priority {
+		add("priority", before.Priority, after.Priority)
+	}
+	if !sameTags(before.Tags, after.Tags) {
+		add("tags", before.Tags, after.Tags)
+	}
+	if before.ProjectID != after.ProjectID {
+		add("project_id", before.ProjectID, after.ProjectID)
+	}
+	if !sameTime(before.DueAt, after.DueAt) {
+		add("due_at", before.DueAt, after.DueAt)
+	}
+	return ch
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// recordRevision 在修改任务的同一事务中追加版本，并裁剪超出上限的旧版本
+func recordRevision(tx *gorm.DB, before *Task, after Task, meta RevisionMeta) error {
+	var last TaskRevision
+	err := tx.Select("rev").Where("task_id = ?", after.ID).Order("rev DESC").First(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	var changes FieldChanges
+	if before != nil {
+		changes = diffTask(*before, after)
+		if len(changes) == 0 {
+			return nil
+		}
+		if last.Rev == 0 {
+			base := snapshotRevision(*before, 1, RevisionMeta{Reason: RevisionBase}, nil)
+			base.CreatedAt = before.UpdatedAt
+			if err := tx.Create(&base).Error; err != nil {
+				return err
+			}
+			last.Rev = 1
+		}
+	}
+	rev := snapshotRevision(after, last.Rev+1, meta, changes)
+	if err := tx.Create(&rev).Error; err != nil {
+		return err
+	}
+	if rev.Rev > taskRevisionLimit {
+		return tx.Where("task_id = ? AND rev <= ?", after.ID, rev.Rev-taskRevisionLimit).Delete(&TaskRevision{}).Error
+	}
+	return nil
+}
+
+// UpdateTaskWithRevision 锁定任务行后修改，并记录修改后的版本；同时返回修改前的任务
+func UpdateTaskWithRevision(ctx context.Context, update map[string]interface{}, id int, uid int, meta RevisionMeta) (Task, Task, int64, error) {
+	var before, after Task
+	var affected int64
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		before, after, affected, err = updateTaskWithRevision(tx, update, id, uid, meta)
+		return err
+	})
+	if err != nil {
+		return Task{}, Task{}, 0, err
+	}
+	return before, after, affected, nil
+}
+
+func updateTaskWithRevision(tx *gorm.DB, update map[string]interface{}, id int, uid int, meta RevisionMeta) (before, after Task, affected int64, err error) {
+	if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", id, uid).First(&before).Error; err != nil {
+		return
+	}
+	res := tx.Model(&Task{}).Where("id = ? AND user_id = ?", id, uid).Updates(update)
+	if err = res.Error; err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) && me.Number == 1062 {
+			err = ErrTaskExists
+		}
+		return
+	}
+	affected = res.RowsAffected
+	if err = tx.Where("id = ? AND user_id = ?", id, uid).First(&after).Error; err != nil {
+		return
+	}
+	err = recordRevision(tx, &before, after, meta)
+	return
+}
+
+// ListTaskRevisions 按版本号倒序分页
+func ListTaskRevisions(ctx context.Context, uid, taskID, offset, limit int) ([]TaskRevision, int64, error) {
+	var items []TaskRevision
+	var total int64
+	tx := d.Db.WithContext(ctx).Model(&TaskRevision{}).Where("task_id = ? AND user_id = ?", taskID, uid)
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := tx.Omit("content_md").Order("rev DESC").Offset(offset).Limit(limit).Find(&items).Error
+	return items, total, err
+}
+
+func GetTaskRevision(ctx context.Context, uid, taskID, rev int) (TaskRevision, error) {
+	var r TaskRevision
+	err := d.Db.WithContext(ctx).Where("task_id = ? AND user_id = ? AND rev = ?", taskID, uid, rev).First(&r).Error
+	return r, err
+}
+
+// CompareRevisions 两个版本之间的字段差异
+func CompareRevisions(from, to TaskRevision) FieldChanges {
+	return diffTask(from.task(), to.task())
+}
+
+func (r TaskRevision) task() Task {
+	return Task{Title: r.Title, ContentMD: r.ContentMD, Status: r.Status, Priority: r.Priority, Tags: r.Tags, ProjectID: r.ProjectID, DueAt: r.DueAt}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 任务标签的数量与长度上限
+const (
+	TaskTagLimit  = 20
+	TaskTagMaxLen = 32
+)
+
+// ErrTooManyTags 添加标签后超过 TaskTagLimit
+var ErrTooManyTags = errors.New("标签数量超过上限")
+
+// TaskTags 任务标签，按添加顺序以 JSON 文本存储；标签区分大小写
+type TaskTags []string
+
+func (v TaskTags) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal([]string(v))
+	return string(b), err
+}
+
+func (v *TaskTags) Scan(src any) error {
+	var b []byte
+	switch t := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		b = t
+	case string:
+		b = []byte(t)
+	default:
+		return fmt.Errorf("task tags: unsupported type %T", src)
+	}
+	if len(b) == 0 {
+		*v = nil
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(v))
+}
+
+// MarshalJSON 没有标签时输出空数组
+func (v TaskTags) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(v))
+}
+
+// NormalizeTags 去掉首尾空白、空标签与重复标签，保持原有顺序；返回第一个超长的标签
+func NormalizeTags(tags []string) (TaskTags, string, bool) {
+	out := make(TaskTags, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || out.Has(tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > TaskTagMaxLen {
+			return nil, tag, false
+		}
+		out = append(out, tag)
+	}
+	return out, "", true
+}
+
+func (v TaskTags) Has(tag string) bool {
+	for _, t := range v {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 移除 remove 中的标签后在末尾追加 add 中尚未存在的标签；结果超过上限时返回 ErrTooManyTags
+// 结果与原标签相同时 changed 为 false
+func (v TaskTags) Apply(add, remove []string) (out TaskTags, changed bool, err error) {
+	for _, t := range v {
+		if !TaskTags(remove).Has(t) {
+			out = append(out, t)
+		}
+	}
+	for _, t := range add {
+		if !out.Has(t) {
+			out = append(out, t)
+		}
+	}
+	if len(out) > TaskTagLimit {
+		return nil, false, ErrTooManyTags
+	}
+	return out, !sameTags(v, out), nil
+}
+
+func sameTags(a, b TaskTags) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
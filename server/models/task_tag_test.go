@@ -0,0 +1,91 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	long := strings.Repeat("标", TaskTagMaxLen+1)
+	cases := []struct {
+		name string
+		in   []string
+		want TaskTags
+		bad  string
+	}{
+		{"empty", nil, TaskTags{}, ""},
+		{"trim and dedupe", []string{" work ", "home", "work", "", "  "}, TaskTags{"work", "home"}, ""},
+		{"case sensitive", []string{"Work", "work"}, TaskTags{"Work", "work"}, ""},
+		{"max length in runes", []string{strings.Repeat("标", TaskTagMaxLen)}, TaskTags{strings.Repeat("标", TaskTagMaxLen)}, ""},
+		{"too long", []string{"ok", long}, nil, long},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, bad, ok := NormalizeTags(tc.in)
+			if ok != (tc.bad == "") || bad != tc.bad {
+				t.Fatalf("NormalizeTags = (%v, %q, %v), want bad %q", got, bad, ok, tc.bad)
+			}
+			if ok && !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("NormalizeTags = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTaskTagsApply(t *testing.T) {
+	full := make(TaskTags, TaskTagLimit)
+	for i := range full {
+		full[i] = strings.Repeat("x", i+1)
+	}
+	cases := []struct {
+		name        string
+		cur         TaskTags
+		add, remove []string
+		want        TaskTags
+		changed     bool
+		err         error
+	}{
+		{"add to empty", nil, []string{"a", "b"}, nil, TaskTags{"a", "b"}, true, nil},
+		{"add existing", TaskTags{"a", "b"}, []string{"b"}, nil, TaskTags{"a", "b"}, false, nil},
+		{"remove", TaskTags{"a", "b", "c"}, nil, []string{"b", "z"}, TaskTags{"a", "c"}, true, nil},
+		{"remove all", TaskTags{"a"}, nil, []string{"a"}, nil, true, nil},
+		{"remove missing", TaskTags{"a"}, nil, []string{"z"}, TaskTags{"a"}, false, nil},
+		{"remove then add moves to end", TaskTags{"a", "b"}, []string{"a"}, []string{"a"}, TaskTags{"b", "a"}, true, nil},
+		{"add and remove same last tag", TaskTags{"a", "b"}, []string{"b"}, []string{"b"}, TaskTags{"a", "b"}, false, nil},
+		{"at limit", full[:TaskTagLimit-1], []string{"new"}, nil, append(full[:TaskTagLimit-1:TaskTagLimit-1], "new"), true, nil},
+		{"over limit", full, []string{"new"}, nil, nil, false, ErrTooManyTags},
+		{"swap at limit", full, []string{"new"}, []string{"x"}, append(full[1:len(full):len(full)], "new"), true, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, changed, err := tc.cur.Apply(tc.add, tc.remove)
+			if err != tc.err {
+				t.Fatalf("err = %v, want %v", err, tc.err)
+			}
+			if changed != tc.changed || !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Apply = (%v, %v), want (%v, %v)", got, changed, tc.want, tc.changed)
+			}
+		})
+	}
+}
+
+func TestTaskTagsValueScan(t *testing.T) {
+	for _, in := range []TaskTags{nil, {"工作", "a\"b"}} {
+		v, err := in.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out TaskTags
+		if err := out.Scan(v); err != nil {
+			t.Fatalf("Scan(%v): %v", v, err)
+		}
+		if !reflect.DeepEqual(out, in) {
+			t.Fatalf("roundtrip = %#v, want %#v", out, in)
+		}
+	}
+	var empty TaskTags
+	if b, _ := empty.MarshalJSON(); string(b) != "[]" {
+		t.Fatalf("MarshalJSON(nil) = %s, want []", b)
+	}
+}
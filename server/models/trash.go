@@ -0,0 +1,316 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrProjectInTrash 任务所属项目也在回收站中，需先恢复项目
+var ErrProjectInTrash = errors.New("所属项目在回收站中")
+
+const (
+	TrashTask    = "task"
+	TrashProject = "project"
+)
+
+// TrashItem 回收站中的项目或任务
+// 随项目一起删除的任务不单独列出，TaskCount 为恢复项目时会一并恢复的任务数
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	ProjectID int       `json:"project_id"`
+	Name      string    `json:"name"`
+	TaskCount int64     `json:"task_count"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func softDeleteColumns(now time.Time) map[string]interface{} {
+	return map[string]interface{}{"deleted_at": now, "del_flag": gorm.Expr("id")}
+}
+
+func restoreColumns() map[string]interface{} {
+	return map[string]interface{}{"deleted_at": nil, "del_flag": 0}
+}
+
+func isDuplicateKey(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == 1062
+}
+
+// MigrateSoftDeleteIndexes 引入回收站前建立的唯一索引不含 del_flag，AutoMigrate 不会修改已存在的索引，这里原地重建
+func MigrateSoftDeleteIndexes(ctx context.Context) error {
+	m := d.Db.WithContext(ctx).Migrator()
+	for _, it := range []struct {
+		model interface{}
+		table string
+		name  string
+		cols  string
+	}{
+		{&Task{}, "tasks", "ux_task_user_proj_title", "user_id, project_id, title, del_flag"},
+		{&Project{}, "projects", "ux_user_name", "user_id, name, del_flag"},
+	} {
+		indexes, err := m.GetIndexes(it.model)
+		if err != nil {
+			return err
+		}
+		migrated := false
+		for _, idx := range indexes {
+			if idx.Name() != it.name {
+				continue
+			}
+			for _, col := range idx.Columns() {
+				migrated = migrated || col == "del_flag"
+			}
+		}
+		if migrated {
+			continue
+		}
+		// 同一条语句内删除并重建，期间唯一约束不会缺失
+		if err := d.Db.WithContext(ctx).Exec("ALTER TABLE " + it.table + " DROP INDEX " + it.name +
+			", ADD UNIQUE INDEX " + it.name + " (" + it.cols + ")").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTrash 按删除时间倒序列出回收站，kind 为空时同时列出项目与任务
+// 所属项目仍在回收站中的任务不单独列出，恢复项目后再出现
+func ListTrash(ctx context.Context, uid int, kind string, offset, limit int) ([]TrashItem, int64, error) {
+	db := d.Db.WithContext(ctx)
+	projects := db.Unscoped().Model(&Project{}).
+		Where("projects.user_id = ? AND projects.deleted_at IS NOT NULL", uid).Session(&gorm.Session{})
+	tasks := db.Unscoped().Model(&Task{}).
+		Joins("JOIN projects ON projects.id = tasks.project_id AND projects.deleted_at IS NULL").
+		Where("tasks.user_id = ? AND tasks.deleted_at IS NOT NULL", uid).Session(&gorm.Session{})
+
+	var parts []*gorm.DB
+	var total int64
+	for _, q := range []struct {
+		kind string
+		db   *gorm.DB
+		cols string
+	}{
+		{TrashProject, projects, "? AS type, projects.id, 0 AS project_id, projects.name, " +
+			"(SELECT COUNT(*) FROM tasks WHERE tasks.project_id = projects.id AND tasks.deleted_at = projects.deleted_at) AS task_count, " +
+			"projects.deleted_at"},
+		{TrashTask, tasks, "? AS type, tasks.id, tasks.project_id, tasks.title AS name, 0 AS task_count, tasks.deleted_at"},
+	} {
+		if kind != "" && kind != q.kind {
+			continue
+		}
+		var n int64
+		if err := q.db.Count(&n).Error; err != nil {
+			return nil, 0, err
+		}
+		total += n
+		parts = append(parts, q.db.Select(q.cols, q.kind))
+	}
+	items := []TrashItem{}
+	var err error
+	if len(parts) == 1 {
+		err = db.Raw("? ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?", parts[0], limit, offset).Scan(&items).Error
+	} else {
+		err = db.Raw("(?) UNION ALL (?) ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?",
+			parts[0], parts[1], limit, offset).Scan(&items).Error
+	}
+	return items, total, err
+}
+
+// RestoreTask 从回收站恢复任务；同一项目下已有同名任务时返回 ErrTaskExists
+func RestoreTask(ctx context.Context, uid, id int) (Task, error) {
+	var t Task
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		t, err = restoreTask(tx, uid, id)
+		return err
+	})
+	return t, err
+}
+
+func restoreTask(tx *gorm.DB, uid, id int) (Task, error) {
+	var t Task
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, uid).First(&t).Error; err != nil {
+		return Task{}, err
+	}
+	var p Project
+	if err := tx.Select("id").Where("id = ? AND user_id = ?", t.ProjectID, uid).First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Task{}, ErrProjectInTrash
+		}
+		return Task{}, err
+	}
+	if err := tx.Unscoped().Model(&Task{}).Where("id = ?", id).
+		UpdateColumns(restoreColumns()).Error; err != nil {
+		if isDuplicateKey(err) {
+			return Task{}, ErrTaskExists
+		}
+		return Task{}, err
+	}
+	t.DeletedAt, t.DelFlag = gorm.DeletedAt{}, 0
+	return t, nil
+}
+
+// RestoreProject 恢复项目及随其一起删除的任务，返回恢复的任务ID
+// 之前单独删除的任务仍留在回收站
+func RestoreProject(ctx context.Context, uid, id int) (Project, []int, error) {
+	var p Project
+	var taskIDs []int
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		p, taskIDs, err = restoreProject(tx, uid, id)
+		return err
+	})
+	return p, taskIDs, err
+}
+
+func restoreProject(tx *gorm.DB, uid, id int) (Project, []int, error) {
+	var p Project
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, uid).First(&p).Error; err != nil {
+		return Project{}, nil, err
+	}
+	if err := tx.Unscoped().Model(&Project{}).Where("id = ?", id).
+		UpdateColumns(restoreColumns()).Error; err != nil {
+		if isDuplicateKey(err) {
+			return Project{}, nil, ErrProjectExists
+		}
+		return Project{}, nil, err
+	}
+	taskIDs := []int{}
+	if err := tx.Unscoped().Model(&Task{}).
+		Where("user_id = ? AND project_id = ? AND deleted_at = ?", uid, id, p.DeletedAt.Time).
+		Pluck("id", &taskIDs).Error; err != nil {
+		return Project{}, nil, err
+	}
+	if len(taskIDs) > 0 {
+		if err := tx.Unscoped().Model(&Task{}).Where("id IN ?", taskIDs).
+			UpdateColumns(restoreColumns()).Error; err != nil {
+			if isDuplicateKey(err) {
+				return Project{}, nil, ErrTaskExists
+			}
+			return Project{}, nil, err
+		}
+	}
+	p.DeletedAt, p.DelFlag = gorm.DeletedAt{}, 0
+	return p, taskIDs, nil
+}
+
+// PurgedTrash 一次永久删除的结果，ObjectKeys 为附件对象 key，按用户分组
+type PurgedTrash struct {
+	Projects   int64
+	Tasks      int64
+	ObjectKeys map[int][]string
+}
+
+// PurgeTrash 永久删除在 before 之前进入回收站的项目与任务，每类最多 limit 个
+// 每个项目单独一个事务，并锁定后复核删除时间，避免与恢复操作并发
+func PurgeTrash(ctx context.Context, before time.Time, limit int) (PurgedTrash, error) {
+	res := PurgedTrash{ObjectKeys: map[int][]string{}}
+	db := d.Db.WithContext(ctx)
+
+	var projects []Project
+	if err := db.Unscoped().Select("id, user_id").Where("deleted_at < ?", before).
+		Order("deleted_at ASC").Limit(limit).Find(&projects).Error; err != nil {
+		return res, err
+	}
+	for _, p := range projects {
+		keys := map[int][]string{}
+		var n int64
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var locked Project
+			if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+				Where("id = ? AND deleted_at < ?", p.ID, before).First(&locked).Error; err != nil {
+				return err
+			}
+			var ids []int
+			if err := tx.Unscoped().Model(&Task{}).Where("user_id = ? AND project_id = ?", p.UserID, p.ID).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			var err error
+			if n, err = purgeTasks(tx, ids, keys); err != nil {
+				return err
+			}
+			if err := tx.Where("project_id = ?", p.ID).Delete(&ProjectStatus{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&Project{}, p.ID).Error
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return res, err
+		}
+		res.Projects++
+		res.Tasks += n
+		mergeObjectKeys(res.ObjectKeys, keys)
+	}
+
+	var ids []int
+	if err := db.Unscoped().Model(&Task{}).Where("deleted_at < ?", before).
+		Order("deleted_at ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return res, err
+	}
+	if len(ids) == 0 {
+		return res, nil
+	}
+	keys := map[int][]string{}
+	var n int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var locked []int
+		if err := tx.Unscoped().Model(&Task{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND deleted_at < ?", ids, before).Pluck("id", &locked).Error; err != nil {
+			return err
+		}
+		var err error
+		n, err = purgeTasks(tx, locked, keys)
+		return err
+	})
+	if err != nil {
+		return res, err
+	}
+	res.Tasks += n
+	mergeObjectKeys(res.ObjectKeys, keys)
+	return res, nil
+}
+
+// purgeTasks 永久删除任务及其附件记录、图片引用与历史版本，附件对象 key 写入 keys
+func purgeTasks(tx *gorm.DB, ids []int, keys map[int][]string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var atts []Attachment
+	if err := tx.Select("id, user_id, object_key").Where("task_id IN ?", ids).Find(&atts).Error; err != nil {
+		return 0, err
+	}
+	for _, a := range atts {
+		keys[a.UserID] = append(keys[a.UserID], a.ObjectKey)
+	}
+	if len(atts) > 0 {
+		if err := tx.Where("task_id IN ?", ids).Delete(&Attachment{}).Error; err != nil {
+			return 0, err
+		}
+	}
+	if err := releaseTaskAssets(tx, ids); err != nil {
+		return 0, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&TaskRevision{}).Error; err != nil {
+		return 0, err
+	}
+	res := tx.Unscoped().Where("id IN ?", ids).Delete(&Task{})
+	return res.RowsAffected, res.Error
+}
+
+func mergeObjectKeys(dst, src map[int][]string) {
+	for uid, keys := range src {
+		dst[uid] = append(dst[uid], keys...)
+	}
+}
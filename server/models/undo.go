@@ -0,0 +1,362 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 撤销时执行的逆操作
+const (
+	UndoRestoreTask       = "restore_task"
+	UndoDeleteTask        = "delete_task"
+	UndoUpdateTask        = "update_task"
+	UndoRestoreProject    = "restore_project"
+	UndoDeleteProject     = "delete_project"
+	UndoUpdateProject     = "update_project"
+	UndoDeleteStatus      = "delete_status"
+	UndoUpdateStatus      = "update_status"
+	UndoRestoreStatus     = "restore_status"
+	UndoRestoreAttachment = "restore_attachment"
+)
+
+// ErrUndoStale 操作之后记录又被修改过，撤销会覆盖新的修改
+var ErrUndoStale = errors.New("记录已被再次修改")
+
+// TaskState 撤销修改时写回的任务字段
+type TaskState struct {
+	Title       string     `json:"title"`
+	ContentMD   string     `json:"content_md"`
+	ContentHtml string     `json:"content_html"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	Tags        TaskTags   `json:"tags"`
+	ProjectID   int        `json:"project_id"`
+	SortOrder   int64      `json:"sort_order"`
+	DueAt       *time.Time `json:"due_at"`
+	RemindAt    *time.Time `json:"remind_at"`
+	Notified    bool       `json:"notified"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+}
+
+func (s TaskState) columns() map[string]interface{} {
+	return map[string]interface{}{
+		"title":        s.Title,
+		"content_md":   s.ContentMD,
+		"content_html": s.ContentHtml,
+		"status":       s.Status,
+		"priority":     s.Priority,
+		"tags":         s.Tags,
+		"project_id":   s.ProjectID,
+		"sort_order":   s.SortOrder,
+		"due_at":       s.DueAt,
+		"remind_at":    s.RemindAt,
+		"notified":     s.Notified,
+		"completed_at": s.CompletedAt,
+		"archived_at":  s.ArchivedAt,
+	}
+}
+
+// ProjectState 撤销修改时写回的项目字段
+type ProjectState struct {
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	SortOrder int64  `json:"sort_order"`
+}
+
+// StatusState 撤销状态修改或删除时写回的字段，Position 为排序下标，Tasks 为当时随之改写的任务
+type StatusState struct {
+	ProjectID int               `json:"project_id"`
+	Key       string            `json:"key"`
+	Name      string            `json:"name"`
+	Category  string            `json:"category"`
+	Position  int               `json:"position"`
+	Tasks     []StatusTaskState `json:"tasks,omitempty"`
+}
+
+// AttachmentState 撤销删除时重新写入的附件记录
+type AttachmentState struct {
+	TaskID      int       `json:"task_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	ObjectKey   string    `json:"object_key"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UndoStep 一个逆操作；UpdatedAt 为原操作完成后记录的修改时间，撤销前据此判断记录是否又被修改
+type UndoStep struct {
+	Op         string           `json:"op"`
+	ID         int              `json:"id"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	Task       *TaskState       `json:"task,omitempty"`
+	Project    *ProjectState    `json:"project,omitempty"`
+	Status     *StatusState     `json:"status,omitempty"`
+	Attachment *AttachmentState `json:"attachment,omitempty"`
+}
+
+// UndoTaskCreate 撤销创建或从回收站恢复，即把任务重新移入回收站
+func UndoTaskCreate(t Task) UndoStep {
+	return UndoStep{Op: UndoDeleteTask, ID: t.ID, UpdatedAt: t.UpdatedAt}
+}
+
+func UndoTaskDelete(id int) UndoStep {
+	return UndoStep{Op: UndoRestoreTask, ID: id}
+}
+
+// UndoTaskUpdate 写回修改前的字段，移动、完成、归档、恢复历史版本都按修改处理
+func UndoTaskUpdate(before, after Task) UndoStep {
+	return UndoStep{Op: UndoUpdateTask, ID: after.ID, UpdatedAt: after.UpdatedAt, Task: &TaskState{
+		Title:       before.Title,
+		ContentMD:   before.ContentMD,
+		ContentHtml: before.ContentHtml,
+		Status:      before.Status,
+		Priority:    before.Priority,
+		Tags:        before.Tags,
+		ProjectID:   before.ProjectID,
+		SortOrder:   before.SortOrder,
+		DueAt:       before.DueAt,
+		RemindAt:    before.RemindAt,
+		Notified:    before.Notified,
+		CompletedAt: before.CompletedAt,
+		ArchivedAt:  before.ArchivedAt,
+	}}
+}
+
+func UndoProjectCreate(p Project) UndoStep {
+	return UndoStep{Op: UndoDeleteProject, ID: p.ID, UpdatedAt: p.UpdatedAt}
+}
+
+func UndoProjectDelete(id int) UndoStep {
+	return UndoStep{Op: UndoRestoreProject, ID: id}
+}
+
+func UndoProjectUpdate(before, after Project) UndoStep {
+	return UndoStep{Op: UndoUpdateProject, ID: after.ID, UpdatedAt: after.UpdatedAt, Project: &ProjectState{
+		Name:      before.Name,
+		Color:     before.Color,
+		SortOrder: before.SortOrder,
+	}}
+}
+
+func UndoStatusCreate(s ProjectStatus) UndoStep {
+	return UndoStep{Op: UndoDeleteStatus, ID: s.ID, UpdatedAt: s.UpdatedAt, Status: &StatusState{ProjectID: s.ProjectID, Key: s.Key}}
+}
+
+func UndoStatusUpdate(c StatusChange) UndoStep {
+	return UndoStep{Op: UndoUpdateStatus, ID: c.After.ID, UpdatedAt: c.After.UpdatedAt, Status: statusState(c)}
+}
+
+func UndoStatusDelete(c StatusChange) UndoStep {
+	return UndoStep{Op: UndoRestoreStatus, ID: c.Before.ID, Status: statusState(c)}
+}
+
+func statusState(c StatusChange) *StatusState {
+	return &StatusState{
+		ProjectID: c.Before.ProjectID,
+		Key:       c.Before.Key,
+		Name:      c.Before.Name,
+		Category:  c.Before.Category,
+		Position:  c.Before.Position,
+		Tasks:     c.Tasks,
+	}
+}
+
+// UndoAttachmentDelete 附件对象在撤销有效期内保留，撤销时只需恢复记录
+func UndoAttachmentDelete(a Attachment) UndoStep {
+	return UndoStep{Op: UndoRestoreAttachment, ID: a.ID, Attachment: &AttachmentState{
+		TaskID:      a.TaskID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		ObjectKey:   a.ObjectKey,
+		CreatedAt:   a.CreatedAt,
+	}}
+}
+
+// UndoResult 撤销涉及的任务与项目，用于清理缓存
+type UndoResult struct {
+	TaskIDs         []int  `json:"task_ids"`
+	ProjectIDs      []int  `json:"project_ids"`
+	ProjectsChanged bool   `json:"-"`
+	Updated         []Task `json:"-"` // 被写回字段的任务，用于同步正文图片引用
+}
+
+func (r *UndoResult) touch(taskIDs []int, projectIDs ...int) {
+	for _, id := range taskIDs {
+		r.TaskIDs = appendUnique(r.TaskIDs, id)
+	}
+	for _, id := range projectIDs {
+		r.ProjectIDs = appendUnique(r.ProjectIDs, id)
+	}
+}
+
+func appendUnique(s []int, v int) []int {
+	for _, x := range s {
+		if x == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
+
+// sameInstant 数据库时间精度为毫秒且写入时四舍五入，比较时允许 1ms 误差
+func sameInstant(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d < time.Millisecond && d > -time.Millisecond
+}
+
+// ApplyUndo 在同一事务中按相反顺序执行逆操作，任一步失败则全部回滚
+func ApplyUndo(ctx context.Context, uid int, steps []UndoStep) (UndoResult, error) {
+	res := UndoResult{TaskIDs: []int{}, ProjectIDs: []int{}}
+	err := d.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := len(steps) - 1; i >= 0; i-- {
+			if err := applyUndoStep(tx, uid, steps[i], &res); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return UndoResult{}, err
+	}
+	return res, nil
+}
+
+func applyUndoStep(tx *gorm.DB, uid int, st UndoStep, res *UndoResult) error {
+	switch st.Op {
+	case UndoRestoreTask:
+		t, err := restoreTask(tx, uid, st.ID)
+		if err != nil {
+			return err
+		}
+		res.touch([]int{t.ID}, t.ProjectID)
+	case UndoDeleteTask:
+		t, err := lockTaskUnchanged(tx, uid, st)
+		if err != nil {
+			return err
+		}
+		if _, err := softDeleteTask(tx, st.ID, uid); err != nil {
+			return err
+		}
+		res.touch([]int{t.ID}, t.ProjectID)
+	case UndoUpdateTask:
+		before, err := lockTaskUnchanged(tx, uid, st)
+		if err != nil {
+			return err
+		}
+		if st.Task.ProjectID != before.ProjectID {
+			var p Project
+			if err := tx.Select("id").Where("id = ? AND user_id = ?", st.Task.ProjectID, uid).First(&p).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrProjectInTrash
+				}
+				return err
+			}
+		}
+		if err := tx.Model(&Task{}).Where("id = ? AND user_id = ?", st.ID, uid).
+			Updates(st.Task.columns()).Error; err != nil {
+			if isDuplicateKey(err) {
+				return ErrTaskExists
+			}
+			return err
+		}
+		var after Task
+		if err := tx.Where("id = ? AND user_id = ?", st.ID, uid).First(&after).Error; err != nil {
+			return err
+		}
+		if err := recordRevision(tx, &before, after, RevisionMeta{Reason: RevisionUndo}); err != nil {
+			return err
+		}
+		res.touch([]int{after.ID}, before.ProjectID, after.ProjectID)
+		res.Updated = append(res.Updated, after)
+	case UndoRestoreProject:
+		_, taskIDs, err := restoreProject(tx, uid, st.ID)
+		if err != nil {
+			return err
+		}
+		res.touch(taskIDs, st.ID)
+		res.ProjectsChanged = true
+	case UndoDeleteProject:
+		if _, err := lockProjectUnchanged(tx, uid, st); err != nil {
+			return err
+		}
+		_, taskIDs, err := deleteProject(tx, st.ID, uid, time.Now())
+		if err != nil {
+			return err
+		}
+		res.touch(taskIDs, st.ID)
+		res.ProjectsChanged = true
+	case UndoUpdateProject:
+		if _, err := lockProjectUnchanged(tx, uid, st); err != nil {
+			return err
+		}
+		if err := tx.Model(&Project{}).Where("id = ? AND user_id = ?", st.ID, uid).
+			Updates(map[string]interface{}{
+				"name":       st.Project.Name,
+				"color":      st.Project.Color,
+				"sort_order": st.Project.SortOrder,
+			}).Error; err != nil {
+			if isDuplicateKey(err) {
+				return ErrProjectExists
+			}
+			return err
+		}
+		res.touch(nil, st.ID)
+		res.ProjectsChanged = true
+	case UndoDeleteStatus, UndoUpdateStatus, UndoRestoreStatus:
+		var err error
+		switch st.Op {
+		case UndoDeleteStatus:
+			err = undoStatusCreate(tx, uid, st)
+		case UndoUpdateStatus:
+			err = undoStatusUpdate(tx, uid, st)
+		default:
+			err = undoStatusDelete(tx, uid, st)
+		}
+		if err != nil {
+			return err
+		}
+		ids := make([]int, 0, len(st.Status.Tasks))
+		for _, t := range st.Status.Tasks {
+			ids = append(ids, t.ID)
+		}
+		res.touch(ids, st.Status.ProjectID)
+	case UndoRestoreAttachment:
+		if err := restoreAttachment(tx, uid, st); err != nil {
+			return err
+		}
+		res.touch([]int{st.Attachment.TaskID})
+	default:
+		return errors.New("unknown undo op: " + st.Op)
+	}
+	return nil
+}
+
+func lockTaskUnchanged(tx *gorm.DB, uid int, st UndoStep) (Task, error) {
+	var t Task
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", st.ID, uid).First(&t).Error; err != nil {
+		return Task{}, err
+	}
+	if !sameInstant(t.UpdatedAt, st.UpdatedAt) {
+		return Task{}, ErrUndoStale
+	}
+	return t, nil
+}
+
+func lockProjectUnchanged(tx *gorm.DB, uid int, st UndoStep) (Project, error) {
+	var p Project
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", st.ID, uid).First(&p).Error; err != nil {
+		return Project{}, err
+	}
+	if !sameInstant(p.UpdatedAt, st.UpdatedAt) {
+		return Project{}, ErrUndoStale
+	}
+	return p, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 撤销步骤以 JSON 保存在 Redis 中，往返后必须保留写回所需的全部字段
+func TestUndoStepJSONRoundTrip(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	done := now.Add(-time.Hour)
+	steps := []UndoStep{
+		UndoStatusCreate(ProjectStatus{ID: 7, ProjectID: 3, Key: "review", UpdatedAt: now}),
+		UndoStatusUpdate(StatusChange{
+			Before: ProjectStatus{ID: 7, ProjectID: 3, Key: "review", Name: "评审", Category: StatusClosed, Position: 2},
+			After:  ProjectStatus{ID: 7, ProjectID: 3, Key: "review", Name: "评审", Category: StatusOpen, UpdatedAt: now},
+			Tasks:  []StatusTaskState{{ID: 11, Status: "review", CompletedAt: &done, UpdatedAt: now}},
+		}),
+		UndoStatusDelete(StatusChange{
+			Before: ProjectStatus{ID: 7, ProjectID: 3, Key: "review", Name: "评审", Category: StatusOpen, Position: 1},
+			Tasks:  []StatusTaskState{{ID: 11, Status: "review", UpdatedAt: now}},
+		}),
+		// Attachment 的 ObjectKey 不输出到 JSON，撤销步骤需单独保存
+		UndoAttachmentDelete(Attachment{ID: 5, TaskID: 11, FileName: "a.pdf", ContentType: "application/pdf",
+			Size: 1024, ObjectKey: "attachments/u1/a.pdf", CreatedAt: now}),
+	}
+	b, err := json.Marshal(steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []UndoStep
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, steps) {
+		t.Fatalf("round trip mismatch:\n got  %+v\n want %+v", got, steps)
+	}
+	if got[3].Attachment.ObjectKey != "attachments/u1/a.pdf" {
+		t.Fatalf("object key lost: %+v", got[3].Attachment)
+	}
+	if got[1].Status.Position != 2 || got[1].Status.Category != StatusClosed {
+		t.Fatalf("status update step should carry the previous state: %+v", got[1].Status)
+	}
+}
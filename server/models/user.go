@@ -16,10 +16,16 @@ type User struct {
 	Password     string         `gorm:"size:255;not null"          json:"-"`
 	Username     string         `gorm:"size:64;not null;uniqueIndex"           json:"username"`
 	AvatarURL    string         `gorm:"size:512"                   json:"avatar_url"`
+	AvatarVariants AvatarVariants `gorm:"type:text" json:"avatar_variants,omitempty"`
 	Timezone     string         `gorm:"size:64;default:Asia/Shanghai" json:"timezone"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	TokenVersion int            `gorm:"not null;default:1"  json:"-"`
+	TOTPSecret   string         `gorm:"size:64"                    json:"-"`
+	TOTPEnabled  bool           `gorm:"not null;default:false"     json:"totp_enabled"`
+	Role         string         `gorm:"size:16;not null;default:user" json:"role"`
+	DeletionScheduledAt *time.Time `gorm:"index" json:"deletion_scheduled_at,omitempty"`
+	UserPreferences `gorm:"embedded" json:"preferences"`
 
 }
 
@@ -6,6 +6,7 @@ import (
 	"ToDoList/server/handler"
 	"ToDoList/server/middlewares"
 	"ToDoList/server/service"
+	"ToDoList/server/utils"
 	"context"
 
 	"github.com/redis/go-redis/v9"
@@ -47,31 +48,100 @@ func NewRouter(ctx context.Context, app *App) *gin.Engine {
 	taskSvc := service.NewTaskService(app.Bus)
 	taskCtl := handler.NewTaskHandler(taskSvc)
 	authSvc := service.NewAuthService(app.Bus)
+	oidcCfg, err := config.LoadOIDCConfig()
+	if err != nil {
+		panic(err)
+	}
+	oidcSvc := service.NewOIDCService(app.Bus, userSvc, oidcCfg)
+	oidcCtl := handler.NewOIDCHandler(oidcSvc)
+	tokenSvc := service.NewAccessTokenService(app.Bus)
+	tokenCtl := handler.NewAccessTokenHandler(tokenSvc)
+	exportSvc := service.NewExportService(app.Bus)
+	exportCtl := handler.NewExportHandler(exportSvc)
+	mediaCtl := handler.NewMediaHandler(service.NewMediaService())
+	attachmentCtl := handler.NewAttachmentHandler(service.NewAttachmentService(app.Bus))
+	assetCtl := handler.NewAssetHandler(service.NewAssetService(app.Bus))
+	storageGCSvc := service.NewStorageGCService()
+	storageGCCtl := handler.NewStorageGCHandler(storageGCSvc)
+	trashSvc := service.NewTrashService(app.Bus)
+	trashCtl := handler.NewTrashHandler(trashSvc)
+	undoCtl := handler.NewUndoHandler(service.NewUndoService())
 	public := r.Group("/api/v1")
 	{
 		public.POST("/login", userCtl.Login)
+		public.POST("/login/2fa", userCtl.LoginMFA)
+		public.GET("/auth/oidc/:provider/login", oidcCtl.Login)
+		public.GET("/auth/oidc/:provider/callback", oidcCtl.Callback)
 		public.POST("/register", userCtl.Register)
+		public.GET("/media/local/*key", mediaCtl.ServeLocal)
 	}
 
+	scope := middlewares.RequireScope
 	protected := r.Group("/api/v1")
 	protected.Use(middlewares.AuthMiddleware(authSvc))
 	{
-		protected.PATCH("/users/me", userCtl.Update)
-		protected.POST("/logout", userCtl.Logout)
-		protected.GET("/projects/:id", projectCtl.GetProjectByID)
-		protected.GET("/projects", projectCtl.Search)
-		protected.POST("/projects", projectCtl.Create)
-		protected.PATCH("/projects/:id", projectCtl.Update)
-		protected.DELETE("/projects/:id", projectCtl.Delete)
+		protected.PATCH("/users/me", scope(utils.ScopeAccountWrite), userCtl.Update)
+		protected.DELETE("/users/me", scope(utils.ScopeAccountWrite), userCtl.Delete)
+		protected.GET("/users/me/preferences", scope(utils.ScopeAccountRead), userCtl.GetPreferences)
+		protected.PATCH("/users/me/preferences", scope(utils.ScopeAccountWrite), userCtl.UpdatePreferences)
+		protected.POST("/users/me/2fa/enroll", scope(utils.ScopeAccountWrite), userCtl.EnrollTOTP)
+		protected.POST("/users/me/2fa/confirm", scope(utils.ScopeAccountWrite), userCtl.ConfirmTOTP)
+		protected.POST("/users/me/2fa/disable", scope(utils.ScopeAccountWrite), userCtl.DisableTOTP)
+		protected.POST("/users/me/tokens", scope(utils.ScopeAccountWrite), tokenCtl.Create)
+		protected.GET("/users/me/tokens", scope(utils.ScopeAccountRead), tokenCtl.List)
+		protected.DELETE("/users/me/tokens/:id", scope(utils.ScopeAccountWrite), tokenCtl.Revoke)
+		protected.POST("/users/me/export", scope(utils.ScopeAccountWrite), exportCtl.Start)
+		protected.GET("/users/me/export", scope(utils.ScopeAccountRead), exportCtl.Status)
+		protected.GET("/users/:id/avatar", scope(utils.ScopeAccountRead), mediaCtl.Avatar)
+		protected.POST("/logout", scope(utils.ScopeAccountWrite), userCtl.Logout)
+		protected.GET("/projects/:id", scope(utils.ScopeProjectsRead), projectCtl.GetProjectByID)
+		protected.GET("/projects", scope(utils.ScopeProjectsRead), projectCtl.Search)
+		protected.POST("/projects", scope(utils.ScopeProjectsWrite), projectCtl.Create)
+		protected.PATCH("/projects/:id", scope(utils.ScopeProjectsWrite), projectCtl.Update)
+		protected.DELETE("/projects/:id", scope(utils.ScopeProjectsAdmin), projectCtl.Delete)
+		protected.POST("/projects/:id/reorder", scope(utils.ScopeProjectsWrite), projectCtl.Reorder)
+		protected.GET("/projects/:id/statuses", scope(utils.ScopeProjectsRead), projectCtl.ListStatuses)
+		protected.POST("/projects/:id/statuses", scope(utils.ScopeProjectsWrite), projectCtl.CreateStatus)
+		protected.PATCH("/projects/:id/statuses/:key", scope(utils.ScopeProjectsWrite), projectCtl.UpdateStatus)
+		protected.DELETE("/projects/:id/statuses/:key", scope(utils.ScopeProjectsWrite), projectCtl.DeleteStatus)
+
+		protected.POST("/tasks", scope(utils.ScopeTasksWrite), taskCtl.Create)
+		protected.PATCH("/projects/:id/tasks/:task_id", scope(utils.ScopeTasksWrite), taskCtl.Update)
+		protected.DELETE("/tasks/:id", scope(utils.ScopeTasksWrite), taskCtl.Delete)
+		protected.GET("/projects/:id/tasks/:task_id", scope(utils.ScopeTasksRead), taskCtl.Search)
+		protected.GET("/projects/:id/board", scope(utils.ScopeTasksRead), taskCtl.Board)
+		protected.POST("/projects/:id/board/move", scope(utils.ScopeTasksWrite), taskCtl.MoveOnBoard)
+		protected.GET("/tasks", scope(utils.ScopeTasksRead), taskCtl.List)
+		protected.GET("/tasks/agenda", scope(utils.ScopeTasksRead), taskCtl.Agenda)
+		protected.GET("/tasks/archive", scope(utils.ScopeTasksRead), taskCtl.ListArchived)
+		protected.POST("/tasks/bulk", scope(utils.ScopeTasksWrite), taskCtl.Bulk)
+		protected.POST("/tasks/:id/archive", scope(utils.ScopeTasksWrite), taskCtl.Archive)
+		protected.POST("/tasks/:id/unarchive", scope(utils.ScopeTasksWrite), taskCtl.Unarchive)
+		protected.POST("/tasks/:id/reorder", scope(utils.ScopeTasksWrite), taskCtl.Reorder)
+		protected.POST("/tasks/:id/attachments", scope(utils.ScopeTasksWrite), attachmentCtl.Upload)
+		protected.GET("/tasks/:id/attachments", scope(utils.ScopeTasksRead), attachmentCtl.List)
+		protected.GET("/tasks/:id/attachments/:attachment_id", scope(utils.ScopeTasksRead), attachmentCtl.Download)
+		protected.DELETE("/tasks/:id/attachments/:attachment_id", scope(utils.ScopeTasksWrite), attachmentCtl.Delete)
+		protected.POST("/assets", scope(utils.ScopeTasksWrite), assetCtl.Upload)
+		protected.GET("/tasks/:id/revisions", scope(utils.ScopeTasksRead), taskCtl.ListRevisions)
+		protected.GET("/tasks/:id/revisions/diff", scope(utils.ScopeTasksRead), taskCtl.DiffRevisions)
+		protected.GET("/tasks/:id/revisions/:rev", scope(utils.ScopeTasksRead), taskCtl.GetRevision)
+		protected.POST("/tasks/:id/revisions/:rev/restore", scope(utils.ScopeTasksWrite), taskCtl.RestoreRevision)
+
+		protected.GET("/trash", scope(utils.ScopeTasksRead), trashCtl.List)
+		protected.POST("/trash/tasks/:id/restore", scope(utils.ScopeTasksWrite), trashCtl.RestoreTask)
+		protected.POST("/trash/projects/:id/restore", scope(utils.ScopeProjectsWrite), trashCtl.RestoreProject)
+		// 所需权限取决于被撤销的操作，由服务层按令牌记录校验
+		protected.POST("/undo/:token", undoCtl.Undo)
 
-		protected.POST("/tasks", taskCtl.Create)
-		protected.PATCH("/projects/:id/tasks/:task_id", taskCtl.Update)
-		protected.DELETE("/tasks/:id", taskCtl.Delete)
-		protected.GET("/projects/:id/tasks/:task_id", taskCtl.Search)
-		protected.GET("/tasks", taskCtl.List)
+		protected.POST("/admin/storage/gc", scope(utils.ScopeAdminSystem), storageGCCtl.Run)
 		
 	}
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	taskSvc.StartDueWatcher(ctx, logger)
+	taskSvc.StartArchiver(ctx, logger)
+	userSvc.StartAccountPurger(ctx, logger)
+	storageGCSvc.StartStorageGC(ctx, logger)
+	trashSvc.StartTrashPurger(ctx, logger)
 	return r
 }
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// TokenPrincipal 个人访问令牌解析出的身份
+type TokenPrincipal struct {
+	TokenID   int        `json:"token_id"`
+	UID       int        `json:"uid"`
+	Username  string     `json:"username"`
+	Role      string     `json:"role"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func patKey(hash string) string {
+	return "pat:" + hash
+}
+
+func patTouchKey(id int) string {
+	return "pat:touch:" + strconv.Itoa(id)
+}
+
+func GetTokenPrincipalCache(ctx context.Context, hash string) (*TokenPrincipal, error) {
+	b, err := c.Rdb.Get(ctx, patKey(hash)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var p TokenPrincipal
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func PutTokenPrincipalCache(ctx context.Context, hash string, p *TokenPrincipal, ttl time.Duration) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.Rdb.Set(ctx, patKey(hash), b, ttl).Err()
+}
+
+func DelTokenPrincipalCache(ctx context.Context, hash string) error {
+	return c.Rdb.Del(ctx, patKey(hash)).Err()
+}
+
+// ShouldTouchToken 限制 last_used_at 的写入频率，interval 内只返回一次 true
+func ShouldTouchToken(ctx context.Context, id int, interval time.Duration) (bool, error) {
+	return c.Rdb.SetNX(ctx, patTouchKey(id), 1, interval).Result()
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"ToDoList/server/async"
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const maxActiveAccessTokens = 50
+
+type AccessTokenService struct {
+	bus *async.EventBus
+}
+
+func NewAccessTokenService(bus *async.EventBus) *AccessTokenService {
+	return &AccessTokenService{bus: bus}
+}
+
+type CreateAccessTokenInput struct {
+	Name          string
+	Scopes        []string
+	ExpiresInDays *int
+	// Granted 发起请求的会话或令牌实际拥有的权限，新令牌不能超出
+	Granted []string
+}
+
+type CreateAccessTokenResult struct {
+	Token     models.PersonalAccessToken
+	Plaintext string
+}
+
+// Create 签发个人访问令牌，明文只在创建时返回一次
+func (s *AccessTokenService) Create(ctx context.Context, lg *zap.Logger, uid int, in CreateAccessTokenInput) (*CreateAccessTokenResult, error) {
+	lg = lg.With(zap.Int("uid", uid))
+	lg.Info("pat.create.begin", zap.Strings("scopes", in.Scopes))
+
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		lg.Warn("pat.create.name_empty")
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "pat.name_required"}
+	}
+	scopes, err := normalizeScopes(in.Scopes)
+	if err != nil {
+		lg.Warn("pat.create.scope_invalid", zap.Strings("scopes", in.Scopes))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "pat.scope_invalid"}
+	}
+	for _, sc := range scopes {
+		if !utils.HasScope(in.Granted, sc) {
+			lg.Warn("pat.create.scope_exceeds_caller", zap.String("scope", sc), zap.Strings("granted", in.Granted))
+			return nil, &AppError{Code: utils.ErrCodeForbidden, Key: "pat.scope_exceeds_caller"}
+		}
+	}
+	user, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil {
+		lg.Error("pat.create.user_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if user.ID == 0 {
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+	}
+	granted := utils.RoleScopes(user.Role)
+	for _, sc := range scopes {
+		if !utils.HasScope(granted, sc) {
+			lg.Warn("pat.create.scope_exceeds_role", zap.String("scope", sc), zap.String("role", user.Role))
+			return nil, &AppError{Code: utils.ErrCodeForbidden, Key: "pat.scope_exceeds_role"}
+		}
+	}
+
+	var expiresAt *time.Time
+	if in.ExpiresInDays != nil {
+		if *in.ExpiresInDays < 1 || *in.ExpiresInDays > 365 {
+			lg.Warn("pat.create.expiry_invalid", zap.Int("days", *in.ExpiresInDays))
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "pat.expiry_invalid"}
+		}
+		exp := time.Now().Add(time.Duration(*in.ExpiresInDays) * 24 * time.Hour)
+		expiresAt = &exp
+	}
+
+	n, err := models.CountActiveAccessTokens(ctx, uid)
+	if err != nil {
+		lg.Error("pat.create.count_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if n >= maxActiveAccessTokens {
+		lg.Info("pat.create.limit_reached", zap.Int64("active", n))
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "pat.limit_reached"}
+	}
+
+	random, err := utils.RandomURLToken(32)
+	if err != nil {
+		lg.Error("pat.create.random_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	plaintext := utils.PATPrefix + random
+	created, err := models.AddAccessToken(ctx, models.PersonalAccessToken{
+		UserID:    uid,
+		Name:      name,
+		TokenHash: hashAccessToken(plaintext),
+		Prefix:    plaintext[:len(utils.PATPrefix)+4],
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		lg.Error("pat.create.insert_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.create_failed"}
+	}
+	audit(lg, "pat_created", zap.Int("token_id", created.ID), zap.Strings("scopes", scopes))
+	return &CreateAccessTokenResult{Token: created, Plaintext: plaintext}, nil
+}
+
+func (s *AccessTokenService) List(ctx context.Context, lg *zap.Logger, uid int) ([]models.PersonalAccessToken, error) {
+	items, err := models.ListAccessTokens(ctx, uid)
+	if err != nil {
+		lg.Error("pat.list.query_failed", zap.Int("uid", uid), zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "pat.list_failed"}
+	}
+	return items, nil
+}
+
+func (s *AccessTokenService) Revoke(ctx context.Context, lg *zap.Logger, uid, id int) error {
+	lg = lg.With(zap.Int("uid", uid), zap.Int("token_id", id))
+	hash, err := models.RevokeAccessToken(ctx, uid, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			lg.Info("pat.revoke.not_found")
+			return &AppError{Code: utils.ErrCodeNotFound, Key: "pat.not_found"}
+		}
+		lg.Error("pat.revoke.db_failed", zap.Error(err))
+		return &AppError{Code: utils.ErrCodeInternalServer, Key: "pat.revoke_failed"}
+	}
+	ctxRedis, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := DelTokenPrincipalCache(ctxRedis, hash); err != nil {
+		lg.Warn("pat.revoke.del_cache_failed", zap.Error(err))
+	}
+	audit(lg, "pat_revoked")
+	return nil
+}
+
+// TouchAccessToken 异步记录最近使用时间
+func TouchAccessToken(ctx context.Context, id int, at time.Time) error {
+	return models.TouchAccessToken(ctx, id, at)
+}
+
+// normalizeScopes 校验并去重权限名，旧权限名展开为当前权限名后保存
+func normalizeScopes(in []string) ([]string, error) {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		s = strings.TrimSpace(s)
+		if !utils.IsValidScope(s) {
+			return nil, errors.New("invalid scope: " + s)
+		}
+		for _, ex := range utils.ExpandScopes([]string{s}) {
+			if !slices.Contains(out, ex) {
+				out = append(out, ex)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no scope")
+	}
+	return out, nil
+}
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"ToDoList/server/utils"
+
+	"go.uber.org/zap"
+)
+
+// 只有 account:write 的令牌不能签发权限更高的令牌；校验发生在查询数据库之前
+func TestCreateAccessTokenScopeExceedsCaller(t *testing.T) {
+	cases := []struct {
+		name    string
+		granted []string
+		scopes  []string
+	}{
+		{"tasks write from account write", []string{utils.ScopeAccountWrite}, []string{utils.ScopeTasksWrite}},
+		{"projects admin from account write", []string{utils.ScopeAccountWrite}, []string{utils.ScopeAccountRead, utils.ScopeProjectsAdmin}},
+		{"admin system from account write", []string{utils.ScopeAccountWrite}, []string{utils.ScopeAdminSystem}},
+		{"legacy name expands beyond caller", []string{utils.ScopeAccountWrite, utils.ScopeTasksRead}, []string{"read:tasks"}},
+		{"no granted scopes", nil, []string{utils.ScopeAccountRead}},
+	}
+	s := NewAccessTokenService(nil)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.Create(context.Background(), zap.NewNop(), 1, CreateAccessTokenInput{
+				Name:    "ci",
+				Scopes:  tc.scopes,
+				Granted: tc.granted,
+			})
+			var ae *AppError
+			if !errors.As(err, &ae) || ae.Code != utils.ErrCodeForbidden || ae.Key != "pat.scope_exceeds_caller" {
+				t.Fatalf("err = %v, want pat.scope_exceeds_caller", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"current", []string{" tasks:read ", "tasks:read", "account:write"}, []string{"tasks:read", "account:write"}},
+		{"legacy read", []string{"read:tasks"}, []string{"tasks:read", "projects:read"}},
+		{"legacy write", []string{"write:tasks"}, []string{"tasks:write", "projects:write"}},
+		{"legacy admin", []string{"admin"}, []string{"tasks:write", "projects:admin", "account:write"}},
+		{"legacy and current overlap", []string{"write:tasks", "tasks:write", "read:tasks"}, []string{"tasks:write", "projects:write", "tasks:read", "projects:read"}},
+		{"unknown", []string{"tasks:read", "tasks:delete"}, nil},
+		{"empty", []string{" "}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizeScopes(tc.in)
+			if tc.want == nil {
+				if err == nil {
+					t.Fatalf("normalizeScopes = %v, want error", got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("normalizeScopes = (%v, %v), want %v", got, err, tc.want)
+			}
+		})
+	}
+}
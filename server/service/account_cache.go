@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+func accountPurgeLockKey(uid int) string {
+	return fmt.Sprintf("acct:purge:%d", uid)
+}
+
+// userCachePatterns 账户相关的全部缓存键
+func userCachePatterns(uid int) []string {
+	return []string{
+		fmt.Sprintf("bl:acc_%d_*", uid),
+		fmt.Sprintf("bl:mfa_%d_*", uid),
+		fmt.Sprintf("u:%d:projects:*", uid),
+		fmt.Sprintf("task:detail:%d:*", uid),
+		fmt.Sprintf("task:list:%d:*", uid),
+		fmt.Sprintf("mfa:step:%d:*", uid),
+	}
+}
+
+// AcquirePurgeLock 防止多个实例同时清理同一账户
+func AcquirePurgeLock(ctx context.Context, uid int, ttl time.Duration) (bool, error) {
+	return c.Rdb.SetNX(ctx, accountPurgeLockKey(uid), 1, ttl).Result()
+}
+
+// PurgeUserCache 删除账户的版本号、头像、导出状态、偏好、令牌黑名单、项目与任务缓存以及个人访问令牌缓存
+func PurgeUserCache(ctx context.Context, uid int, patHashes []string) error {
+	keys := []string{"uver:" + strconv.Itoa(uid), strconv.Itoa(uid), exportKey(uid), prefsKey(uid)}
+	for _, h := range patHashes {
+		keys = append(keys, patKey(h))
+	}
+	if err := c.Rdb.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	for _, pattern := range userCachePatterns(uid) {
+		if err := delByPattern(ctx, pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func delByPattern(ctx context.Context, pattern string) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.Rdb.Scan(ctx, cursor, pattern, 200).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := c.Rdb.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
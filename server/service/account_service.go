@@ -0,0 +1,141 @@
+package service
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	accountPurgeInterval = 10 * time.Minute
+	accountPurgeLimit    = 50
+	accountPurgeLockTTL  = 5 * time.Minute
+)
+
+// RequestDeletion 校验密码后标记账户待注销，宽限期内重新登录即可撤销
+func (s *UserService) RequestDeletion(ctx context.Context, lg *zap.Logger, uid int, password string) (time.Time, error) {
+	lg = lg.With(zap.Int("uid", uid))
+	lg.Info("user.delete.begin")
+
+	user, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil {
+		lg.Error("user.delete.query_user_failed", zap.Error(err))
+		return time.Time{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	if user.ID == 0 {
+		return time.Time{}, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		lg.Warn("user.delete.password_mismatch")
+		return time.Time{}, &AppError{Code: utils.ErrCodeAuthFailed, Key: "account.password_incorrect"}
+	}
+
+	at := time.Now().Add(config.AccountDeletionGrace)
+	updated, hashes, err := models.ScheduleUserDeletion(ctx, uid, at)
+	if err != nil {
+		if errors.Is(err, models.ErrDeletionPending) {
+			return time.Time{}, &AppError{Code: utils.ErrCodeConflict, Key: "account.deletion_pending"}
+		}
+		lg.Error("user.delete.schedule_failed", zap.Error(err))
+		return time.Time{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "account.delete_failed"}
+	}
+
+	ctxRedis, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := PutVersion(ctxRedis, updated.ID, updated.TokenVersion); err != nil {
+		lg.Warn("user.delete.putTokenVersion_redis_failed", zap.Error(err))
+	}
+	for _, h := range hashes {
+		if err := DelTokenPrincipalCache(ctxRedis, h); err != nil {
+			lg.Warn("user.delete.del_pat_cache_failed", zap.Error(err))
+		}
+	}
+	audit(lg, "account_deletion_requested", zap.Time("purge_at", at), zap.Int("revoked_tokens", len(hashes)))
+	return at, nil
+}
+
+// cancelPendingDeletion 宽限期内登录成功时撤销注销申请
+func (s *UserService) cancelPendingDeletion(ctx context.Context, lg *zap.Logger, user models.User) bool {
+	if user.DeletionScheduledAt == nil {
+		return false
+	}
+	n, err := models.CancelUserDeletion(ctx, user.ID)
+	if err != nil {
+		lg.Error("user.delete.cancel_failed", zap.Int("uid", user.ID), zap.Error(err))
+		return false
+	}
+	if n > 0 {
+		audit(lg, "account_deletion_canceled", zap.Int("uid", user.ID))
+	}
+	return n > 0
+}
+
+// StartAccountPurger 定期清理宽限期已过的账户
+func (s *UserService) StartAccountPurger(ctx context.Context, lg *zap.Logger) {
+	go func() {
+		ticker := time.NewTicker(accountPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				lg.Info("account_purger.stopped")
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+				s.purgeDueAccounts(ctx, lg)
+				cancel()
+			}
+		}
+	}()
+}
+
+func (s *UserService) purgeDueAccounts(ctx context.Context, lg *zap.Logger) {
+	users, err := models.FindUsersDueForPurge(ctx, time.Now(), accountPurgeLimit)
+	if err != nil {
+		lg.Error("account_purger.find_failed", zap.Error(err))
+		return
+	}
+	for _, u := range users {
+		s.purgeAccount(ctx, lg.With(zap.Int("uid", u.ID)), u.ID)
+	}
+}
+
+// purgeAccount 删除账户的任务、项目与登录凭据，清理缓存，并通过 DeleteCOS 删除头像、附件与导出文件
+func (s *UserService) purgeAccount(ctx context.Context, lg *zap.Logger, uid int) {
+	ok, err := AcquirePurgeLock(ctx, uid, accountPurgeLockTTL)
+	if err != nil {
+		lg.Warn("account_purger.lock_failed", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	purged, err := models.PurgeUser(ctx, uid, time.Now())
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			lg.Error("account_purger.db_failed", zap.Error(err))
+		}
+		return
+	}
+
+	user := purged.User
+	objects := append(user.AvatarObjectKeys(), purged.ObjectKeys...)
+	if st, err := GetExportState(ctx, uid); err == nil && st.Key != "" {
+		objects = append(objects, st.Key)
+	}
+	if err := PurgeUserCache(ctx, uid, purged.TokenHashes); err != nil {
+		lg.Warn("account_purger.cache_failed", zap.Error(err))
+	}
+	s.clearLoginFailures(ctx, lg, user.Username)
+
+	deleteObjects(s.bus, lg, uid, objects)
+	audit(lg, "account_purged", zap.String("username", user.Username))
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	AgendaDay  = "day"
+	AgendaWeek = "week"
+)
+
+type AgendaInput struct {
+	Range string // day / week
+	Date  string // YYYY-MM-DD，按用户时区，默认今天
+}
+
+type AgendaItem struct {
+	ID        int        `json:"id"`
+	ProjectID int        `json:"project_id"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	Priority  int        `json:"priority"`
+	DueAt     *time.Time `json:"due_at"`
+}
+
+type AgendaResult struct {
+	Timezone string       `json:"timezone"`
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Items    []AgendaItem `json:"items"`
+}
+
+// Agenda 按用户时区划分日/周边界，列出期间截止的任务
+func (t *TaskService) Agenda(ctx context.Context, lg *zap.Logger, uid int, in AgendaInput) (*AgendaResult, error) {
+	prefs := loadPreferences(ctx, lg, uid)
+	loc := prefs.Location()
+
+	day := time.Now().In(loc)
+	if d := strings.TrimSpace(in.Date); d != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, d, loc)
+		if err != nil {
+			lg.Warn("task.agenda.date_invalid", zap.String("date", d))
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.agenda_date_invalid"}
+		}
+		day = parsed
+	}
+
+	var from, to time.Time
+	switch in.Range {
+	case "", AgendaDay:
+		from = utils.StartOfDay(day, loc)
+		to = from.AddDate(0, 0, 1)
+	case AgendaWeek:
+		from = utils.StartOfWeek(day, loc, time.Weekday(prefs.WeekStart))
+		to = from.AddDate(0, 0, 7)
+	default:
+		lg.Warn("task.agenda.range_invalid", zap.String("range", in.Range))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.agenda_range_invalid"}
+	}
+
+	tasks, err := models.ListTasksDueBetween(ctx, uid, from, to)
+	if err != nil {
+		lg.Error("task.agenda.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "task.agenda_failed"}
+	}
+	items := make([]AgendaItem, len(tasks))
+	for i, tk := range tasks {
+		var due *time.Time
+		if tk.DueAt != nil {
+			d := tk.DueAt.In(loc)
+			due = &d
+		}
+		items[i] = AgendaItem{
+			ID:        tk.ID,
+			ProjectID: tk.ProjectID,
+			Title:     tk.Title,
+			Status:    tk.Status,
+			Priority:  tk.Priority,
+			DueAt:     due,
+		}
+	}
+	return &AgendaResult{Timezone: loc.String(), From: from, To: to, Items: items}, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"ToDoList/server/async"
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/storage"
+	"ToDoList/server/utils"
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const assetMaxPixels = 40_000_000
+
+type AssetService struct {
+	bus *async.EventBus
+}
+
+func NewAssetService(bus *async.EventBus) *AssetService {
+	return &AssetService{bus: bus}
+}
+
+// AssetUploadResult 上传结果，Markdown 可直接插入任务正文
+type AssetUploadResult struct {
+	Asset    models.Asset `json:"asset"`
+	Ref      string       `json:"ref"`
+	Markdown string       `json:"markdown"`
+	SignedLink
+}
+
+// Upload 上传正文图片；未被任何任务引用的图片超过宽限期后由对象清理任务删除
+func (s *AssetService) Upload(ctx context.Context, lg *zap.Logger, uid int, fh *multipart.FileHeader) (*AssetUploadResult, error) {
+	lg.Info("asset.upload.begin", zap.Int("uid", uid), zap.Int64("size", fh.Size))
+	if fh.Size > config.AssetMaxBytes {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "asset.too_large", Args: []any{config.AssetMaxBytes >> 20}}
+	}
+	f, err := fh.Open()
+	if err != nil {
+		lg.Warn("asset.open_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "asset.upload_failed"}
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, config.AssetMaxBytes+1))
+	if err != nil {
+		lg.Warn("asset.read_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "asset.upload_failed"}
+	}
+	if int64(len(data)) > config.AssetMaxBytes {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "asset.too_large", Args: []any{config.AssetMaxBytes >> 20}}
+	}
+	typ := utils.SniffImageType(data)
+	if typ == "" {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "asset.type_unsupported"}
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		lg.Warn("asset.decode_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "asset.type_unsupported"}
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > assetMaxPixels {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "asset.dimensions", Args: []any{assetMaxPixels / 1_000_000}}
+	}
+
+	rnd, err := utils.RandomURLToken(6)
+	if err != nil {
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "asset.upload_failed"}
+	}
+	ext := typ
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+	key := "images/assets/" + strconv.Itoa(uid) + "/" + time.Now().Format("20060102_150405") + "_" + rnd + "." + ext
+	contentType := "image/" + typ
+	if err := storage.Default.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		lg.Error("asset.put_failed", zap.String("key", key), zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "asset.upload_failed"}
+	}
+	a, err := models.CreateAsset(ctx, models.Asset{
+		UserID:      uid,
+		ObjectKey:   key,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	})
+	if err != nil {
+		lg.Error("asset.db_failed", zap.Error(err))
+		deleteObjects(s.bus, lg, uid, []string{key})
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "asset.upload_failed"}
+	}
+	l, err := SignLink(ctx, lg, key, "")
+	if err != nil {
+		return nil, err
+	}
+	ref := utils.AssetScheme + ":" + strconv.Itoa(a.ID)
+	lg.Info("asset.upload.ok", zap.Int("asset_id", a.ID), zap.String("key", key))
+	return &AssetUploadResult{Asset: a, Ref: ref, Markdown: "![](" + ref + ")", SignedLink: *l}, nil
+}
+
+// resolveAssets 读取时把正文中的 asset:<id> 替换为限时链接；缓存中保存的是替换前的内容
+func resolveAssets(ctx context.Context, lg *zap.Logger, uid int, html string) string {
+	ids := utils.AssetIDs(html)
+	if len(ids) == 0 {
+		return html
+	}
+	assets, err := models.GetAssetsByIDs(ctx, uid, ids)
+	if err != nil {
+		lg.Warn("asset.resolve_failed", zap.Error(err))
+		return html
+	}
+	links := make(map[int]string, len(assets))
+	for _, a := range assets {
+		l, err := SignLink(ctx, lg, a.ObjectKey, "")
+		if err != nil {
+			continue
+		}
+		links[a.ID] = l.URL
+	}
+	return utils.RewriteAssetURLs(html, func(id int) (string, bool) {
+		u, ok := links[id]
+		return u, ok
+	})
+}
+
+// syncTaskAssets 正文变化后更新任务引用的图片
+func syncTaskAssets(ctx context.Context, lg *zap.Logger, uid, taskID int, html string) {
+	if err := models.SyncTaskAssets(ctx, uid, taskID, utils.AssetIDs(html)); err != nil {
+		lg.Warn("asset.sync_failed", zap.Int("task_id", taskID), zap.Error(err))
+	}
+}
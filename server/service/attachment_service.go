@@ -0,0 +1,221 @@
+package service
+
+import (
+	"ToDoList/server/async"
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/storage"
+	"ToDoList/server/utils"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var attachmentExtRe = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// attachmentUndoSlack 删除附件后，对象在撤销令牌过期后再保留的时长
+const attachmentUndoSlack = time.Minute
+
+type AttachmentService struct {
+	bus *async.EventBus
+}
+
+func NewAttachmentService(bus *async.EventBus) *AttachmentService {
+	return &AttachmentService{bus: bus}
+}
+
+// AttachmentLink 附件元数据与限时下载链接
+type AttachmentLink struct {
+	Attachment models.Attachment `json:"attachment"`
+	SignedLink
+}
+
+func (s *AttachmentService) limits() models.AttachmentLimits {
+	return models.AttachmentLimits{MaxPerTask: config.AttachmentMaxPerTask, UserQuota: config.AttachmentUserQuota}
+}
+
+// checkTask 校验任务归属，不存在与无权访问返回同样的结果
+func (s *AttachmentService) checkTask(ctx context.Context, lg *zap.Logger, uid, taskID int) error {
+	if _, err := models.GetTaskByIDAndUID(ctx, taskID, uid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
+		}
+		lg.Error("attachment.task_query_failed", zap.Error(err))
+		return &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	return nil
+}
+
+// Upload 上传附件：先写对象存储，再在事务内复核配额写入记录，失败时删除已上传的对象
+func (s *AttachmentService) Upload(ctx context.Context, lg *zap.Logger, uid, taskID int, fh *multipart.FileHeader) (*models.Attachment, error) {
+	lg.Info("attachment.upload.begin", zap.Int("uid", uid), zap.Int("task_id", taskID), zap.Int64("size", fh.Size))
+	if fh.Size > config.AttachmentMaxBytes {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "attachment.too_large", Args: []any{config.AttachmentMaxBytes >> 20}}
+	}
+	if fh.Size == 0 {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "attachment.empty"}
+	}
+	if err := s.checkTask(ctx, lg, uid, taskID); err != nil {
+		return nil, err
+	}
+	// 预检空间，避免明显超额的文件白白上传
+	used, err := models.AttachmentUsage(ctx, uid)
+	if err != nil {
+		lg.Error("attachment.usage_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "attachment.upload_failed"}
+	}
+	if used+fh.Size > config.AttachmentUserQuota {
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "attachment.quota_exceeded", Args: []any{config.AttachmentUserQuota >> 20}}
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		lg.Warn("attachment.open_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "attachment.upload_failed"}
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		lg.Warn("attachment.read_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "attachment.upload_failed"}
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+
+	name := sanitizeFileName(fh.Filename)
+	rnd, err := utils.RandomURLToken(6)
+	if err != nil {
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "attachment.upload_failed"}
+	}
+	key := "attachments/" + strconv.Itoa(uid) + "/" + time.Now().Format("20060102_150405") + "_" + rnd
+	if ext := strings.ToLower(path.Ext(name)); attachmentExtRe.MatchString(ext) {
+		key += ext
+	}
+	if err := storage.Default.Put(ctx, key, io.MultiReader(bytes.NewReader(head), f), fh.Size, contentType); err != nil {
+		lg.Error("attachment.put_failed", zap.String("key", key), zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "attachment.upload_failed"}
+	}
+
+	a, err := models.AddAttachment(ctx, models.Attachment{
+		UserID:      uid,
+		TaskID:      taskID,
+		FileName:    name,
+		ContentType: contentType,
+		Size:        fh.Size,
+		ObjectKey:   key,
+	}, s.limits())
+	if err != nil {
+		deleteObjects(s.bus, lg, uid, []string{key})
+		switch {
+		case errors.Is(err, models.ErrAttachmentLimit):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "attachment.too_many", Args: []any{config.AttachmentMaxPerTask}}
+		case errors.Is(err, models.ErrAttachmentQuota):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "attachment.quota_exceeded", Args: []any{config.AttachmentUserQuota >> 20}}
+		}
+		lg.Error("attachment.db_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "attachment.upload_failed"}
+	}
+	lg.Info("attachment.upload.ok", zap.Int("attachment_id", a.ID), zap.String("key", key))
+	return &a, nil
+}
+
+func (s *AttachmentService) List(ctx context.Context, lg *zap.Logger, uid, taskID int) ([]models.Attachment, error) {
+	if err := s.checkTask(ctx, lg, uid, taskID); err != nil {
+		return nil, err
+	}
+	items, err := models.ListAttachments(ctx, uid, taskID)
+	if err != nil {
+		lg.Error("attachment.list_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	return items, nil
+}
+
+// Download 附件限时下载链接，下载时使用原始文件名
+func (s *AttachmentService) Download(ctx context.Context, lg *zap.Logger, uid, taskID, id int) (*AttachmentLink, error) {
+	a, err := models.GetAttachment(ctx, uid, taskID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "attachment.not_found"}
+		}
+		lg.Error("attachment.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	l, err := SignLink(ctx, lg, a.ObjectKey, a.FileName)
+	if err != nil {
+		return nil, err
+	}
+	return &AttachmentLink{Attachment: a, SignedLink: *l}, nil
+}
+
+// Delete 删除附件并返回撤销令牌；对象在撤销有效期过后仍未被恢复时才删除
+func (s *AttachmentService) Delete(ctx context.Context, lg *zap.Logger, uid, taskID, id int) (string, error) {
+	a, err := models.DeleteAttachment(ctx, uid, taskID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", &AppError{Code: utils.ErrCodeNotFound, Key: "attachment.not_found"}
+		}
+		lg.Error("attachment.delete_failed", zap.Error(err))
+		return "", &AppError{Code: utils.ErrCodeInternalServer, Key: "common.delete_failed"}
+	}
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoAttachmentDelete(a))
+	if token == "" {
+		deleteObjects(s.bus, lg, uid, []string{a.ObjectKey})
+	} else {
+		s.deleteObjectAfterUndo(lg, uid, a.ObjectKey)
+	}
+	lg.Info("attachment.delete.ok", zap.Int("attachment_id", id))
+	return token, nil
+}
+
+// deleteObjectAfterUndo 撤销令牌过期后对象仍未被引用时删除；进程在此之前退出时由存储清理任务回收
+func (s *AttachmentService) deleteObjectAfterUndo(lg *zap.Logger, uid int, key string) {
+	// 令牌过期前开始的撤销可能稍晚提交，多等一段时间
+	time.AfterFunc(config.UndoTTL+attachmentUndoSlack, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		used, err := models.AttachmentObjectInUse(ctx, key)
+		if err != nil {
+			lg.Warn("attachment.delete.ref_check_failed", zap.String("key", key), zap.Error(err))
+			return
+		}
+		if !used {
+			deleteObjects(s.bus, lg, uid, []string{key})
+		}
+	})
+}
+
+// sanitizeFileName 去掉路径与控制字符，保留原始文件名用于下载
+func sanitizeFileName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" || name == ".." {
+		return "file"
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,8 @@
+package service
+
+import "go.uber.org/zap"
+
+// audit 安全审计日志，统一带 audit 标记便于检索
+func audit(lg *zap.Logger, event string, fields ...zap.Field) {
+	lg.Info("audit."+event, append([]zap.Field{zap.Bool("audit", true)}, fields...)...)
+}
@@ -7,6 +7,7 @@ import (
 	"ToDoList/server/utils"
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -28,12 +29,12 @@ func (a *AuthService) ValidateJti(ctx context.Context, lg *zap.Logger, jti strin
 	blacklisted, err := ExistsJti(ctxRedis, jti)
 	if err != nil {
 		lg.Warn("user.auth.ExistsJti_redis_failed", zap.Error(err))
-		return &AppError{Code: utils.ErrCodeInternalServer, Message: "服务忙，请稍后重试"}
+		return &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
 	}
 	if !blacklisted {
 		return nil
 	}
-	return &AppError{Code: utils.ErrCodeNotFound, Message: "用户已登出，请重新登录"}
+	return &AppError{Code: utils.ErrCodeNotFound, Key: "auth.logged_out"}
 }
 
 func (a *AuthService) ValidateVersion(ctx context.Context, lg *zap.Logger, uid int, reqVersion int) error {
@@ -49,12 +50,12 @@ func (a *AuthService) ValidateVersion(ctx context.Context, lg *zap.Logger, uid i
 	u, dbErr := models.GetVersionByID(ctx, uid)
 	if dbErr != nil {
 		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
-			return &AppError{Code: utils.ErrCodeNotFound, Message: "该用户不存在"}
+			return &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
 		}
-		return &AppError{Code: utils.ErrCodeInternalServer, Message: "服务忙"}
+		return &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
 	}
 	if reqVersion != u.TokenVersion {
-		return &AppError{Code: utils.ErrCodeAuthFailed, Message: "令牌已失效"}
+		return &AppError{Code: utils.ErrCodeAuthFailed, Key: "auth.token_invalid"}
 	}
 
 	if errors.Is(cacheErr, redis.Nil) {
@@ -69,3 +70,65 @@ func (a *AuthService) ValidateVersion(ctx context.Context, lg *zap.Logger, uid i
 	return nil
 
 }
+
+const (
+	patCacheTTL      = 5 * time.Minute
+	patTouchInterval = time.Minute
+)
+
+// ValidateAccessToken 校验个人访问令牌：已吊销、已过期或用户不存在均视为无效
+func (a *AuthService) ValidateAccessToken(ctx context.Context, lg *zap.Logger, token string) (*TokenPrincipal, error) {
+	hash := hashAccessToken(token)
+
+	rctx, rcancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	p, cacheErr := GetTokenPrincipalCache(rctx, hash)
+	rcancel()
+	if cacheErr != nil {
+		if !errors.Is(cacheErr, redis.Nil) {
+			lg.Warn("user.auth.pat_cache_failed", zap.Error(cacheErr))
+		}
+		t, err := models.GetAccessTokenByHash(ctx, hash)
+		if err != nil {
+			lg.Error("user.auth.pat_query_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+		}
+		if t.ID == 0 || t.RevokedAt != nil {
+			return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "auth.token_invalid"}
+		}
+		u, err := models.GetUserInfoByID(ctx, t.UserID)
+		if err != nil {
+			lg.Error("user.auth.pat_user_query_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+		}
+		if u.ID == 0 {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+		}
+		p = &TokenPrincipal{
+			TokenID:   t.ID,
+			UID:       u.ID,
+			Username:  u.Username,
+			Role:      u.Role,
+			Scopes:    strings.Fields(t.Scopes),
+			ExpiresAt: t.ExpiresAt,
+		}
+		wctx, wcancel := context.WithTimeout(ctx, 300*time.Millisecond)
+		if err := PutTokenPrincipalCache(wctx, hash, p, patCacheTTL); err != nil {
+			lg.Warn("user.auth.pat_cache_put_failed", zap.Error(err))
+		}
+		wcancel()
+	}
+	if p.ExpiresAt != nil && time.Now().After(*p.ExpiresAt) {
+		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "auth.token_expired"}
+	}
+
+	tctx, tcancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	touch, err := ShouldTouchToken(tctx, p.TokenID, patTouchInterval)
+	tcancel()
+	if err == nil && touch && a.bus != nil {
+		infra.Publish(a.bus, lg, "TouchAccessToken", struct {
+			TokenID int       `json:"tokenId"`
+			At      time.Time `json:"at"`
+		}{TokenID: p.TokenID, At: time.Now()}, 100*time.Millisecond, zap.Int("token_id", p.TokenID))
+	}
+	return p, nil
+}
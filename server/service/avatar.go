@@ -0,0 +1,98 @@
+package service
+
+import (
+	"ToDoList/server/async"
+	"ToDoList/server/infra"
+	"ToDoList/server/models"
+	"ToDoList/server/storage"
+	"ToDoList/server/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// storeAvatar 校验并处理上传的头像，上传各尺寸、各格式后返回对象 key
+func storeAvatar(ctx context.Context, lg *zap.Logger, fh *multipart.FileHeader) (models.AvatarVariants, error) {
+	if fh.Size > utils.AvatarMaxBytes {
+		lg.Warn("avatar.too_large", zap.Int64("size", fh.Size))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.avatar_too_large", Args: []any{utils.AvatarMaxBytes >> 20}}
+	}
+	f, err := fh.Open()
+	if err != nil {
+		lg.Warn("avatar.open_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.avatar_upload_failed"}
+	}
+	defer f.Close()
+
+	images, err := utils.ProcessAvatar(f)
+	if err != nil {
+		lg.Warn("avatar.process_failed", zap.Error(err))
+		switch {
+		case errors.Is(err, utils.ErrImageTooLarge):
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.avatar_too_large", Args: []any{utils.AvatarMaxBytes >> 20}}
+		case errors.Is(err, utils.ErrImageType):
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.avatar_type_unsupported"}
+		case errors.Is(err, utils.ErrImageDimensions):
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.avatar_dimensions",
+				Args: []any{utils.AvatarMinSide, utils.AvatarMaxPixels / 1_000_000}}
+		}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "user.avatar_store_failed"}
+	}
+
+	id, err := utils.RandomURLToken(6)
+	if err != nil {
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "user.avatar_store_failed"}
+	}
+	prefix := "images/avatars/" + time.Now().Format("20060102_150405") + "_" + id + "/"
+	variants := models.AvatarVariants{}
+	for _, size := range utils.AvatarSizes {
+		variants[size] = map[string]string{}
+		for _, format := range utils.AvatarFormats {
+			f := avatarFiles[format]
+			key := fmt.Sprintf("%s%d.%s", prefix, size, f.ext)
+			b := images[size][format]
+			if err := storage.Default.Put(ctx, key, bytes.NewReader(b), int64(len(b)), f.contentType); err != nil {
+				lg.Error("avatar.put_failed", zap.String("key", key), zap.Error(err))
+				for _, k := range (models.User{AvatarVariants: variants}).AvatarObjectKeys() {
+					_ = storage.Default.Delete(context.Background(), k)
+				}
+				return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "user.avatar_store_failed"}
+			}
+			variants[size][format] = key
+		}
+	}
+	return variants, nil
+}
+
+// avatarFiles 各格式的扩展名与 Content-Type
+var avatarFiles = map[string]struct{ ext, contentType string }{
+	utils.AvatarJPEG: {"jpg", "image/jpeg"},
+}
+
+// avatarMainKey 兼容旧字段 avatar_url，取最大尺寸的 JPEG
+func avatarMainKey(v models.AvatarVariants) string {
+	return v[utils.AvatarSizes[len(utils.AvatarSizes)-1]][utils.AvatarJPEG]
+}
+
+// deleteAvatarObjects 异步删除头像占用的对象
+func (s *UserService) deleteAvatarObjects(lg *zap.Logger, uid int, keys []string) {
+	deleteObjects(s.bus, lg, uid, keys)
+}
+
+// deleteObjects 通过 DeleteCOS 异步删除对象
+func deleteObjects(bus *async.EventBus, lg *zap.Logger, uid int, keys []string) {
+	if bus == nil {
+		return
+	}
+	for _, key := range keys {
+		infra.Publish(bus, lg, "DeleteCOS", struct {
+			Key string `json:"key"`
+		}{Key: key}, 300*time.Millisecond, zap.Int("uid", uid),
+			zap.String("COSKey", key))
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type BoardCard struct {
+	ID        int             `json:"id"`
+	Title     string          `json:"title"`
+	Priority  int             `json:"priority"`
+	Tags      models.TaskTags `json:"tags"`
+	SortOrder int64           `json:"sort_order"`
+	DueAt     *time.Time      `json:"due_at"`
+}
+
+type BoardColumn struct {
+	Key      string      `json:"key"`
+	Name     string      `json:"name"`
+	Category string      `json:"category"`
+	Count    int         `json:"count"`
+	Tasks    []BoardCard `json:"tasks"`
+}
+
+type Board struct {
+	ProjectID int           `json:"project_id"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+// Board 按项目状态分列返回未归档的任务，列按状态顺序排列，列内按排序键倒序
+func (t *TaskService) Board(ctx context.Context, lg *zap.Logger, uid, pid int) (*Board, error) {
+	if _, err := models.GetProjectByID(uid, pid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+		}
+		lg.Error("task.board.project_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	statuses, err := models.ListProjectStatuses(ctx, uid, pid)
+	if err != nil {
+		lg.Error("task.board.status_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	tasks, err := models.ListBoardTasks(ctx, uid, pid)
+	if err != nil {
+		lg.Error("task.board.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "task.list_failed"}
+	}
+	board := &Board{ProjectID: pid, Columns: make([]BoardColumn, len(statuses))}
+	col := make(map[string]int, len(statuses))
+	for i, s := range statuses {
+		board.Columns[i] = BoardColumn{Key: s.Key, Name: s.Name, Category: s.Category, Tasks: []BoardCard{}}
+		col[s.Key] = i
+	}
+	for _, task := range tasks {
+		i, ok := col[task.Status]
+		if !ok {
+			lg.Warn("task.board.unknown_status", zap.Int("task_id", task.ID), zap.String("status", task.Status))
+			continue
+		}
+		board.Columns[i].Tasks = append(board.Columns[i].Tasks, BoardCard{
+			ID:        task.ID,
+			Title:     task.Title,
+			Priority:  task.Priority,
+			Tags:      task.Tags,
+			SortOrder: task.SortOrder,
+			DueAt:     task.DueAt,
+		})
+		board.Columns[i].Count++
+	}
+	return board, nil
+}
+
+type BoardMoveInput struct {
+	TaskID int
+	Status string
+	PrevID int
+	NextID int
+}
+
+// MoveOnBoard 将任务移动到某列的两个相邻任务之间，状态与排序在同一事务中修改
+func (t *TaskService) MoveOnBoard(ctx context.Context, lg *zap.Logger, uid, pid int, in BoardMoveInput) (*UpdateTaskResult, error) {
+	lg.Info("task.board.move.begin", zap.Int("uid", uid), zap.Int("project_id", pid), zap.Int("task_id", in.TaskID),
+		zap.String("status", in.Status), zap.Int("prev_id", in.PrevID), zap.Int("next_id", in.NextID))
+	if in.TaskID == in.PrevID || in.TaskID == in.NextID {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "board.neighbor_invalid"}
+	}
+	if _, err := models.GetProjectByID(uid, pid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+		}
+		lg.Error("task.board.project_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	before, after, err := models.MoveTask(ctx, uid, pid, models.BoardMove{
+		TaskID: in.TaskID,
+		Status: in.Status,
+		PrevID: in.PrevID,
+		NextID: in.NextID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
+		case errors.Is(err, models.ErrStatusNotFound):
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "status.not_found"}
+		case errors.Is(err, models.ErrOrderStale):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "board.stale"}
+		}
+		lg.Error("task.board.move.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	if err := DelTaskDetailCache(ctx, uid, in.TaskID); err != nil {
+		lg.Warn("redis.del.task_detail_failed", zap.Error(err))
+	}
+	if err := DelTaskListCache(ctx, uid, pid); err != nil {
+		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", pid))
+	}
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskUpdate(before, after))
+	after.ContentHtml = resolveAssets(ctx, lg, uid, after.ContentHtml)
+	lg.Info("task.board.move.ok", zap.Int("task_id", in.TaskID), zap.Int64("sort_order", after.SortOrder))
+	return &UpdateTaskResult{Task: after, Affected: 1, UndoToken: token}, nil
+}
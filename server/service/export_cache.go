@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ExportState 数据导出任务状态
+type ExportState struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Progress   int        `json:"progress"`
+	Key        string     `json:"key,omitempty"`
+	Size       int64      `json:"size,omitempty"`
+	Error      string     `json:"error,omitempty"` // 失败原因的错误键
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+func exportKey(uid int) string {
+	return "export:" + strconv.Itoa(uid)
+}
+
+func GetExportState(ctx context.Context, uid int) (*ExportState, error) {
+	b, err := c.Rdb.Get(ctx, exportKey(uid)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var st ExportState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func PutExportState(ctx context.Context, uid int, st *ExportState, ttl time.Duration) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return c.Rdb.Set(ctx, exportKey(uid), b, ttl).Err()
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"ToDoList/server/async"
+	"ToDoList/server/infra"
+	"ToDoList/server/models"
+	"ToDoList/server/storage"
+	"ToDoList/server/utils"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	ExportQueued  = "queued"
+	ExportRunning = "running"
+	ExportDone    = "done"
+	ExportFailed  = "failed"
+
+	exportRetention = 24 * time.Hour   // 导出文件保留时长
+	exportLinkTTL   = 15 * time.Minute // 单个下载链接有效期
+	exportStaleAge  = 30 * time.Minute // 超过该时长仍未完成的任务视为失败，可重新发起
+)
+
+type ExportService struct {
+	bus *async.EventBus
+}
+
+func NewExportService(bus *async.EventBus) *ExportService {
+	return &ExportService{bus: bus}
+}
+
+type ExportView struct {
+	ExportState
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// Start 发起数据导出，同一用户同时只允许一个进行中的任务
+func (s *ExportService) Start(ctx context.Context, lg *zap.Logger, uid int) (*ExportState, error) {
+	lg = lg.With(zap.Int("uid", uid))
+
+	prev, err := GetExportState(ctx, uid)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		lg.Error("export.start.state_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+	if prev != nil {
+		active := prev.Status == ExportQueued || prev.Status == ExportRunning
+		if active && time.Since(prev.CreatedAt) < exportStaleAge {
+			lg.Info("export.start.in_progress", zap.String("export_id", prev.ID))
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "export.in_progress"}
+		}
+		if prev.Key != "" && s.bus != nil {
+			infra.Publish(s.bus, lg, "DeleteCOS", struct {
+				Key string `json:"key"`
+			}{Key: prev.Key}, 300*time.Millisecond, zap.Int("uid", uid),
+				zap.String("COSKey", prev.Key))
+		}
+	}
+
+	id, err := utils.RandomURLToken(12)
+	if err != nil {
+		lg.Error("export.start.random_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	st := &ExportState{ID: id, Status: ExportQueued, CreatedAt: time.Now()}
+	if err := PutExportState(ctx, uid, st, exportRetention); err != nil {
+		lg.Error("export.start.put_state_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+
+	ok := s.bus != nil && infra.Publish(s.bus, lg, "BuildExport", struct {
+		UID      int    `json:"uid"`
+		ExportID string `json:"exportId"`
+	}{UID: uid, ExportID: id}, 300*time.Millisecond, zap.Int("uid", uid), zap.String("export_id", id))
+	if !ok {
+		st.Status = ExportFailed
+		st.Error = "common.busy"
+		_ = PutExportState(ctx, uid, st, exportRetention)
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+	audit(lg, "data_export_requested", zap.String("export_id", id))
+	return st, nil
+}
+
+// Status 查询导出进度，完成后附带限时下载链接
+func (s *ExportService) Status(ctx context.Context, lg *zap.Logger, uid int) (*ExportView, error) {
+	st, err := GetExportState(ctx, uid)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "export.not_found"}
+		}
+		lg.Error("export.status.state_failed", zap.Int("uid", uid), zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+	view := &ExportView{ExportState: *st}
+	if st.Status != ExportDone || st.ExpiresAt == nil {
+		return view, nil
+	}
+	ttl := min(exportLinkTTL, time.Until(*st.ExpiresAt))
+	if ttl <= 0 {
+		return view, nil
+	}
+	link, err := storage.Default.SignedURL(ctx, st.Key, ttl, storage.SignOptions{Filename: exportFileName(uid, st)})
+	if err != nil {
+		lg.Error("export.status.presign_failed", zap.Int("uid", uid), zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "export.link_failed"}
+	}
+	exp := time.Now().Add(ttl)
+	view.DownloadURL = link
+	view.DownloadExpiresAt = &exp
+	return view, nil
+}
+
+// BuildUserExport 异步生成导出 ZIP 并上传到对象存储
+func BuildUserExport(ctx context.Context, lg *zap.Logger, uid int, id string) error {
+	lg = lg.With(zap.Int("uid", uid), zap.String("export_id", id))
+	st, err := GetExportState(ctx, uid)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+	// 已被新的导出任务替代或已完成
+	if st.ID != id || st.Status == ExportDone {
+		return nil
+	}
+
+	progress := func(p int) {
+		st.Status = ExportRunning
+		st.Progress = p
+		if err := PutExportState(ctx, uid, st, exportRetention); err != nil {
+			lg.Warn("export.build.progress_failed", zap.Error(err))
+		}
+	}
+	fail := func(err error) error {
+		lg.Error("export.build.failed", zap.Error(err))
+		st.Status = ExportFailed
+		st.Error = "export.failed"
+		_ = PutExportState(context.Background(), uid, st, exportRetention)
+		return err
+	}
+	progress(5)
+
+	f, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return fail(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := writeExportZip(ctx, lg, f, uid, progress); err != nil {
+		return fail(err)
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fail(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+
+	key := fmt.Sprintf("exports/%d/%s.zip", uid, id)
+	if err := storage.Default.Put(ctx, key, f, size, "application/zip"); err != nil {
+		return fail(err)
+	}
+
+	now := time.Now()
+	exp := now.Add(exportRetention)
+	st.Status = ExportDone
+	st.Progress = 100
+	st.Key = key
+	st.Size = size
+	st.Error = ""
+	st.FinishedAt = &now
+	st.ExpiresAt = &exp
+	if err := PutExportState(ctx, uid, st, exportRetention); err != nil {
+		return err
+	}
+	audit(lg, "data_export_ready", zap.Int64("size", size))
+	return nil
+}
+
+type exportProfile struct {
+	ExportedAt   time.Time                    `json:"exported_at"`
+	User         models.User                  `json:"user"`
+	Identities   []models.UserIdentity        `json:"identities"`
+	AccessTokens []models.PersonalAccessToken `json:"access_tokens"`
+}
+
+func writeExportZip(ctx context.Context, lg *zap.Logger, w io.Writer, uid int, progress func(int)) error {
+	user, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return errors.New("user not found")
+	}
+	identities, err := models.ListIdentities(ctx, uid)
+	if err != nil {
+		return err
+	}
+	tokens, err := models.ListAccessTokens(ctx, uid)
+	if err != nil {
+		return err
+	}
+	projects, err := models.ListAllProjects(ctx, uid)
+	if err != nil {
+		return err
+	}
+	tasks, err := models.ListAllTasks(ctx, uid)
+	if err != nil {
+		return err
+	}
+	progress(20)
+
+	zw := zip.NewWriter(w)
+	if err := writeZipJSON(zw, "profile.json", exportProfile{
+		ExportedAt:   time.Now().UTC(),
+		User:         user,
+		Identities:   identities,
+		AccessTokens: tokens,
+	}); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, "projects.json", projects); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, "tasks.json", tasks); err != nil {
+		return err
+	}
+
+	dirs := make(map[int]string, len(projects))
+	for _, p := range projects {
+		dirs[p.ID] = path.Join("projects", strconv.Itoa(p.ID)+"-"+safeFileName(p.Name))
+	}
+	for i, t := range tasks {
+		dir, ok := dirs[t.ProjectID]
+		if !ok {
+			dir = path.Join("projects", strconv.Itoa(t.ProjectID))
+		}
+		name := path.Join(dir, strconv.Itoa(t.ID)+"-"+safeFileName(t.Title)+".md")
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, taskMarkdown(t)); err != nil {
+			return err
+		}
+		if len(tasks) >= 10 && i%(len(tasks)/10) == 0 {
+			progress(20 + 60*i/len(tasks))
+		}
+	}
+	progress(80)
+
+	if key := strings.TrimSpace(user.AvatarURL); key != "" {
+		b, _, err := storage.ReadAll(ctx, key)
+		if err != nil {
+			// 头像缺失不影响其他数据导出
+			lg.Warn("export.build.avatar_failed", zap.Error(err))
+		} else {
+			fw, err := zw.Create("avatar" + path.Ext(key))
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+	progress(90)
+	return zw.Close()
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// taskMarkdown 任务正文前附带 front matter 元数据
+func taskMarkdown(t models.Task) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %d\n", t.ID)
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(t.Title))
+	fmt.Fprintf(&b, "project_id: %d\n", t.ProjectID)
+	fmt.Fprintf(&b, "status: %s\n", t.Status)
+	fmt.Fprintf(&b, "priority: %d\n", t.Priority)
+	if len(t.Tags) > 0 {
+		quoted := make([]string, len(t.Tags))
+		for i, tag := range t.Tags {
+			quoted[i] = strconv.Quote(tag)
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(quoted, ", "))
+	}
+	if t.DueAt != nil {
+		fmt.Fprintf(&b, "due_at: %s\n", t.DueAt.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "created_at: %s\n", t.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "updated_at: %s\n", t.UpdatedAt.UTC().Format(time.RFC3339))
+	b.WriteString("---\n\n")
+	b.WriteString(t.ContentMD)
+	if !strings.HasSuffix(t.ContentMD, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// safeFileName 去掉压缩包内文件名中的非法字符并限制长度
+func safeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if r := []rune(s); len(r) > 60 {
+		s = string(r[:60])
+	}
+	if s == "" || s == "." || s == ".." {
+		s = "_"
+	}
+	return s
+}
+
+func exportFileName(uid int, st *ExportState) string {
+	return fmt.Sprintf("todolist-export-%d-%s.zip", uid, st.CreatedAt.UTC().Format("20060102"))
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func loginFailUserKey(username string) string {
+	return "login:fail:u:" + strings.ToLower(username)
+}
+func loginFailIPKey(ip string) string {
+	return "login:fail:ip:" + ip
+}
+func loginLockUserKey(username string) string {
+	return "login:lock:u:" + strings.ToLower(username)
+}
+func loginLockIPKey(ip string) string {
+	return "login:lock:ip:" + ip
+}
+func loginNextUserKey(username string) string {
+	return "login:next:u:" + strings.ToLower(username)
+}
+
+// IncrLoginFailures 失败计数 +1，计数窗口从第一次失败开始计算
+func IncrLoginFailures(ctx context.Context, username, ip string, window time.Duration) (userFails int64, ipFails int64, err error) {
+	var userCmd, ipCmd *redis.IntCmd
+	_, err = c.Rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		userCmd = p.Incr(ctx, loginFailUserKey(username))
+		p.ExpireNX(ctx, loginFailUserKey(username), window)
+		ipCmd = p.Incr(ctx, loginFailIPKey(ip))
+		p.ExpireNX(ctx, loginFailIPKey(ip), window)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return userCmd.Val(), ipCmd.Val(), nil
+}
+
+// PutLoginDelay 在 delay 时间内拒绝该用户名的下一次尝试
+func PutLoginDelay(ctx context.Context, username string, delay time.Duration) error {
+	return c.Rdb.Set(ctx, loginNextUserKey(username), 1, delay).Err()
+}
+
+func PutUserLoginLock(ctx context.Context, username string, d time.Duration) error {
+	return c.Rdb.Set(ctx, loginLockUserKey(username), 1, d).Err()
+}
+
+func PutIPLoginLock(ctx context.Context, ip string, d time.Duration) error {
+	return c.Rdb.Set(ctx, loginLockIPKey(ip), 1, d).Err()
+}
+
+// GetLoginBlock 返回用户名或 IP 仍需等待的时长，未被限制时返回 0
+func GetLoginBlock(ctx context.Context, username, ip string) (locked time.Duration, delayed time.Duration, err error) {
+	var userLock, ipLock, next *redis.DurationCmd
+	_, err = c.Rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		userLock = p.PTTL(ctx, loginLockUserKey(username))
+		ipLock = p.PTTL(ctx, loginLockIPKey(ip))
+		next = p.PTTL(ctx, loginNextUserKey(username))
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+	locked = max(userLock.Val(), ipLock.Val(), 0)
+	delayed = max(next.Val(), 0)
+	return locked, delayed, nil
+}
+
+// ClearLoginFailures 清除该用户名的失败计数与锁定
+func ClearLoginFailures(ctx context.Context, username string) error {
+	return c.Rdb.Del(ctx, loginFailUserKey(username), loginLockUserKey(username), loginNextUserKey(username)).Err()
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SignedLink 对象存储的限时访问链接
+type SignedLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func mediaLinkKey(objectKey, filename string) string {
+	return "media:link:" + objectKey + "|" + filename
+}
+
+func GetMediaLink(ctx context.Context, objectKey, filename string) (*SignedLink, error) {
+	b, err := c.Rdb.Get(ctx, mediaLinkKey(objectKey, filename)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var l SignedLink
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func PutMediaLink(ctx context.Context, objectKey, filename string, l *SignedLink, ttl time.Duration) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return c.Rdb.Set(ctx, mediaLinkKey(objectKey, filename), b, ttl).Err()
+}
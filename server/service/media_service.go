@@ -0,0 +1,104 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/storage"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const mediaLinkTTL = 15 * time.Minute // 签名链接有效期
+
+type MediaService struct{}
+
+func NewMediaService() *MediaService {
+	return &MediaService{}
+}
+
+// SignLink 生成对象的限时链接；有效期前半段复用同一链接，浏览器缓存才能命中
+func SignLink(ctx context.Context, lg *zap.Logger, key, filename string) (*SignedLink, error) {
+	rctx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	l, err := GetMediaLink(rctx, key, filename)
+	cancel()
+	if err == nil && time.Until(l.ExpiresAt) > mediaLinkTTL/2 {
+		return l, nil
+	}
+	u, err := storage.Default.SignedURL(ctx, key, mediaLinkTTL, storage.SignOptions{Filename: filename})
+	if err != nil {
+		lg.Error("media.sign_failed", zap.String("key", key), zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "media.link_failed"}
+	}
+	l = &SignedLink{URL: u, ExpiresAt: time.Now().Add(mediaLinkTTL)}
+	wctx, wcancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	if err := PutMediaLink(wctx, key, filename, l, mediaLinkTTL/2); err != nil {
+		lg.Warn("media.link_cache_failed", zap.Error(err))
+	}
+	wcancel()
+	return l, nil
+}
+
+// Avatar 头像限时链接，size 取不小于它的最小尺寸；只能查看自己的头像
+func (s *MediaService) Avatar(ctx context.Context, lg *zap.Logger, viewerUID, ownerUID, size int) (*SignedLink, error) {
+	// 无权访问与不存在返回同样的结果，避免枚举
+	if viewerUID != ownerUID {
+		lg.Info("media.avatar.forbidden", zap.Int("uid", viewerUID), zap.Int("owner", ownerUID))
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "media.not_found"}
+	}
+	u, err := models.GetUserInfoByID(ctx, ownerUID)
+	if err != nil {
+		lg.Error("media.avatar.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	key := pickAvatarVariant(u, size)
+	if u.ID == 0 || key == "" {
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "media.not_found"}
+	}
+	return SignLink(ctx, lg, key, "")
+}
+
+func pickAvatarVariant(u models.User, size int) string {
+	sizes := make([]int, 0, len(u.AvatarVariants))
+	for s := range u.AvatarVariants {
+		sizes = append(sizes, s)
+	}
+	sort.Ints(sizes)
+	if len(sizes) == 0 {
+		return strings.TrimSpace(u.AvatarURL)
+	}
+	chosen := sizes[len(sizes)-1]
+	for _, s := range sizes {
+		if s >= size {
+			chosen = s
+			break
+		}
+	}
+	return u.AvatarVariants[chosen][utils.AvatarJPEG]
+}
+
+// OpenLocal 校验本地存储代理链接的签名后打开对象
+func (s *MediaService) OpenLocal(ctx context.Context, lg *zap.Logger, key, exp, filename, sig string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	local, ok := storage.Default.(*storage.Local)
+	if !ok {
+		return nil, nil, &AppError{Code: utils.ErrCodeNotFound, Key: "media.not_found"}
+	}
+	if err := local.Verify(key, exp, filename, sig); err != nil {
+		lg.Info("media.local.bad_signature", zap.String("key", key))
+		return nil, nil, &AppError{Code: utils.ErrCodeForbidden, Key: "media.link_expired"}
+	}
+	rc, info, err := local.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, &AppError{Code: utils.ErrCodeNotFound, Key: "media.not_found"}
+		}
+		lg.Error("media.local.open_failed", zap.String("key", key), zap.Error(err))
+		return nil, nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	return rc, info, nil
+}
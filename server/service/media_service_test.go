@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"ToDoList/server/models"
+)
+
+func TestPickAvatarVariant(t *testing.T) {
+	full := models.User{AvatarURL: "a/512.jpg", AvatarVariants: models.AvatarVariants{
+		64:  {"jpeg": "a/64.jpg"},
+		256: {"jpeg": "a/256.jpg"},
+		512: {"jpeg": "a/512.jpg"},
+	}}
+	partial := models.User{AvatarVariants: models.AvatarVariants{64: {"jpeg": "b/64.jpg"}, 256: {"jpeg": "b/256.jpg"}}}
+	cases := []struct {
+		name string
+		user models.User
+		size int
+		want string
+	}{
+		{"exact", full, 256, "a/256.jpg"},
+		{"rounds size up", full, 100, "a/256.jpg"},
+		{"smallest", full, 1, "a/64.jpg"},
+		{"larger than all", full, 2048, "a/512.jpg"},
+		{"missing sizes fall back to largest", partial, 512, "b/256.jpg"},
+		{"no variants", models.User{AvatarURL: " old/avatar.png "}, 64, "old/avatar.png"},
+		{"no avatar", models.User{}, 64, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pickAvatarVariant(tc.user, tc.size); got != tc.want {
+				t.Fatalf("pickAvatarVariant(%d) = %q, want %q", tc.size, got, tc.want)
+			}
+		})
+	}
+}
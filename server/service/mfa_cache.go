@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func totpStepKey(uid int, step int64) string {
+	return fmt.Sprintf("mfa:step:%d:%d", uid, step)
+}
+
+// MarkTOTPStep 记录已使用的时间步，返回 false 表示该验证码已被用过
+func MarkTOTPStep(ctx context.Context, uid int, step int64) (bool, error) {
+	return c.Rdb.SetNX(ctx, totpStepKey(uid, step), 1, 3*time.Minute).Result()
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+type TOTPEnrollResult struct {
+	Secret string
+	URI    string
+}
+
+// EnrollTOTP 生成待确认的 TOTP 密钥，需调用 ConfirmTOTP 后才生效
+func (s *UserService) EnrollTOTP(ctx context.Context, lg *zap.Logger, uid int) (*TOTPEnrollResult, error) {
+	lg = lg.With(zap.Int("uid", uid))
+	lg.Info("mfa.enroll.begin")
+
+	user, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil {
+		lg.Error("mfa.enroll.query_user_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if user.ID == 0 {
+		lg.Warn("mfa.enroll.user_not_found")
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+	}
+	if user.TOTPEnabled {
+		lg.Info("mfa.enroll.already_enabled")
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "mfa.already_enabled"}
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		lg.Error("mfa.enroll.secret_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	affected, err := models.SetTOTPSecret(ctx, uid, secret)
+	if err != nil {
+		lg.Error("mfa.enroll.save_secret_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if affected == 0 {
+		lg.Info("mfa.enroll.enabled_concurrently")
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "mfa.already_enabled"}
+	}
+
+	lg.Info("mfa.enroll.success")
+	return &TOTPEnrollResult{
+		Secret: secret,
+		URI:    s.totp.ProvisioningURI(config.MFAIssuer, user.Username, secret),
+	}, nil
+}
+
+type TOTPConfirmResult struct {
+	RecoveryCodes []string
+}
+
+// ConfirmTOTP 校验首个验证码后开启两步验证，恢复码明文只返回这一次
+func (s *UserService) ConfirmTOTP(ctx context.Context, lg *zap.Logger, uid int, code string) (*TOTPConfirmResult, error) {
+	lg = lg.With(zap.Int("uid", uid))
+	lg.Info("mfa.confirm.begin")
+
+	user, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil {
+		lg.Error("mfa.confirm.query_user_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if user.ID == 0 {
+		lg.Warn("mfa.confirm.user_not_found")
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+	}
+	if user.TOTPEnabled {
+		lg.Info("mfa.confirm.already_enabled")
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "mfa.already_enabled"}
+	}
+	if user.TOTPSecret == "" {
+		lg.Warn("mfa.confirm.not_enrolled")
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "mfa.enroll_first"}
+	}
+	if err := s.verifyTOTP(ctx, lg, uid, user.TOTPSecret, code); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		rc, err := newRecoveryCode()
+		if err != nil {
+			lg.Error("mfa.confirm.recovery_code_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+		}
+		codes[i] = rc
+		hashes[i] = hashRecoveryCode(uid, rc)
+	}
+	if err := models.EnableTOTP(ctx, uid, hashes); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			lg.Info("mfa.confirm.enabled_concurrently")
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "mfa.already_enabled"}
+		}
+		lg.Error("mfa.confirm.enable_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "mfa.enable_failed"}
+	}
+	lg.Info("mfa.confirm.success")
+	return &TOTPConfirmResult{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP 关闭两步验证，必须提供当前有效的验证码
+func (s *UserService) DisableTOTP(ctx context.Context, lg *zap.Logger, uid int, code string) error {
+	lg = lg.With(zap.Int("uid", uid))
+	lg.Info("mfa.disable.begin")
+
+	user, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil {
+		lg.Error("mfa.disable.query_user_failed", zap.Error(err))
+		return &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if user.ID == 0 {
+		lg.Warn("mfa.disable.user_not_found")
+		return &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+	}
+	if !user.TOTPEnabled {
+		lg.Info("mfa.disable.not_enabled")
+		return &AppError{Code: utils.ErrCodeValidation, Key: "mfa.not_enabled"}
+	}
+	if err := s.verifyTOTP(ctx, lg, uid, user.TOTPSecret, code); err != nil {
+		return err
+	}
+	if err := models.DisableTOTP(ctx, uid); err != nil {
+		lg.Error("mfa.disable.db_failed", zap.Error(err))
+		return &AppError{Code: utils.ErrCodeInternalServer, Key: "mfa.disable_failed"}
+	}
+	lg.Info("mfa.disable.success")
+	return nil
+}
+
+// LoginMFA 登录第二步：校验挑战令牌与验证码（或恢复码），签发正式令牌
+func (s *UserService) LoginMFA(ctx context.Context, lg *zap.Logger, mfaToken, code, ip string) (*LoginResult, error) {
+	claims, err := utils.ParseMFAToken(mfaToken)
+	if err != nil {
+		lg.Warn("login.mfa.token_invalid", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "mfa.challenge_expired"}
+	}
+	lg = lg.With(zap.Int("uid", claims.UID))
+	lg.Info("login.mfa.begin")
+
+	ctxRedis, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	used, err := ExistsJti(ctxRedis, claims.RegisteredClaims.ID)
+	cancel()
+	if err != nil {
+		lg.Warn("login.mfa.ExistsJti_redis_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+	if used {
+		lg.Warn("login.mfa.token_reused")
+		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "mfa.challenge_expired"}
+	}
+
+	user, err := models.GetUserInfoByID(ctx, claims.UID)
+	if err != nil {
+		lg.Error("login.mfa.query_user_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	if user.ID == 0 {
+		lg.Warn("login.mfa.user_not_found")
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+	}
+	if user.TokenVersion != claims.Ver || !user.TOTPEnabled {
+		lg.Warn("login.mfa.state_changed")
+		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "mfa.challenge_expired"}
+	}
+	if err := s.checkLoginAllowed(ctx, lg, user.Username, ip); err != nil {
+		return nil, err
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == s.totp.Digits && isDigits(code) {
+		if err := s.verifyTOTP(ctx, lg, user.ID, user.TOTPSecret, code); err != nil {
+			if errors.Is(err, errTOTPMismatch) {
+				s.recordLoginFailure(ctx, lg, user.Username, ip, "mfa_code_invalid")
+			}
+			return nil, err
+		}
+	} else {
+		ok, err := models.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(user.ID, code))
+		if err != nil {
+			lg.Error("login.mfa.recovery_code_query_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+		}
+		if !ok {
+			lg.Warn("login.mfa.recovery_code_invalid")
+			s.recordLoginFailure(ctx, lg, user.Username, ip, "recovery_code_invalid")
+			return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "mfa.code_invalid"}
+		}
+		audit(lg, "recovery_code_used", zap.String("ip", ip))
+	}
+
+	// 挑战令牌只能使用一次
+	ctxRedis, cancel = context.WithTimeout(ctx, 300*time.Millisecond)
+	if err := PutJti(ctxRedis, claims.RegisteredClaims.ID, claims.RegisteredClaims.ExpiresAt.Time); err != nil {
+		lg.Warn("login.mfa.burn_token_failed", zap.Error(err))
+	}
+	cancel()
+
+	token, exp, err := utils.GenerateAccessToken(user.ID, user.Username, user.Role, user.TokenVersion)
+	if err != nil {
+		lg.Error("login.mfa.jwt_issue_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "auth.token_issue_failed"}
+	}
+	s.clearLoginFailures(ctx, lg, user.Username)
+	canceled := s.cancelPendingDeletion(ctx, lg, user)
+	audit(lg, "login_succeeded", zap.String("ip", ip), zap.Bool("mfa", true))
+	lg.Info("login.mfa.success", zap.Time("access_exp", exp))
+	return &LoginResult{
+		AccessToken:      token,
+		AccessExpireAt:   exp,
+		DeletionCanceled: canceled,
+	}, nil
+}
+
+// errTOTPMismatch 验证码与密钥不匹配；只有这种情况计入登录失败次数
+var errTOTPMismatch = &AppError{Code: utils.ErrCodeAuthFailed, Key: "mfa.code_invalid"}
+
+func (s *UserService) verifyTOTP(ctx context.Context, lg *zap.Logger, uid int, secret, code string) error {
+	step, ok := s.totp.Validate(secret, code)
+	if !ok {
+		lg.Warn("mfa.code_invalid")
+		return errTOTPMismatch
+	}
+	ctxRedis, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	fresh, err := MarkTOTPStep(ctxRedis, uid, step)
+	if err != nil {
+		lg.Warn("mfa.mark_step_redis_failed", zap.Error(err))
+		return &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if !fresh {
+		lg.Warn("mfa.code_replayed", zap.Int64("step", step))
+		return &AppError{Code: utils.ErrCodeAuthFailed, Key: "mfa.code_reused"}
+	}
+	return nil
+}
+
+func isDigits(code string) bool {
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCode 形如 a1b2c-3d4e5
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	h := hex.EncodeToString(buf)
+	return h[:5] + "-" + h[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// hashRecoveryCode 用服务端密钥派生出每个用户的 HMAC 密钥，不同用户的相同恢复码得到不同哈希
+func hashRecoveryCode(uid int, code string) string {
+	kdf := hmac.New(sha256.New, []byte(config.MFARecoveryKey))
+	kdf.Write([]byte("recovery-code:" + strconv.Itoa(uid)))
+	mac := hmac.New(sha256.New, kdf.Sum(nil))
+	mac.Write([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
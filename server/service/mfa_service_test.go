@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ToDoList/server/utils"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 验证码错误才计入登录失败；防重放缓存不可用时返回 common.retry_later，不算作错误的验证码
+func TestVerifyTOTPErrors(t *testing.T) {
+	old := c.Rdb
+	c.Rdb = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 50 * time.Millisecond})
+	t.Cleanup(func() {
+		c.Rdb.Close()
+		c.Rdb = old
+	})
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := utils.DefaultTOTP.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong := "000000"
+	for _, ok := utils.DefaultTOTP.Validate(secret, wrong); ok; _, ok = utils.DefaultTOTP.Validate(secret, wrong) {
+		wrong = wrong[1:] + "1"
+	}
+	s := NewUserService(nil)
+
+	err = s.verifyTOTP(context.Background(), zap.NewNop(), 1, secret, wrong)
+	if !errors.Is(err, errTOTPMismatch) {
+		t.Fatalf("wrong code: err = %v, want errTOTPMismatch", err)
+	}
+
+	err = s.verifyTOTP(context.Background(), zap.NewNop(), 1, secret, valid)
+	var ae *AppError
+	if !errors.As(err, &ae) || ae.Key != "common.retry_later" || ae.Code != utils.ErrCodeInternalServer {
+		t.Fatalf("redis down: err = %v, want common.retry_later", err)
+	}
+	if errors.Is(err, errTOTPMismatch) {
+		t.Fatal("redis failure counted as code mismatch")
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// OIDCState 发起授权时暂存的 PKCE 与 nonce，回调时一次性取出
+type OIDCState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+func oidcStateKey(state string) string {
+	return "oidc:state:" + state
+}
+
+func PutOIDCState(ctx context.Context, state string, st OIDCState, ttl time.Duration) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return c.Rdb.Set(ctx, oidcStateKey(state), b, ttl).Err()
+}
+
+func TakeOIDCState(ctx context.Context, state string) (*OIDCState, error) {
+	b, err := c.Rdb.GetDel(ctx, oidcStateKey(state)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var st OIDCState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"ToDoList/server/async"
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var usernameUnsafeRe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+type OIDCService struct {
+	bus       *async.EventBus
+	users     *UserService
+	providers map[string]*utils.OIDCClient
+	cfgs      map[string]config.OIDCProviderConfig
+	store     oidcUserStore
+}
+
+// oidcUserStore 关联本地用户时用到的数据访问，测试中替换为内存实现
+type oidcUserStore struct {
+	getIdentity         func(ctx context.Context, provider, subject string) (models.UserIdentity, error)
+	addIdentity         func(ctx context.Context, ident models.UserIdentity) (models.UserIdentity, error)
+	userByID            func(ctx context.Context, uid int) (models.User, error)
+	userByEmail         func(ctx context.Context, email string) (models.User, error)
+	addUserWithIdentity func(ctx context.Context, user models.User, ident models.UserIdentity) (models.User, error)
+}
+
+var modelsOIDCUserStore = oidcUserStore{
+	getIdentity:         models.GetIdentity,
+	addIdentity:         models.AddIdentity,
+	userByID:            models.GetUserInfoByID,
+	userByEmail:         models.GetUserInfoByEmail,
+	addUserWithIdentity: models.AddUserWithIdentity,
+}
+
+func NewOIDCService(bus *async.EventBus, users *UserService, cfg *config.OIDCConfig) *OIDCService {
+	o := &OIDCService{
+		bus:       bus,
+		users:     users,
+		providers: make(map[string]*utils.OIDCClient),
+		cfgs:      make(map[string]config.OIDCProviderConfig),
+		store:     modelsOIDCUserStore,
+	}
+	if cfg != nil {
+		for _, p := range cfg.Providers {
+			o.providers[p.Name] = utils.NewOIDCClient(p)
+			o.cfgs[p.Name] = p
+		}
+	}
+	return o
+}
+
+// Provider 返回指定提供方的客户端，便于替换 HTTP 客户端对接本地模拟提供方
+func (o *OIDCService) Provider(name string) *utils.OIDCClient {
+	return o.providers[name]
+}
+
+// BeginLogin 生成 state、nonce 与 PKCE，返回跳转到提供方的授权地址
+func (o *OIDCService) BeginLogin(ctx context.Context, lg *zap.Logger, provider string) (string, error) {
+	lg = lg.With(zap.String("provider", provider))
+	client, ok := o.providers[provider]
+	if !ok {
+		lg.Warn("oidc.begin.provider_unknown")
+		return "", &AppError{Code: utils.ErrCodeNotFound, Key: "oidc.provider_unknown"}
+	}
+
+	state, err := utils.RandomURLToken(24)
+	if err != nil {
+		lg.Error("oidc.begin.state_failed", zap.Error(err))
+		return "", &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	nonce, err := utils.RandomURLToken(24)
+	if err != nil {
+		lg.Error("oidc.begin.nonce_failed", zap.Error(err))
+		return "", &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	verifier, challenge, err := utils.NewPKCE()
+	if err != nil {
+		lg.Error("oidc.begin.pkce_failed", zap.Error(err))
+		return "", &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+
+	authURL, err := client.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		lg.Error("oidc.begin.discovery_failed", zap.Error(err))
+		return "", &AppError{Code: utils.ErrCodeInternalServer, Key: "oidc.provider_unavailable"}
+	}
+
+	ctxRedis, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := PutOIDCState(ctxRedis, state, OIDCState{Provider: provider, Verifier: verifier, Nonce: nonce}, oidcStateTTL); err != nil {
+		lg.Error("oidc.begin.put_state_failed", zap.Error(err))
+		return "", &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+	lg.Info("oidc.begin.success")
+	return authURL, nil
+}
+
+// FinishLogin 处理回调：换取并校验 ID Token，按外部身份或已验证邮箱关联本地用户后签发令牌
+func (o *OIDCService) FinishLogin(ctx context.Context, lg *zap.Logger, provider, state, code, ip string) (*LoginResult, error) {
+	lg = lg.With(zap.String("provider", provider))
+	lg.Info("oidc.callback.begin")
+	client, ok := o.providers[provider]
+	if !ok {
+		lg.Warn("oidc.callback.provider_unknown")
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "oidc.provider_unknown"}
+	}
+
+	ctxRedis, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	st, err := TakeOIDCState(ctxRedis, state)
+	cancel()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			lg.Warn("oidc.callback.state_unknown")
+			return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "oidc.state_expired"}
+		}
+		lg.Error("oidc.callback.get_state_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+	if st.Provider != provider {
+		lg.Warn("oidc.callback.state_provider_mismatch", zap.String("state_provider", st.Provider))
+		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "oidc.state_expired"}
+	}
+
+	claims, err := client.Exchange(ctx, code, st.Verifier, st.Nonce)
+	if err != nil {
+		lg.Warn("oidc.callback.exchange_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "oidc.verify_failed"}
+	}
+	lg = lg.With(zap.String("subject", claims.Subject))
+
+	user, err := o.resolveUser(ctx, lg, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+	return o.users.issueLogin(ctx, lg, user, ip, "oidc:"+provider)
+}
+
+// resolveUser 已绑定则直接使用；否则按已验证邮箱关联已有用户，必要时自动注册
+func (o *OIDCService) resolveUser(ctx context.Context, lg *zap.Logger, provider string, claims *utils.OIDCClaims) (models.User, error) {
+	ident, err := o.store.getIdentity(ctx, provider, claims.Subject)
+	if err != nil {
+		lg.Error("oidc.identity_query_failed", zap.Error(err))
+		return models.User{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	if ident.ID != 0 {
+		user, err := o.store.userByID(ctx, ident.UserID)
+		if err != nil {
+			lg.Error("oidc.user_query_failed", zap.Error(err))
+			return models.User{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+		}
+		if user.ID == 0 {
+			lg.Warn("oidc.linked_user_missing", zap.Int("uid", ident.UserID))
+			return models.User{}, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+		}
+		return user, nil
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.IsEmailVerified() {
+		lg.Warn("oidc.email_unverified")
+		return models.User{}, &AppError{Code: utils.ErrCodeAuthFailed, Key: "oidc.email_unverified"}
+	}
+	newIdent := models.UserIdentity{Provider: provider, Subject: claims.Subject, Email: email}
+
+	user, err := o.store.userByEmail(ctx, email)
+	if err != nil {
+		lg.Error("oidc.email_query_failed", zap.Error(err))
+		return models.User{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
+	}
+	if user.ID != 0 {
+		newIdent.UserID = user.ID
+		if _, err := o.store.addIdentity(ctx, newIdent); err != nil && !errors.Is(err, models.ErrIdentityExists) {
+			lg.Error("oidc.link_failed", zap.Error(err))
+			return models.User{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "oidc.link_failed"}
+		}
+		audit(lg, "identity_linked", zap.Int("uid", user.ID), zap.String("provider", provider))
+		return user, nil
+	}
+
+	if !o.cfgs[provider].AllowSignup {
+		lg.Info("oidc.signup_disabled")
+		return models.User{}, &AppError{Code: utils.ErrCodeNotFound, Key: "oidc.account_not_found"}
+	}
+	return o.signup(ctx, lg, claims, email, newIdent)
+}
+
+func (o *OIDCService) signup(ctx context.Context, lg *zap.Logger, claims *utils.OIDCClaims, email string, ident models.UserIdentity) (models.User, error) {
+	// 外部账户默认不可用密码登录，用户可之后在个人资料中设置密码
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		lg.Error("oidc.signup.random_failed", zap.Error(err))
+		return models.User{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(random)), bcrypt.DefaultCost)
+	if err != nil {
+		lg.Error("oidc.signup.password_hash_failed", zap.Error(err))
+		return models.User{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(email, "@", 2)[0]
+	}
+	base = usernameUnsafeRe.ReplaceAllString(base, "")
+	if len(base) < 2 {
+		base = "user" + base
+	}
+	if len(base) > 58 {
+		base = base[:58]
+	}
+
+	for i := 0; i < 5; i++ {
+		username := base
+		if i > 0 {
+			suffix := make([]byte, 2)
+			_, _ = rand.Read(suffix)
+			username = base + "_" + hex.EncodeToString(suffix)
+		}
+		created, err := o.store.addUserWithIdentity(ctx, models.User{
+			Email:    email,
+			Password: string(hash),
+			Username: username,
+		}, ident)
+		if err == nil {
+			audit(lg, "identity_signup", zap.Int("uid", created.ID), zap.String("provider", ident.Provider))
+			return created, nil
+		}
+		if !errors.Is(err, models.ErrUserExists) {
+			lg.Error("oidc.signup.insert_failed", zap.Error(err))
+			return models.User{}, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.save_failed"}
+		}
+	}
+	lg.Warn("oidc.signup.username_exhausted", zap.String("base", base))
+	return models.User{}, &AppError{Code: utils.ErrCodeConflict, Key: "user.username_exists"}
+}
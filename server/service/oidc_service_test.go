@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// fakeOIDCUsers 内存中的用户与外部身份
+type fakeOIDCUsers struct {
+	users  []models.User
+	idents []models.UserIdentity
+}
+
+func (f *fakeOIDCUsers) store() oidcUserStore {
+	return oidcUserStore{
+		getIdentity: func(ctx context.Context, provider, subject string) (models.UserIdentity, error) {
+			for _, i := range f.idents {
+				if i.Provider == provider && i.Subject == subject {
+					return i, nil
+				}
+			}
+			return models.UserIdentity{}, nil
+		},
+		addIdentity: func(ctx context.Context, ident models.UserIdentity) (models.UserIdentity, error) {
+			ident.ID = len(f.idents) + 1
+			f.idents = append(f.idents, ident)
+			return ident, nil
+		},
+		userByID: func(ctx context.Context, uid int) (models.User, error) {
+			for _, u := range f.users {
+				if u.ID == uid {
+					return u, nil
+				}
+			}
+			return models.User{}, nil
+		},
+		userByEmail: func(ctx context.Context, email string) (models.User, error) {
+			for _, u := range f.users {
+				if u.Email == email {
+					return u, nil
+				}
+			}
+			return models.User{}, nil
+		},
+		addUserWithIdentity: func(ctx context.Context, user models.User, ident models.UserIdentity) (models.User, error) {
+			user.ID = 100 + len(f.users)
+			f.users = append(f.users, user)
+			ident.UserID = user.ID
+			f.idents = append(f.idents, ident)
+			return user, nil
+		},
+	}
+}
+
+// newOIDCTestProvider 模拟提供方，token 端点签发带 claims 的 ID Token；PKCE 等协议细节由 utils 中的测试覆盖
+func newOIDCTestProvider(t *testing.T, claims jwt.MapClaims) *httptest.Server {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"jwks_uri":               srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		c := jwt.MapClaims{
+			"iss":   srv.URL,
+			"aud":   "todo-app",
+			"exp":   time.Now().Add(5 * time.Minute).Unix(),
+			"nonce": "nonce-1",
+		}
+		for k, v := range claims {
+			c[k] = v
+		}
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		tok.Header["kid"] = "k1"
+		raw, err := tok.SignedString(key)
+		if err != nil {
+			t.Errorf("sign id_token: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": raw})
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOIDCResolveUser(t *testing.T) {
+	existing := models.User{ID: 7, Email: "ann@example.com", Username: "ann"}
+	cases := []struct {
+		name        string
+		claims      jwt.MapClaims
+		allowSignup bool
+		idents      []models.UserIdentity
+		wantUID     int
+		wantKey     string
+		wantLinked  bool
+		wantCreated bool
+	}{
+		{
+			name:    "linked identity",
+			claims:  jwt.MapClaims{"sub": "s-1"},
+			idents:  []models.UserIdentity{{ID: 1, UserID: 7, Provider: "mock", Subject: "s-1"}},
+			wantUID: 7,
+		},
+		{
+			name:       "verified email links existing account",
+			claims:     jwt.MapClaims{"sub": "s-2", "email": "Ann@Example.com", "email_verified": true},
+			wantUID:    7,
+			wantLinked: true,
+		},
+		{
+			name:       "verified as string",
+			claims:     jwt.MapClaims{"sub": "s-2", "email": "ann@example.com", "email_verified": "true"},
+			wantUID:    7,
+			wantLinked: true,
+		},
+		{
+			name:    "unverified email refused",
+			claims:  jwt.MapClaims{"sub": "s-2", "email": "ann@example.com", "email_verified": false},
+			wantKey: "oidc.email_unverified",
+		},
+		{
+			name:        "unverified email refused even with signup",
+			claims:      jwt.MapClaims{"sub": "s-2", "email": "new@example.com"},
+			allowSignup: true,
+			wantKey:     "oidc.email_unverified",
+		},
+		{
+			name:        "signup allowed",
+			claims:      jwt.MapClaims{"sub": "s-3", "email": "new@example.com", "email_verified": true, "preferred_username": "new user!"},
+			allowSignup: true,
+			wantCreated: true,
+		},
+		{
+			name:    "signup denied",
+			claims:  jwt.MapClaims{"sub": "s-3", "email": "new@example.com", "email_verified": true},
+			wantKey: "oidc.account_not_found",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newOIDCTestProvider(t, tc.claims)
+			o := NewOIDCService(nil, nil, &config.OIDCConfig{Providers: []config.OIDCProviderConfig{{
+				Name:        "mock",
+				Issuer:      srv.URL,
+				ClientID:    "todo-app",
+				RedirectURL: "http://app.local/callback",
+				AllowSignup: tc.allowSignup,
+			}}})
+			fake := &fakeOIDCUsers{users: []models.User{existing}, idents: tc.idents}
+			o.store = fake.store()
+			client := o.Provider("mock")
+			client.HTTP = srv.Client()
+
+			claims, err := client.Exchange(context.Background(), "code", "verifier", "nonce-1")
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			user, err := o.resolveUser(context.Background(), zap.NewNop(), "mock", claims)
+			if tc.wantKey != "" {
+				var ae *AppError
+				if !errors.As(err, &ae) || ae.Key != tc.wantKey {
+					t.Fatalf("err = %v, want %s", err, tc.wantKey)
+				}
+				if len(fake.users) != 1 || len(fake.idents) != len(tc.idents) {
+					t.Fatalf("refused login changed accounts: users %v, identities %v", fake.users, fake.idents)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveUser: %v", err)
+			}
+
+			switch {
+			case tc.wantCreated:
+				if len(fake.users) != 2 || user.ID != fake.users[1].ID {
+					t.Fatalf("user = %+v, want a new account", user)
+				}
+				if user.Email != "new@example.com" || user.Username != "newuser" || user.Password == "" {
+					t.Fatalf("created user = %+v", user)
+				}
+			default:
+				if user.ID != tc.wantUID || len(fake.users) != 1 {
+					t.Fatalf("user = %+v, want uid %d without signup", user, tc.wantUID)
+				}
+			}
+			added := fake.idents[len(tc.idents):]
+			if tc.wantLinked || tc.wantCreated {
+				if len(added) != 1 || added[0].UserID != user.ID || added[0].Subject != claims.Subject || added[0].Email != user.Email {
+					t.Fatalf("identities added = %+v", added)
+				}
+			} else if len(added) != 0 {
+				t.Fatalf("identities added = %+v, want none", added)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+func prefsKey(uid int) string {
+	return "prefs:" + strconv.Itoa(uid)
+}
+
+func GetPreferencesCache(ctx context.Context, uid int) (*Preferences, error) {
+	b, err := c.Rdb.Get(ctx, prefsKey(uid)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var p Preferences
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func PutPreferencesCache(ctx context.Context, uid int, p *Preferences, ttl time.Duration) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.Rdb.Set(ctx, prefsKey(uid), b, ttl).Err()
+}
+
+func DelPreferencesCache(ctx context.Context, uid int) error {
+	return c.Rdb.Del(ctx, prefsKey(uid)).Err()
+}
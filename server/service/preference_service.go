@@ -0,0 +1,187 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const prefsCacheTTL = time.Hour
+
+// Preferences 用户偏好视图
+type Preferences struct {
+	Timezone string `json:"timezone"`
+	models.UserPreferences
+}
+
+func (p *Preferences) Location() *time.Location {
+	return utils.UserLocation(p.Timezone)
+}
+
+func defaultPreferences() *Preferences {
+	return &Preferences{
+		Timezone: utils.DefaultTimezone,
+		UserPreferences: models.UserPreferences{
+			Locale:             utils.DefaultLocale,
+			WeekStart:          1,
+			DefaultPriority:    3,
+			ReminderEnabled:    true,
+			AllDayReminderTime: "09:00",
+		},
+	}
+}
+
+func preferencesOf(u models.User) *Preferences {
+	return &Preferences{Timezone: u.Timezone, UserPreferences: u.UserPreferences}
+}
+
+// loadPreferences 读取用户偏好，出错时回退默认值，不阻断业务
+func loadPreferences(ctx context.Context, lg *zap.Logger, uid int) *Preferences {
+	rctx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	p, err := GetPreferencesCache(rctx, uid)
+	cancel()
+	if err == nil {
+		return p
+	}
+	u, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil || u.ID == 0 {
+		lg.Warn("prefs.load_failed", zap.Int("uid", uid), zap.Error(err))
+		return defaultPreferences()
+	}
+	p = preferencesOf(u)
+	wctx, wcancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	if err := PutPreferencesCache(wctx, uid, p, prefsCacheTTL); err != nil {
+		lg.Warn("prefs.cache_put_failed", zap.Int("uid", uid), zap.Error(err))
+	}
+	wcancel()
+	return p
+}
+
+// UserLocale 用户偏好语言
+func UserLocale(ctx context.Context, lg *zap.Logger, uid int) string {
+	return loadPreferences(ctx, lg, uid).Locale
+}
+
+func (s *UserService) GetPreferences(ctx context.Context, lg *zap.Logger, uid int) (*Preferences, error) {
+	u, err := models.GetUserInfoByID(ctx, uid)
+	if err != nil {
+		lg.Error("prefs.get.query_failed", zap.Int("uid", uid), zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "prefs.get_failed"}
+	}
+	if u.ID == 0 {
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"}
+	}
+	return preferencesOf(u), nil
+}
+
+type UpdatePreferencesInput struct {
+	Timezone           *string
+	Locale             *string
+	WeekStart          *int
+	DefaultPriority    *int
+	DefaultProjectID   *int // 0 表示清除
+	ReminderEnabled    *bool
+	ReminderMinutes    *int
+	AllDayReminderTime *string
+}
+
+func (s *UserService) UpdatePreferences(ctx context.Context, lg *zap.Logger, uid int, in UpdatePreferencesInput) (*Preferences, error) {
+	lg = lg.With(zap.Int("uid", uid))
+	update := map[string]interface{}{}
+	if in.Timezone != nil {
+		tz := strings.TrimSpace(*in.Timezone)
+		if _, err := utils.LoadLocation(tz); err != nil {
+			lg.Warn("prefs.update.timezone_invalid", zap.String("timezone", tz))
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "prefs.timezone_invalid"}
+		}
+		update["timezone"] = tz
+	}
+	if in.Locale != nil {
+		if !utils.IsSupportedLocale(*in.Locale) {
+			lg.Warn("prefs.update.locale_invalid", zap.String("locale", *in.Locale))
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "prefs.locale_unsupported"}
+		}
+		update["locale"] = *in.Locale
+	}
+	if in.WeekStart != nil {
+		if *in.WeekStart < 0 || *in.WeekStart > 6 {
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "prefs.week_start_invalid"}
+		}
+		update["week_start"] = *in.WeekStart
+	}
+	if in.DefaultPriority != nil {
+		if *in.DefaultPriority < 1 || *in.DefaultPriority > 5 {
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.priority_range"}
+		}
+		update["default_priority"] = *in.DefaultPriority
+	}
+	if in.DefaultProjectID != nil {
+		switch {
+		case *in.DefaultProjectID < 0:
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "project.id_invalid"}
+		case *in.DefaultProjectID == 0:
+			update["default_project_id"] = nil
+		default:
+			update["default_project_id"] = *in.DefaultProjectID
+		}
+	}
+	if in.ReminderEnabled != nil {
+		update["reminder_enabled"] = *in.ReminderEnabled
+	}
+	if in.ReminderMinutes != nil {
+		if *in.ReminderMinutes < 0 || *in.ReminderMinutes > 7*24*60 {
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "prefs.reminder_minutes_invalid"}
+		}
+		update["reminder_minutes"] = *in.ReminderMinutes
+	}
+	if in.AllDayReminderTime != nil {
+		if _, err := time.Parse("15:04", *in.AllDayReminderTime); err != nil {
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "prefs.reminder_time_invalid"}
+		}
+		update["all_day_reminder_time"] = *in.AllDayReminderTime
+	}
+	if len(update) == 0 {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "common.no_fields"}
+	}
+
+	u, err := models.UpdatePreferences(ctx, uid, update)
+	if err != nil {
+		if errors.Is(err, models.ErrProjectNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+		}
+		lg.Error("prefs.update.db_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	ctxRedis, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := DelPreferencesCache(ctxRedis, uid); err != nil {
+		lg.Warn("prefs.update.del_cache_failed", zap.Error(err))
+	}
+	lg.Info("prefs.update.success", zap.Int("fields", len(update)))
+	return preferencesOf(u), nil
+}
+
+// reminderAt 按用户偏好计算提醒时间；关闭提醒时返回 nil
+func reminderAt(due time.Time, dateOnly bool, p *Preferences) *time.Time {
+	if !p.ReminderEnabled {
+		return nil
+	}
+	var at time.Time
+	if dateOnly {
+		loc := p.Location()
+		hm, err := time.Parse("15:04", p.AllDayReminderTime)
+		if err != nil {
+			hm = time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)
+		}
+		y, m, d := due.In(loc).Date()
+		at = time.Date(y, m, d, hm.Hour(), hm.Minute(), 0, 0, loc)
+	} else {
+		at = due.Add(-time.Duration(p.ReminderMinutes) * time.Minute)
+	}
+	return &at
+}
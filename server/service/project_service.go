@@ -30,6 +30,8 @@ type ProjectProfile struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	CreatedAt time.Time `json:"created_at"`
 	SortOrder int64     `json:"sort_order"`
+	// TaskCounts 待办与已完成不含已归档的任务
+	TaskCounts models.TaskCounts `json:"task_counts"`
 }
 type ProjectSummary struct {
 	ID        int       `json:"id"`
@@ -50,27 +52,33 @@ func (p *ProjectService) GetProjectByID(ctx context.Context, lg *zap.Logger, uid
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
 		lg.Warn("project.GetProjectByID.invalid_project_id")
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "非法项目id"}
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "project.id_invalid"}
 	}
 
 	project, err := models.GetProjectInfoByIDAndUserID(ctx, id, uid)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Info("project.GetProjectByID.project_not_found", zap.Int("project_id", id))
-			return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "项目不存在"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
 		}
 		lg.Error("project.GetProjectByID.query_project_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	counts, err := models.CountProjectTasks(ctx, uid, id)
+	if err != nil {
+		lg.Error("project.GetProjectByID.count_tasks_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
 	}
 	lg.Info("project.GetProjectByID.success")
 	return &ProjectProfile{
-		ID:        project.ID,
-		Name:      project.Name,
-		UserID:    project.UserID,
-		Color:     project.Color,
-		UpdatedAt: project.UpdatedAt,
-		CreatedAt: project.CreatedAt,
-		SortOrder: project.SortOrder,
+		ID:         project.ID,
+		Name:       project.Name,
+		UserID:     project.UserID,
+		Color:      project.Color,
+		UpdatedAt:  project.UpdatedAt,
+		CreatedAt:  project.CreatedAt,
+		SortOrder:  project.SortOrder,
+		TaskCounts: counts,
 	}, nil
 }
 
@@ -94,7 +102,7 @@ func (p *ProjectService) SearchProjectListByName(ctx context.Context, lg *zap.Lo
 	Projects, total, err := models.GetProjectListByUserIDAndName(ctx, uid, name, page, size)
 	if err != nil {
 		lg.Error("project.SearchProjectListByName.projects_failed", zap.Error(err))
-		return nil, 0, &AppError{Code: utils.ErrCodeInternalServer, Message: "获取项目列表信息出错"}
+		return nil, 0, &AppError{Code: utils.ErrCodeInternalServer, Key: "project.list_failed"}
 	}
 
 	res = make([]ProjectSummary, len(Projects))
@@ -124,7 +132,8 @@ func (p *ProjectService) SearchProjectListByName(ctx context.Context, lg *zap.Lo
 
 
 type CreatePeojectResult struct {
-	Project models.Project
+	Project   models.Project
+	UndoToken string
 }
 
 func (p *ProjectService) CreateProject(ctx context.Context, lg *zap.Logger, uid int, name string, color *string) (*CreatePeojectResult, error) {
@@ -134,7 +143,7 @@ func (p *ProjectService) CreateProject(ctx context.Context, lg *zap.Logger, uid
 		err := validateColorIfProvided(color)
 		if err != nil{
 			lg.Info("CreateProject.Color_is_Error")
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "项目颜色格式出错"}
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "project.color_invalid"}
 		}
 		cl := *color
 		project = models.Project{Name: name, UserID: uid, Color: cl}
@@ -145,10 +154,10 @@ func (p *ProjectService) CreateProject(ctx context.Context, lg *zap.Logger, uid
 	if err != nil {
 		if errors.Is(err, models.ErrProjectExists) {
 			lg.Info("CreateProject.duplicate_on_insert")
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "该项目已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "project.exists"}
 		}
 		lg.Error("CreateProject_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "保存失败，请联系管理员"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.save_failed"}
 	}
 	err = IncrProjectsVer(ctx, c.Rdb, uid)
 	if err != nil {
@@ -157,18 +166,19 @@ func (p *ProjectService) CreateProject(ctx context.Context, lg *zap.Logger, uid
 
 	lg.Info("project.CreateProject.success", zap.Int("project_id", created.ID))
 	return &CreatePeojectResult{
-		Project: created,
+		Project:   created,
+		UndoToken: issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoProjectCreate(created)),
 	}, nil
 }
 
 type UpdateProjectInput struct {
 	Name      *string `json:"name"  binding:"omitempty,min=1,max=128"`
 	Color     *string `json:"color" binding:"omitempty,max=16"`
-	SortOrder *int64  `json:"sort_order" binding:"omitempty"`
 }
 type UpdateProjectResult struct {
-	Project  models.Project
-	Affected int64
+	Project   models.Project
+	Affected  int64
+	UndoToken string
 }
 
 func (p *ProjectService) UpdateProject(ctx context.Context, lg *zap.Logger, pid int, uid int, in UpdateProjectInput) (*UpdateProjectResult, error) {
@@ -181,34 +191,28 @@ func (p *ProjectService) UpdateProject(ctx context.Context, lg *zap.Logger, pid
 		err := validateColorIfProvided(in.Color)
 		if err != nil{
 			lg.Info("CreateProject.Color_is_Error")
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "项目颜色格式出错"}
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "project.color_invalid"}
 		}
 		Color := *(in.Color)
 		update["color"] = Color
 	}
-	if in.SortOrder != nil {
-		if *in.SortOrder < 0 {
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "sort_order 不能小于 0"}
-		}
-		update["sort_order"] = *in.SortOrder
-	}
 	if len(update) == 0 {
 		lg.Info("project.no_fields_to_update")
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "没有需要更新的字段"}
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "common.no_fields"}
 	}
 
-	updated, affected, err := models.UpdateProjectByIDAndUserID(ctx, update, pid, uid)
+	before, updated, affected, err := models.UpdateProjectByIDAndUserID(ctx, update, pid, uid)
 	if err != nil {
 		if errors.Is(err, models.ErrProjectExists) {
 			lg.Info("project.UpdateProject.duplicate_name", zap.Int("project_id", pid))
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "该项目已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "project.exists"}
 		}
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Info("project.UpdateProject.not_found", zap.Int("project_id", pid))
-			return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "项目不存在或无权限"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
 		}
 		lg.Error("project.UpdateProject.db_failed", zap.Int("project_id", pid), zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "保存失败，请联系管理员"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.save_failed"}
 	}
 	if affected == 0 {
 		lg.Info("Project.update.noop")
@@ -223,39 +227,51 @@ func (p *ProjectService) UpdateProject(ctx context.Context, lg *zap.Logger, pid
 	}
 	lg.Info("project.update.ok", zap.Int("project_id", updated.ID), zap.Int64("affected", affected))
 	return &UpdateProjectResult{
-		Project:  updated,
-		Affected: affected,
+		Project:   updated,
+		Affected:  affected,
+		UndoToken: issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoProjectUpdate(before, updated)),
 	}, nil
 }
 
 type DeleteProjectResult struct {
 	Affected     int64
 	TaskAffected int64
+	UndoToken    string
 }
 
 func (p *ProjectService) DeleteProject(ctx context.Context, lg *zap.Logger, pid int, uid int) (*DeleteProjectResult, error) {
-	affected, taskAffected, err := models.DeleteProjectAndTasks(ctx, pid, uid)
+	affected, taskIDs, err := models.DeleteProjectAndTasks(ctx, pid, uid)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Info("project not found or already deleted", zap.Int("project_id", pid))
-			return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "项目不存在或已删除"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found_or_deleted"}
 		}
 		lg.Error("delete project failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "删除失败"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.delete_failed"}
 	}
 	IncrProjectsVer(ctx, c.Rdb, uid)
 	lg.Info("project.delete.ok",
 		zap.Int("project_id", pid),
 		zap.Int64("proj_affected", affected),
-		zap.Int64("task_affected", taskAffected),
+		zap.Int("task_affected", len(taskIDs)),
 	)
-	err = DelTaskSummaryCache(ctx, uid, pid, "all")
+	for _, id := range taskIDs {
+		if err := DelTaskDetailCache(ctx, uid, id); err != nil {
+			lg.Warn("redis.deleteProject.task_detail_failed", zap.Error(err), zap.Int("task_id", id))
+		}
+	}
+	err = DelTaskListCache(ctx, uid, pid)
 	if err != nil {
 		lg.Warn("redis.deleteProject.task_summary_failed", zap.Error(err), zap.Int("pid", pid))
 	}
+	// 删除的项目可能是默认项目
+	if err := DelPreferencesCache(ctx, uid); err != nil {
+		lg.Warn("redis.deleteProject.prefs_failed", zap.Error(err))
+	}
 	return &DeleteProjectResult{
 		Affected:     affected,
-		TaskAffected: taskAffected,
+		TaskAffected: int64(len(taskIDs)),
+		UndoToken:    issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoProjectDelete(pid)),
 	}, nil
 }
 
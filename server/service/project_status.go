@@ -0,0 +1,194 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// statusKeyPattern 状态 Key 保存在任务上并出现在接口参数中，限制为小写字母、数字与下划线
+var statusKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+func validStatusCategory(c string) bool {
+	return c == models.StatusOpen || c == models.StatusClosed
+}
+
+// statusAppError 将状态相关的模型错误转换为接口错误，未识别的错误返回 nil
+func statusAppError(err error) *AppError {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+	case errors.Is(err, models.ErrStatusNotFound):
+		return &AppError{Code: utils.ErrCodeNotFound, Key: "status.not_found"}
+	case errors.Is(err, models.ErrStatusExists):
+		return &AppError{Code: utils.ErrCodeConflict, Key: "status.exists"}
+	case errors.Is(err, models.ErrStatusLastOfCategory):
+		return &AppError{Code: utils.ErrCodeConflict, Key: "status.last_of_category"}
+	case errors.Is(err, models.ErrStatusInUse):
+		return &AppError{Code: utils.ErrCodeConflict, Key: "status.in_use"}
+	}
+	return nil
+}
+
+// resolveTaskStatus 确定任务在目标项目中的状态：指定了 key 时必须存在；
+// 未指定时沿用 fallback（跨项目移动时的原状态），目标项目没有同名状态则取同分类的第一个状态
+func resolveTaskStatus(statuses []models.ProjectStatus, key *string, fallback string, category string) (models.ProjectStatus, bool) {
+	if key != nil {
+		return models.FindStatus(statuses, strings.TrimSpace(*key))
+	}
+	if s, ok := models.FindStatus(statuses, fallback); ok {
+		return s, true
+	}
+	return models.FirstStatus(statuses, category)
+}
+
+func (p *ProjectService) ListStatuses(ctx context.Context, lg *zap.Logger, uid, pid int) ([]models.ProjectStatus, error) {
+	if _, err := models.GetProjectByID(uid, pid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+		}
+		lg.Error("project.status.list.project_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	items, err := models.ListProjectStatuses(ctx, uid, pid)
+	if err != nil {
+		lg.Error("project.status.list.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	return items, nil
+}
+
+type CreateStatusInput struct {
+	Key      string
+	Name     string
+	Category string
+	Position *int
+}
+
+// StatusResult 新增或修改后的状态与撤销令牌
+type StatusResult struct {
+	Status    models.ProjectStatus
+	UndoToken string
+}
+
+func (p *ProjectService) CreateStatus(ctx context.Context, lg *zap.Logger, uid, pid int, in CreateStatusInput) (*StatusResult, error) {
+	lg.Info("project.status.create.begin", zap.Int("uid", uid), zap.Int("project_id", pid), zap.String("key", in.Key))
+	in.Key = strings.TrimSpace(in.Key)
+	in.Name = strings.TrimSpace(in.Name)
+	var fields []utils.FieldError
+	if !statusKeyPattern.MatchString(in.Key) {
+		fields = append(fields, utils.NewFieldError("key", "format", "status.key_invalid"))
+	}
+	if in.Name == "" {
+		fields = append(fields, utils.NewFieldError("name", "required", "status.name_required"))
+	}
+	if in.Category == "" {
+		in.Category = models.StatusOpen
+	}
+	if !validStatusCategory(in.Category) {
+		fields = append(fields, utils.NewFieldError("category", "oneof", "status.category_invalid"))
+	}
+	if len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+	created, err := models.CreateProjectStatus(ctx, models.ProjectStatus{
+		ProjectID: pid,
+		UserID:    uid,
+		Key:       in.Key,
+		Name:      in.Name,
+		Category:  in.Category,
+	}, in.Position)
+	if err != nil {
+		if ae := statusAppError(err); ae != nil {
+			return nil, ae
+		}
+		lg.Error("project.status.create.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.create_failed"}
+	}
+	token := issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoStatusCreate(created))
+	return &StatusResult{Status: created, UndoToken: token}, nil
+}
+
+type UpdateStatusInput struct {
+	Name     *string
+	Category *string
+	Position *int
+}
+
+// UpdateStatus 修改状态名称、分类或顺序；分类变化时该状态下的任务随之变为已完成或未完成
+func (p *ProjectService) UpdateStatus(ctx context.Context, lg *zap.Logger, uid, pid int, key string, in UpdateStatusInput) (*StatusResult, error) {
+	lg.Info("project.status.update.begin", zap.Int("uid", uid), zap.Int("project_id", pid), zap.String("key", key))
+	var fields []utils.FieldError
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			fields = append(fields, utils.NewFieldError("name", "required", "status.name_required"))
+		}
+		in.Name = &name
+	}
+	if in.Category != nil && !validStatusCategory(*in.Category) {
+		fields = append(fields, utils.NewFieldError("category", "oneof", "status.category_invalid"))
+	}
+	if len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+	if in.Name == nil && in.Category == nil && in.Position == nil {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "common.no_fields"}
+	}
+	change, err := models.UpdateProjectStatus(ctx, uid, pid, key, models.StatusUpdate{
+		Name:     in.Name,
+		Category: in.Category,
+		Position: in.Position,
+	})
+	if err != nil {
+		if ae := statusAppError(err); ae != nil {
+			return nil, ae
+		}
+		lg.Error("project.status.update.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	invalidateStatusTasks(ctx, lg, uid, pid, change.TaskIDs())
+	token := issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoStatusUpdate(change))
+	return &StatusResult{Status: change.After, UndoToken: token}, nil
+}
+
+type DeleteStatusResult struct {
+	TasksMoved int
+	UndoToken  string
+}
+
+// DeleteStatus 删除状态，状态下仍有任务时迁移到 moveTo；撤销时重建状态并把任务移回
+func (p *ProjectService) DeleteStatus(ctx context.Context, lg *zap.Logger, uid, pid int, key, moveTo string) (*DeleteStatusResult, error) {
+	lg.Info("project.status.delete.begin", zap.Int("uid", uid), zap.Int("project_id", pid), zap.String("key", key), zap.String("move_to", moveTo))
+	change, err := models.DeleteProjectStatus(ctx, uid, pid, key, strings.TrimSpace(moveTo))
+	if err != nil {
+		if ae := statusAppError(err); ae != nil {
+			return nil, ae
+		}
+		lg.Error("project.status.delete.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.delete_failed"}
+	}
+	invalidateStatusTasks(ctx, lg, uid, pid, change.TaskIDs())
+	token := issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoStatusDelete(change))
+	return &DeleteStatusResult{TasksMoved: len(change.Tasks), UndoToken: token}, nil
+}
+
+func invalidateStatusTasks(ctx context.Context, lg *zap.Logger, uid, pid int, taskIDs []int) {
+	if len(taskIDs) == 0 {
+		return
+	}
+	for _, id := range taskIDs {
+		if err := DelTaskDetailCache(ctx, uid, id); err != nil {
+			lg.Warn("redis.del.task_detail_failed", zap.Error(err), zap.Int("task_id", id))
+		}
+	}
+	if err := DelTaskListCache(ctx, uid, pid); err != nil {
+		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", pid))
+	}
+}
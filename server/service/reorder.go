@@ -0,0 +1,81 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReorderInput 放到 AfterID 下方、BeforeID 上方，至少给出一个；两者都给出时必须相邻
+type ReorderInput struct {
+	AfterID  int
+	BeforeID int
+}
+
+func (in ReorderInput) validate(id int) *AppError {
+	if in.AfterID == 0 && in.BeforeID == 0 {
+		return &AppError{Code: utils.ErrCodeValidation, Key: "order.anchor_required"}
+	}
+	if in.AfterID == id || in.BeforeID == id {
+		return &AppError{Code: utils.ErrCodeValidation, Key: "order.anchor_self"}
+	}
+	return nil
+}
+
+// Reorder 在项目任务列表中移动任务，排序键由服务端计算
+func (t *TaskService) Reorder(ctx context.Context, lg *zap.Logger, uid, id int, in ReorderInput) (*UpdateTaskResult, error) {
+	lg.Info("task.reorder.begin", zap.Int("uid", uid), zap.Int("task_id", id), zap.Int("after_id", in.AfterID), zap.Int("before_id", in.BeforeID))
+	if ae := in.validate(id); ae != nil {
+		return nil, ae
+	}
+	before, after, err := models.ReorderTask(ctx, uid, id, in.AfterID, in.BeforeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
+		case errors.Is(err, models.ErrOrderStale):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "order.stale"}
+		}
+		lg.Error("task.reorder.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	if err := DelTaskListCache(ctx, uid, after.ProjectID); err != nil {
+		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", after.ProjectID))
+	}
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskUpdate(before, after))
+	after.ContentHtml = resolveAssets(ctx, lg, uid, after.ContentHtml)
+	lg.Info("task.reorder.ok", zap.Int("task_id", id), zap.Int64("sort_order", after.SortOrder))
+	return &UpdateTaskResult{Task: after, Affected: 1, UndoToken: token}, nil
+}
+
+// Reorder 在项目列表中移动项目
+func (p *ProjectService) Reorder(ctx context.Context, lg *zap.Logger, uid, id int, in ReorderInput) (*UpdateProjectResult, error) {
+	lg.Info("project.reorder.begin", zap.Int("uid", uid), zap.Int("project_id", id), zap.Int("after_id", in.AfterID), zap.Int("before_id", in.BeforeID))
+	if ae := in.validate(id); ae != nil {
+		return nil, ae
+	}
+	before, after, err := models.ReorderProject(ctx, uid, id, in.AfterID, in.BeforeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+		case errors.Is(err, models.ErrOrderStale):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "order.stale"}
+		}
+		lg.Error("project.reorder.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	if err := IncrProjectsVer(ctx, c.Rdb, uid); err != nil {
+		lg.Warn("project.reorder.incr_ver_failed", zap.Error(err))
+	}
+	lg.Info("project.reorder.ok", zap.Int("project_id", id), zap.Int64("sort_order", after.SortOrder))
+	return &UpdateProjectResult{
+		Project:   after,
+		Affected:  1,
+		UndoToken: issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoProjectUpdate(before, after)),
+	}, nil
+}
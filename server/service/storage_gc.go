@@ -0,0 +1,176 @@
+package service
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/storage"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	storageGCTimeout    = 30 * time.Minute
+	storageGCMaxDeletes = 1000 // 单次最多删除的对象数，防止引用数据异常时误删过多
+	storageGCMaxReport  = 200  // 报告中最多列出的孤儿对象数
+)
+
+// storageGCPrefixes 由数据库引用管理的对象前缀，未被引用且早于宽限期的对象视为孤儿
+var storageGCPrefixes = []string{"images/", "attachments/"}
+
+// storageGCExportPrefix 导出文件不被数据库引用，超过保留时长即删除；导出状态过期后文件不会再被下载
+const storageGCExportPrefix = "exports/"
+
+var errStorageGCLimit = errors.New("storage gc delete limit reached")
+
+type StorageGCService struct{}
+
+func NewStorageGCService() *StorageGCService {
+	return &StorageGCService{}
+}
+
+// OrphanObject 未被引用的对象
+type OrphanObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// StorageGCReport 一次清理的结果
+type StorageGCReport struct {
+	DryRun        bool           `json:"dry_run"`
+	Grace         string         `json:"grace"`
+	Scanned       int            `json:"scanned"`
+	Referenced    int            `json:"referenced"`
+	TooRecent     int            `json:"too_recent"`
+	ExpiredAssets int            `json:"expired_assets"`
+	Orphaned      int            `json:"orphaned"`
+	OrphanedBytes int64          `json:"orphaned_bytes"`
+	Deleted       int            `json:"deleted"`
+	Failed        int            `json:"failed"`
+	LimitReached  bool           `json:"limit_reached"`
+	Objects       []OrphanObject `json:"objects"`
+	StartedAt     time.Time      `json:"started_at"`
+	FinishedAt    time.Time      `json:"finished_at"`
+}
+
+// StartStorageGC 定期清理对象存储中未被数据库引用的对象
+func (s *StorageGCService) StartStorageGC(ctx context.Context, lg *zap.Logger) {
+	if config.StorageGCInterval <= 0 {
+		lg.Info("storage_gc.disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(config.StorageGCInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				lg.Info("storage_gc.stopped")
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), storageGCTimeout)
+				if _, err := s.Run(ctx, lg, config.StorageGCDryRun); err != nil {
+					lg.Warn("storage_gc.run_failed", zap.Error(err))
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Run 先取数据库引用快照再遍历对象，快照之后上传的对象必然晚于宽限期，不会被误删
+// 正文图片按最后一次失去引用的时间计算宽限期
+func (s *StorageGCService) Run(ctx context.Context, lg *zap.Logger, dryRun bool) (*StorageGCReport, error) {
+	ok, err := AcquireStorageGCLock(ctx, storageGCTimeout)
+	if err != nil {
+		lg.Error("storage_gc.lock_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.busy"}
+	}
+	if !ok {
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "storage_gc.running"}
+	}
+	defer func() {
+		if err := ReleaseStorageGCLock(context.Background()); err != nil {
+			lg.Warn("storage_gc.unlock_failed", zap.Error(err))
+		}
+	}()
+
+	rep := &StorageGCReport{DryRun: dryRun, Grace: config.StorageGCGrace.String(), StartedAt: time.Now(), Objects: []OrphanObject{}}
+	cutoff := rep.StartedAt.Add(-config.StorageGCGrace)
+	// 宽限期内一直无人引用的正文图片先删记录，对象随后按孤儿对象删除
+	if !dryRun {
+		keys, err := models.DeleteExpiredAssets(ctx, cutoff)
+		if err != nil {
+			lg.Error("storage_gc.assets_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "storage_gc.failed"}
+		}
+		rep.ExpiredAssets = len(keys)
+	}
+	refs, err := models.ObjectKeyRefs(ctx, cutoff)
+	if err != nil {
+		lg.Error("storage_gc.refs_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "storage_gc.failed"}
+	}
+
+	prefixes := append(append([]string{}, storageGCPrefixes...), storageGCExportPrefix)
+	for _, prefix := range prefixes {
+		cutoff := cutoff
+		if prefix == storageGCExportPrefix {
+			cutoff = rep.StartedAt.Add(-exportRetention)
+		}
+		err := storage.Default.List(ctx, prefix, func(o storage.ObjectInfo) error {
+			rep.Scanned++
+			if _, ok := refs[o.Key]; ok {
+				rep.Referenced++
+				return nil
+			}
+			// 取不到修改时间的对象按最新处理
+			if o.LastModified.IsZero() || o.LastModified.After(cutoff) {
+				rep.TooRecent++
+				return nil
+			}
+			if rep.Orphaned >= storageGCMaxDeletes {
+				rep.LimitReached = true
+				return errStorageGCLimit
+			}
+			rep.Orphaned++
+			rep.OrphanedBytes += o.Size
+			if len(rep.Objects) < storageGCMaxReport {
+				rep.Objects = append(rep.Objects, OrphanObject{Key: o.Key, Size: o.Size, LastModified: o.LastModified})
+			}
+			if dryRun {
+				return nil
+			}
+			if err := storage.Default.Delete(ctx, o.Key); err != nil {
+				rep.Failed++
+				lg.Warn("storage_gc.delete_failed", zap.String("key", o.Key), zap.Error(err))
+				return nil
+			}
+			rep.Deleted++
+			return nil
+		})
+		if errors.Is(err, errStorageGCLimit) {
+			break
+		}
+		if err != nil {
+			lg.Error("storage_gc.list_failed", zap.String("prefix", prefix), zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "storage_gc.failed"}
+		}
+	}
+	rep.FinishedAt = time.Now()
+	audit(lg, "storage_gc",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("scanned", rep.Scanned),
+		zap.Int("expired_assets", rep.ExpiredAssets),
+		zap.Int("orphaned", rep.Orphaned),
+		zap.Int64("orphaned_bytes", rep.OrphanedBytes),
+		zap.Int("deleted", rep.Deleted),
+		zap.Int("failed", rep.Failed),
+		zap.Bool("limit_reached", rep.LimitReached),
+	)
+	return rep, nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+const storageGCLockKey = "storage:gc:lock"
+
+// AcquireStorageGCLock 同一时间只允许一个实例执行对象清理
+func AcquireStorageGCLock(ctx context.Context, ttl time.Duration) (bool, error) {
+	return c.Rdb.SetNX(ctx, storageGCLockKey, 1, ttl).Result()
+}
+
+func ReleaseStorageGCLock(ctx context.Context) error {
+	return c.Rdb.Del(ctx, storageGCLockKey).Err()
+}
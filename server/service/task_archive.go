@@ -0,0 +1,151 @@
+package service
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	archiveTimeout = 5 * time.Minute
+	archiveBatch   = 500
+	archiveRounds  = 20
+)
+
+// Archive 手动归档处于已完成分类状态的任务，归档后不再出现在任务列表中
+func (t *TaskService) Archive(ctx context.Context, lg *zap.Logger, uid, id int) (*UpdateTaskResult, error) {
+	return t.setArchived(ctx, lg, uid, id, true)
+}
+
+// Unarchive 取消归档，任务保持已完成状态回到列表
+func (t *TaskService) Unarchive(ctx context.Context, lg *zap.Logger, uid, id int) (*UpdateTaskResult, error) {
+	return t.setArchived(ctx, lg, uid, id, false)
+}
+
+func (t *TaskService) setArchived(ctx context.Context, lg *zap.Logger, uid, id int, archived bool) (*UpdateTaskResult, error) {
+	lg.Info("task.archive.begin", zap.Int("uid", uid), zap.Int("task_id", id), zap.Bool("archived", archived))
+	task, err := models.GetTaskByIDAndUID(ctx, id, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
+		}
+		lg.Error("task.archive.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if archived && task.CompletedAt == nil {
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.archive_not_done"}
+	}
+	if (task.ArchivedAt != nil) == archived {
+		return &UpdateTaskResult{Task: task}, nil
+	}
+	update := map[string]interface{}{"archived_at": nil}
+	if archived {
+		update["archived_at"] = time.Now()
+	}
+	before, updated, affected, err := models.UpdateTaskWithRevision(ctx, update, id, uid, models.RevisionMeta{Reason: models.RevisionUpdate})
+	if err != nil {
+		lg.Error("task.archive.update_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	if err := DelTaskDetailCache(ctx, uid, id); err != nil {
+		lg.Warn("redis.del.task_detail_failed", zap.Error(err))
+	}
+	if err := DelTaskListCache(ctx, uid, task.ProjectID); err != nil {
+		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", task.ProjectID))
+	}
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskUpdate(before, updated))
+	updated.ContentHtml = resolveAssets(ctx, lg, uid, updated.ContentHtml)
+	lg.Info("task.archive.ok", zap.Int("task_id", id), zap.Bool("archived", archived))
+	return &UpdateTaskResult{Task: updated, Affected: affected, UndoToken: token}, nil
+}
+
+type ArchivedListResult struct {
+	Tasks []models.Task
+	Total int64
+}
+
+// ListArchived 归档视图，pid 为 0 时列出全部项目
+func (t *TaskService) ListArchived(ctx context.Context, lg *zap.Logger, uid, pid, page, size int) (*ArchivedListResult, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 20
+	}
+	if pid > 0 {
+		if _, err := models.GetProjectByID(uid, pid); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+			}
+			lg.Error("task.archived.project_query_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+		}
+	}
+	tasks, total, err := models.ListArchivedTasks(ctx, uid, pid, (page-1)*size, size)
+	if err != nil {
+		lg.Error("task.archived.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "task.list_failed"}
+	}
+	return &ArchivedListResult{Tasks: tasks, Total: total}, nil
+}
+
+// StartArchiver 定期归档完成时间超过 config.TaskArchiveAfter 的任务
+func (t *TaskService) StartArchiver(ctx context.Context, lg *zap.Logger) {
+	if config.TaskArchiveAfter <= 0 || config.TaskArchiveInterval <= 0 {
+		lg.Info("task_archiver.disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(config.TaskArchiveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				lg.Info("task_archiver.stopped")
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), archiveTimeout)
+				t.archiveDone(ctx, lg)
+				cancel()
+			}
+		}
+	}()
+}
+
+// archiveDone 条件更新只归档仍为已完成且未归档的任务，多实例同时执行也不会重复处理
+func (t *TaskService) archiveDone(ctx context.Context, lg *zap.Logger) {
+	before := time.Now().Add(-config.TaskArchiveAfter)
+	var n int
+	for i := 0; i < archiveRounds; i++ {
+		tasks, err := models.ArchiveDoneTasks(ctx, before, archiveBatch)
+		if err != nil {
+			lg.Error("task_archiver.db_failed", zap.Error(err))
+			break
+		}
+		lists := map[[2]int]bool{}
+		for _, task := range tasks {
+			if err := DelTaskDetailCache(ctx, task.UserID, task.ID); err != nil {
+				lg.Warn("redis.del.task_detail_failed", zap.Error(err), zap.Int("task_id", task.ID))
+			}
+			lists[[2]int{task.UserID, task.ProjectID}] = true
+		}
+		for k := range lists {
+			if err := DelTaskListCache(ctx, k[0], k[1]); err != nil {
+				lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", k[1]))
+			}
+		}
+		n += len(tasks)
+		if len(tasks) < archiveBatch {
+			break
+		}
+	}
+	if n > 0 {
+		lg.Info("task_archiver.archived", zap.Int("tasks", n))
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"sort"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// bulkMaxIDs 单次批量操作的任务数上限
+const bulkMaxIDs = 100
+
+type BulkInput struct {
+	Op         string
+	IDs        []int
+	ProjectID  int
+	Priority   int
+	AddTags    []string
+	RemoveTags []string
+}
+
+// BulkItemResult 单个任务的结果，失败时 Key 为错误的翻译键，由接口层翻译
+type BulkItemResult struct {
+	ID      int    `json:"id"`
+	OK      bool   `json:"ok"`
+	Changed bool   `json:"changed"`
+	Code    int    `json:"code,omitempty"`
+	Key     string `json:"-"`
+	Args    []any  `json:"-"`
+	Msg     string `json:"msg,omitempty"`
+}
+
+type BulkResult struct {
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	UndoToken string           `json:"undo_token,omitempty"`
+}
+
+func (in *BulkInput) validate() *AppError {
+	var fields []utils.FieldError
+	switch in.Op {
+	case models.BulkComplete, models.BulkReopen, models.BulkDelete:
+	case models.BulkMoveProject:
+		if in.ProjectID <= 0 {
+			fields = append(fields, utils.NewFieldError("project_id", "required", "project.id_invalid"))
+		}
+	case models.BulkSetPriority:
+		if in.Priority < 1 || in.Priority > 5 {
+			fields = append(fields, utils.NewFieldError("priority", "range", "task.priority_range"))
+		}
+	case models.BulkTag:
+		add, fe := normalizeTags("add_tags", in.AddTags)
+		fields = append(fields, fe...)
+		remove, fe := normalizeTags("remove_tags", in.RemoveTags)
+		fields = append(fields, fe...)
+		if len(fields) == 0 && len(add) == 0 && len(remove) == 0 {
+			fields = append(fields, utils.NewFieldError("add_tags", "required", "bulk.tags_required"))
+		}
+		in.AddTags, in.RemoveTags = add, remove
+	default:
+		fields = append(fields, utils.NewFieldError("op", "oneof", "bulk.op_invalid"))
+	}
+	// 去重并升序排列，并发的批量操作按相同顺序锁定任务
+	seen := make(map[int]bool, len(in.IDs))
+	ids := make([]int, 0, len(in.IDs))
+	for _, id := range in.IDs {
+		if id <= 0 {
+			fields = append(fields, utils.NewFieldError("ids", "gt", "task.id_invalid"))
+			break
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	in.IDs = ids
+	if len(ids) == 0 {
+		fields = append(fields, utils.NewFieldError("ids", "required", "bulk.ids_required"))
+	} else if len(ids) > bulkMaxIDs {
+		fields = append(fields, utils.NewFieldError("ids", "max", "bulk.too_many", bulkMaxIDs))
+	}
+	if len(fields) > 0 {
+		return validationError(fields)
+	}
+	return nil
+}
+
+// bulkItemAppError 单个任务失败的原因
+func bulkItemAppError(op string, err error) *AppError {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound) && op == models.BulkDelete:
+		return &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found_or_deleted"}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
+	case errors.Is(err, models.ErrTaskExists):
+		return &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
+	case errors.Is(err, models.ErrStatusNotFound):
+		return &AppError{Code: utils.ErrCodeConflict, Key: "bulk.status_missing"}
+	case errors.Is(err, models.ErrTooManyTags):
+		return &AppError{Code: utils.ErrCodeValidation, Key: "task.tags_too_many", Args: []any{models.TaskTagLimit}}
+	}
+	return &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+}
+
+// Bulk 在一个事务中对多个任务执行同一操作，逐项返回结果；成功的修改共用一个撤销令牌，缓存统一清理一次
+func (t *TaskService) Bulk(ctx context.Context, lg *zap.Logger, uid int, in BulkInput) (*BulkResult, error) {
+	lg.Info("task.bulk.begin", zap.Int("uid", uid), zap.String("op", in.Op), zap.Int("count", len(in.IDs)))
+	if ae := in.validate(); ae != nil {
+		return nil, ae
+	}
+	if in.Op == models.BulkMoveProject {
+		if _, err := models.GetProjectByID(uid, in.ProjectID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+			}
+			lg.Error("task.bulk.project_query_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+		}
+	}
+	items, err := models.BulkUpdateTasks(ctx, uid, in.IDs, models.BulkOp{
+		Op:         in.Op,
+		ProjectID:  in.ProjectID,
+		Priority:   in.Priority,
+		AddTags:    in.AddTags,
+		RemoveTags: in.RemoveTags,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
+		}
+		lg.Error("task.bulk.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+
+	res := &BulkResult{Results: make([]BulkItemResult, len(items))}
+	var steps []models.UndoStep
+	var taskIDs, pids []int
+	seenPid := map[int]bool{}
+	addPid := func(pid int) {
+		if pid > 0 && !seenPid[pid] {
+			seenPid[pid] = true
+			pids = append(pids, pid)
+		}
+	}
+	for i, item := range items {
+		if item.Err != nil {
+			ae := bulkItemAppError(in.Op, item.Err)
+			res.Results[i] = BulkItemResult{ID: item.ID, Code: ae.Code, Key: ae.Key, Args: ae.Args}
+			res.Failed++
+			continue
+		}
+		res.Results[i] = BulkItemResult{ID: item.ID, OK: true, Changed: item.Changed}
+		res.Succeeded++
+		if !item.Changed {
+			continue
+		}
+		taskIDs = append(taskIDs, item.ID)
+		addPid(item.Before.ProjectID)
+		if in.Op == models.BulkDelete {
+			steps = append(steps, models.UndoTaskDelete(item.ID))
+			continue
+		}
+		addPid(item.After.ProjectID)
+		steps = append(steps, models.UndoTaskUpdate(item.Before, item.After))
+	}
+	if err := DelTaskCaches(ctx, uid, taskIDs, pids); err != nil {
+		lg.Warn("redis.del.task_bulk_failed", zap.Error(err), zap.Ints("task_ids", taskIDs), zap.Ints("pids", pids))
+	}
+	if len(steps) > 0 {
+		res.UndoToken = issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, steps...)
+	}
+	lg.Info("task.bulk.ok", zap.String("op", in.Op), zap.Int("succeeded", res.Succeeded), zap.Int("failed", res.Failed))
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBulkInputValidateTag(t *testing.T) {
+	cases := []struct {
+		name       string
+		add, rm    []string
+		wantAdd    []string
+		wantRemove []string
+		field      string
+	}{
+		{"add", []string{" work ", "work", "home"}, nil, []string{"work", "home"}, []string{}, ""},
+		{"remove", nil, []string{"old"}, []string{}, []string{"old"}, ""},
+		{"nothing", nil, []string{" "}, nil, nil, "add_tags"},
+		{"tag too long", []string{strings.Repeat("x", models.TaskTagMaxLen+1)}, nil, nil, nil, "add_tags"},
+		{"remove too long", nil, []string{strings.Repeat("x", models.TaskTagMaxLen+1)}, nil, nil, "remove_tags"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := BulkInput{Op: models.BulkTag, IDs: []int{3, 1, 3}, AddTags: tc.add, RemoveTags: tc.rm}
+			ae := in.validate()
+			if tc.field != "" {
+				if ae == nil || len(ae.Fields) == 0 || ae.Fields[0].Field != tc.field {
+					t.Fatalf("validate = %+v, want error on %s", ae, tc.field)
+				}
+				return
+			}
+			if ae != nil {
+				t.Fatalf("validate = %+v", ae)
+			}
+			if !reflect.DeepEqual([]string(in.AddTags), tc.wantAdd) || !reflect.DeepEqual([]string(in.RemoveTags), tc.wantRemove) {
+				t.Fatalf("tags = %v / %v, want %v / %v", in.AddTags, in.RemoveTags, tc.wantAdd, tc.wantRemove)
+			}
+			if !reflect.DeepEqual(in.IDs, []int{1, 3}) {
+				t.Fatalf("ids = %v, want [1 3]", in.IDs)
+			}
+		})
+	}
+}
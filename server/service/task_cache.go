@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 type TaskListCache struct {
@@ -16,11 +18,16 @@ func taskKey(uid, id int) string {
 	return fmt.Sprintf("task:detail:%d:%d", uid, id)
 }
 
-func taskListKey(uid, pid int, status string) string {
+// taskListKey 同一项目各状态筛选的列表缓存放在一个哈希中，修改任务时整体删除
+func taskListKey(uid, pid int) string {
+	return fmt.Sprintf("task:list:%d:%d", uid, pid)
+}
+
+func taskListField(status string) string {
 	if status == "" {
-		status = "all"
+		return "all"
 	}
-	return fmt.Sprintf("task:list:%d:%d:%s", uid, pid, status)
+	return status
 }
 
 func SetaskDetailCache(ctx context.Context, td *TaskDetail) error {
@@ -52,29 +59,47 @@ func DelTaskDetailCache(ctx context.Context, uid, id int) error {
 }
 
 func SetTaskSummaryCache(ctx context.Context, uid, pid int, status string, total int64, ts []TaskSummary) error {
-	key := taskListKey(uid, pid, status)
-	b, err := json.Marshal(TaskListCache{Items: ts,Total: total})
+	key := taskListKey(uid, pid)
+	b, err := json.Marshal(TaskListCache{Items: ts, Total: total})
 	if err != nil {
 		return err
 	}
-	return c.Rdb.Set(ctx, key, b, time.Hour).Err()
+	_, err = c.Rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, key, taskListField(status), b)
+		p.Expire(ctx, key, time.Hour)
+		return nil
+	})
+	return err
 }
 
-func GetTaskSummaryCache(ctx context.Context, uid, pid int, status string) (*TaskListCache, error){
-	key := taskListKey(uid, pid, status)
-	data, err := c.Rdb.Get(ctx, key).Bytes()
-	if err == nil {
-		var td TaskListCache
-		if uerr := json.Unmarshal(data, &td); uerr != nil {
-			return nil, uerr
-		}
-		return  &td, nil
+func GetTaskSummaryCache(ctx context.Context, uid, pid int, status string) (*TaskListCache, error) {
+	data, err := c.Rdb.HGet(ctx, taskListKey(uid, pid), taskListField(status)).Bytes()
+	if err != nil {
+		return nil, err
 	}
-	return nil, err
+	var td TaskListCache
+	if err := json.Unmarshal(data, &td); err != nil {
+		return nil, err
+	}
+	return &td, nil
 }
 
-func DelTaskSummaryCache(ctx context.Context, uid, pid int, status string) error {
-	key := taskListKey(uid, pid, status)
-	return c.Rdb.Del(ctx, key).Err()
+// DelTaskListCache 删除项目下全部状态筛选的列表缓存
+func DelTaskListCache(ctx context.Context, uid, pid int) error {
+	return c.Rdb.Del(ctx, taskListKey(uid, pid)).Err()
 }
 
+// DelTaskCaches 一次删除多个任务的详情缓存与多个项目的列表缓存
+func DelTaskCaches(ctx context.Context, uid int, taskIDs, pids []int) error {
+	keys := make([]string, 0, len(taskIDs)+len(pids))
+	for _, id := range taskIDs {
+		keys = append(keys, taskKey(uid, id))
+	}
+	for _, pid := range pids {
+		keys = append(keys, taskListKey(uid, pid))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.Rdb.Del(ctx, keys...).Err()
+}
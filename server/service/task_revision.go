@@ -0,0 +1,128 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"strconv"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RevisionListResult struct {
+	Revisions []models.TaskRevision
+	Total     int64
+}
+
+// RevisionDiff 两个版本之间的差异，Diff 为正文的 unified diff
+type RevisionDiff struct {
+	From      int                 `json:"from"`
+	To        int                 `json:"to"`
+	TitleFrom string              `json:"title_from"`
+	TitleTo   string              `json:"title_to"`
+	Changes   models.FieldChanges `json:"changes"`
+	Diff      string              `json:"diff"`
+}
+
+func (t *TaskService) ListRevisions(ctx context.Context, lg *zap.Logger, uid, taskID, page, size int) (*RevisionListResult, error) {
+	if _, err := models.GetTaskByIDAndUID(ctx, taskID, uid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
+		}
+		lg.Error("task.revisions.task_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 20
+	}
+	items, total, err := models.ListTaskRevisions(ctx, uid, taskID, (page-1)*size, size)
+	if err != nil {
+		lg.Error("task.revisions.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	return &RevisionListResult{Revisions: items, Total: total}, nil
+}
+
+func (t *TaskService) GetRevision(ctx context.Context, lg *zap.Logger, uid, taskID, rev int) (*models.TaskRevision, error) {
+	r, err := models.GetTaskRevision(ctx, uid, taskID, rev)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "revision.not_found", Args: []any{rev}}
+		}
+		lg.Error("task.revision.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	return &r, nil
+}
+
+// DiffRevisions 比较任意两个版本，from 可以大于 to
+func (t *TaskService) DiffRevisions(ctx context.Context, lg *zap.Logger, uid, taskID, from, to, contextLines int) (*RevisionDiff, error) {
+	a, err := t.GetRevision(ctx, lg, uid, taskID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := t.GetRevision(ctx, lg, uid, taskID, to)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDiff{
+		From:      from,
+		To:        to,
+		TitleFrom: a.Title,
+		TitleTo:   b.Title,
+		Changes:   models.CompareRevisions(*a, *b),
+		Diff:      utils.UnifiedDiff("rev "+strconv.Itoa(from), "rev "+strconv.Itoa(to), a.ContentMD, b.ContentMD, contextLines),
+	}, nil
+}
+
+// RestoreRevision 将标题与正文恢复为指定版本，重新渲染 HTML，并记录为新版本
+func (t *TaskService) RestoreRevision(ctx context.Context, lg *zap.Logger, uid, taskID, rev int) (*UpdateTaskResult, error) {
+	lg.Info("task.revision.restore.begin", zap.Int("uid", uid), zap.Int("task_id", taskID), zap.Int("rev", rev))
+	task, err := models.GetTaskByIDAndUID(ctx, taskID, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
+		}
+		lg.Error("task.revision.restore.task_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	r, err := t.GetRevision(ctx, lg, uid, taskID, rev)
+	if err != nil {
+		return nil, err
+	}
+	contentHtml, err := utils.RenderSafeHTML([]byte(r.ContentMD))
+	if err != nil {
+		lg.Warn("task.revision.restore.md_render_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.markdown_invalid"}
+	}
+	update := map[string]interface{}{
+		"title":        r.Title,
+		"content_md":   r.ContentMD,
+		"content_html": contentHtml,
+	}
+	before, updated, affected, err := models.UpdateTaskWithRevision(ctx, update, taskID, uid,
+		models.RevisionMeta{Reason: models.RevisionRestore, RestoredFrom: &rev})
+	if err != nil {
+		if errors.Is(err, models.ErrTaskExists) {
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
+		}
+		lg.Error("task.revision.restore.update_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	if err := DelTaskDetailCache(ctx, uid, taskID); err != nil {
+		lg.Warn("redis.del.task_detail_failed", zap.Error(err))
+	}
+	if err := DelTaskListCache(ctx, uid, task.ProjectID); err != nil {
+		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", task.ProjectID))
+	}
+	syncTaskAssets(ctx, lg, uid, taskID, updated.ContentHtml)
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskUpdate(before, updated))
+	updated.ContentHtml = resolveAssets(ctx, lg, uid, updated.ContentHtml)
+	lg.Info("task.revision.restore.ok", zap.Int("task_id", taskID), zap.Int("rev", rev))
+	return &UpdateTaskResult{Task: updated, Affected: affected, UndoToken: token}, nil
+}
@@ -6,10 +6,12 @@ import (
 	"ToDoList/server/utils"
 	"context"
 	"errors"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -32,81 +34,115 @@ type CreateTaskInput struct {
 	ContentMD *string
 	Priority  *int
 	Status    *string
+	Tags      []string
 	StartAt   *time.Time
-	DueAt     *time.Time
+	DueAt     *string // 不带时区偏移时按用户时区解释
 }
 type CreateTaskResult struct {
-	Task models.Task
+	Task      models.Task
+	UndoToken string
 }
 type TaskDetail struct {
-	ID          int        `json:"id"`
-	UserID      int        `json:"user_id"`
-	ProjectID   int        `json:"project_id"`
-	Title       string     `json:"title"`
-	Status      string     `json:"status"`
-	ContentHtml string     `json:"content_html"`
-	DueAt       *time.Time `json:"due_at"`
+	ID          int             `json:"id"`
+	UserID      int             `json:"user_id"`
+	ProjectID   int             `json:"project_id"`
+	Title       string          `json:"title"`
+	Status      string          `json:"status"`
+	Tags        models.TaskTags `json:"tags"`
+	ContentHtml string          `json:"content_html"`
+	DueAt       *time.Time      `json:"due_at"`
+	ArchivedAt  *time.Time      `json:"archived_at"`
 }
 
 type TaskSummary struct {
-	ID     int        `json:"id"`
-	Title  string     `json:"title"`
-	Status string     `json:"status"`
-	DueAt  *time.Time `json:"due_at"`
+	ID     int             `json:"id"`
+	Title  string          `json:"title"`
+	Status string          `json:"status"`
+	Tags   models.TaskTags `json:"tags"`
+	DueAt  *time.Time      `json:"due_at"`
 }
 
 func (t *TaskService) Create(ctx context.Context, lg *zap.Logger, uid int, in CreateTaskInput) (*CreateTaskResult, error) {
 	lg.Info("task.create.begin",
 		zap.Int("uid", uid),
 		zap.Any("project_id", in.ProjectID),
-		zap.Int("priority", getOr(in.Priority, 3)),
-		zap.String("status", getOrStr(in.Status, "todo")),
+		zap.Int("priority", getOr(in.Priority, 0)),
+		zap.String("status", getOrStr(in.Status, "")),
 		zap.Int("title_len", len(strings.TrimSpace(in.Title))),
 		zap.Int("content_len", strlen(in.ContentMD)),
 	)
 	in.Title = strings.TrimSpace(in.Title)
+	var fields []utils.FieldError
 	if in.Title == "" {
 		lg.Warn("task.create.title_empty")
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "请输入任务名称"}
+		fields = append(fields, utils.NewFieldError("title", "required", "task.title_required"))
 	}
 	if in.Priority != nil && (*in.Priority < 1 || *in.Priority > 5) {
 		lg.Warn("task.create.priority_range_invalid", zap.Int("priority", *in.Priority))
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "优先级范围应为 1~5"}
+		fields = append(fields, utils.NewFieldError("priority", "range", "task.priority_range"))
+	}
+	tags, fe := normalizeTags("tags", in.Tags)
+	fields = append(fields, fe...)
+
+	prefs := loadPreferences(ctx, lg, uid)
+	if in.ProjectID == 0 {
+		if prefs.DefaultProjectID == nil {
+			lg.Warn("task.create.project_missing")
+			fields = append(fields, utils.NewFieldError("project_id", "required", "task.project_required"))
+		} else {
+			in.ProjectID = *prefs.DefaultProjectID
+		}
+	}
+	var dueAt, remindAt *time.Time
+	if in.DueAt != nil && strings.TrimSpace(*in.DueAt) != "" {
+		due, dateOnly, err := utils.ParseUserTime(*in.DueAt, prefs.Location())
+		if err != nil {
+			lg.Warn("task.create.due_at_invalid", zap.String("due_at", *in.DueAt))
+			fields = append(fields, utils.NewFieldError("due_at", "datetime", "task.due_at_invalid"))
+		} else {
+			dueAt = &due
+			remindAt = reminderAt(due, dateOnly, prefs)
+		}
 	}
 
-	if in.StartAt != nil && in.DueAt != nil && (*in.DueAt).Before(*in.StartAt) {
-		lg.Warn("task.create.time_order_invalid", zap.Timep("start_at", in.StartAt), zap.Timep("due_at", in.DueAt))
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "截止时间不能早于开始时间"}
+	if in.StartAt != nil && dueAt != nil && dueAt.Before(*in.StartAt) {
+		lg.Warn("task.create.time_order_invalid", zap.Timep("start_at", in.StartAt), zap.Timep("due_at", dueAt))
+		fields = append(fields, utils.NewFieldError("due_at", "after_start", "task.due_before_start"))
+	}
+	if len(fields) > 0 {
+		return nil, validationError(fields)
 	}
 	_, err := models.GetProjectByID(uid, in.ProjectID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Warn("task.create.project_id_invalid", zap.Int("project_id", in.ProjectID))
-			return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "项目不存在"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
 		}
 		lg.Error("task.create.project_query_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	statuses, err := models.ListProjectStatuses(ctx, uid, in.ProjectID)
+	if err != nil {
+		lg.Error("task.create.status_query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	// 未指定状态时使用项目中排在最前的未完成状态
+	status, ok := resolveTaskStatus(statuses, in.Status, "", models.StatusOpen)
+	if !ok {
+		lg.Warn("task.create.status_invalid", zap.String("status", getOrStr(in.Status, "")))
+		return nil, validationError([]utils.FieldError{utils.NewFieldError("status", "oneof", "task.status_invalid")})
 	}
 	exists, err := models.GetTaskByUserProjectTitle(uid, in.ProjectID, in.Title)
 	if err != nil {
 		lg.Error("task.create.check_unique_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
 	}
 	if exists.ID != 0 {
 		lg.Info("task.create.duplicate", zap.Int("exists_id", exists.ID))
-		return nil, &AppError{Code: utils.ErrCodeConflict, Message: "该任务已存在"}
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
 	}
 
-	status := "todo"
-	if in.Status != nil {
-		s := strings.TrimSpace(*in.Status)
-		if s != "todo" && s != "done" {
-			lg.Warn("task.create.status_invalid", zap.String("status", s))
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "任务状态错误"}
-		}
-		status = s
-	}
-	priority := 3
+	priority := prefs.DefaultPriority
 	if in.Priority != nil {
 		priority = *in.Priority
 	}
@@ -117,28 +153,38 @@ func (t *TaskService) Create(ctx context.Context, lg *zap.Logger, uid int, in Cr
 	contentHtml := ""
 	if contentHtml, err = utils.RenderSafeHTML([]byte(contented)); err != nil {
 		lg.Warn("task.create.md_render_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "markdown内容出错"}
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.markdown_invalid"}
 	}
 	task := models.Task{
 		UserID:      uid,
 		ProjectID:   in.ProjectID,
 		Title:       in.Title,
 		ContentMD:   contented,
-		Status:      status,
+		Status:      status.Key,
 		Priority:    priority,
-		DueAt:       in.DueAt,
+		Tags:        tags,
+		DueAt:       dueAt,
+		RemindAt:    remindAt,
+		Notified:    dueAt != nil && remindAt == nil, // 关闭提醒的任务不再进入提醒扫描
 		ContentHtml: contentHtml,
 	}
+	if status.Category == models.StatusClosed {
+		now := time.Now()
+		task.CompletedAt = &now
+	}
 	created, err := models.CreateTaskByUidAndTask(uid, task)
 	if err != nil {
 		if errors.Is(err, models.ErrTaskExists) {
 			lg.Info("task.create.duplicate_on_insert")
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "该任务已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
 		}
 		lg.Error("task.create.insert_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "创建失败，请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.create_failed"}
 	}
-	return &CreateTaskResult{Task: created}, nil
+	syncTaskAssets(ctx, lg, uid, created.ID, created.ContentHtml)
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskCreate(created))
+	created.ContentHtml = resolveAssets(ctx, lg, uid, created.ContentHtml)
+	return &CreateTaskResult{Task: created, UndoToken: token}, nil
 }
 
 type UpdateTaskInput struct {
@@ -147,31 +193,33 @@ type UpdateTaskInput struct {
 	ContentMD *string
 	Priority  *int
 	Status    *string
-	SortOrder *int64
-	ReDueAt   *time.Time
+	Tags      *[]string // 整体替换；批量增删使用 /tasks/bulk 的 tag 操作
+	ReDueAt   *string   // 不带时区偏移时按用户时区解释
 }
 type UpdateTaskResult struct {
-	Task     models.Task
-	Affected int64
+	Task      models.Task
+	Affected  int64
+	UndoToken string
 }
 
 func (t *TaskService) Update(ctx context.Context, lg *zap.Logger, uid, pid int, id int, in UpdateTaskInput) (*UpdateTaskResult, error) {
-	_, err := models.GetTaskByIDAndProjectIDAndUID(id, uid, pid)
+	cur, err := models.GetTaskByIDAndProjectIDAndUID(id, uid, pid)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Info("task.update.not_found", zap.Int("task_id", id))
-			return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "任务不存在"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
 		}
 		lg.Error("task.update.query_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
 	}
 
 	update := map[string]interface{}{}
+	var fields []utils.FieldError
 	if in.Title != nil {
 		title := strings.TrimSpace(*in.Title)
 		if title == "" {
 			lg.Warn("task.update.title_empty")
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "任务名称不能为空"}
+			fields = append(fields, utils.NewFieldError("title", "required", "task.title_required"))
 		}
 		update["title"] = title
 	}
@@ -181,7 +229,7 @@ func (t *TaskService) Update(ctx context.Context, lg *zap.Logger, uid, pid int,
 		contentHtml := ""
 		if contentHtml, err = utils.RenderSafeHTML([]byte(*in.ContentMD)); err != nil {
 			lg.Warn("task.update.md_render_failed", zap.Error(err))
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "markdown内容出错"}
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.markdown_invalid"}
 		}
 		update["content_html"] = contentHtml
 	}
@@ -189,58 +237,91 @@ func (t *TaskService) Update(ctx context.Context, lg *zap.Logger, uid, pid int,
 	if in.Priority != nil {
 		if *in.Priority < 1 || *in.Priority > 5 {
 			lg.Warn("task.update.priority_invalid", zap.Int("priority", *in.Priority))
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "优先级范围应为 1~5"}
+			fields = append(fields, utils.NewFieldError("priority", "range", "task.priority_range"))
 		}
 		update["priority"] = *in.Priority
 	}
-
-	if in.Status != nil {
-		s := strings.TrimSpace(*in.Status)
-		if s != "todo" && s != "done" {
-			lg.Warn("task.update.status_invalid", zap.String("status", s))
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "任务状态错误"}
-		}
-		update["status"] = s
+	if in.Tags != nil {
+		tags, fe := normalizeTags("tags", *in.Tags)
+		fields = append(fields, fe...)
+		update["tags"] = tags
 	}
 
-	if in.SortOrder != nil && *in.SortOrder >= 0 {
-		update["sort_order"] = *in.SortOrder
-	}
 	if in.ReDueAt != nil {
-		if in.ReDueAt.Before(time.Now()) {
-			lg.Warn("task.update.time_order_invalid", zap.Any("DueAt", *in.ReDueAt))
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "截止时间不能早于开始时间"}
+		prefs := loadPreferences(ctx, lg, uid)
+		due, dateOnly, err := utils.ParseUserTime(*in.ReDueAt, prefs.Location())
+		switch {
+		case err != nil:
+			lg.Warn("task.update.due_at_invalid", zap.String("due_at", *in.ReDueAt))
+			fields = append(fields, utils.NewFieldError("re_due_at", "datetime", "task.due_at_invalid"))
+		case due.Before(time.Now()):
+			lg.Warn("task.update.time_order_invalid", zap.Time("DueAt", due))
+			fields = append(fields, utils.NewFieldError("re_due_at", "after_now", "task.due_in_past"))
+		default:
+			remindAt := reminderAt(due, dateOnly, prefs)
+			update["due_at"] = due
+			update["remind_at"] = remindAt
+			update["notified"] = remindAt == nil
 		}
-		update["due_at"] = *in.ReDueAt
 	}
 	if in.ProjectID != nil {
 		if *in.ProjectID <= 0 {
 			lg.Warn("task.update.project_id_invalid", zap.Int("re_project_id", *in.ProjectID))
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "项目号不合法"}
+			return nil, validationError(append(fields, utils.NewFieldError("re_project_id", "gt", "project.id_invalid")))
+		}
+		if len(fields) > 0 {
+			return nil, validationError(fields)
 		}
 		if _, err := models.GetProjectByID(uid, *in.ProjectID); err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				lg.Info("task.update.project_not_found", zap.Int("re_project_id", *in.ProjectID))
-				return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "项目不存在"}
+				return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
 			}
 			lg.Error("task.update.project_query_failed", zap.Error(err))
-			return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后重试"}
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
 		}
 		update["project_id"] = *in.ProjectID
 	}
+	if len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+	// 修改状态或移动到其他项目时按目标项目的状态集确定状态；重新打开的任务回到列表，再次完成时重新计算归档时间
+	if in.Status != nil || (in.ProjectID != nil && *in.ProjectID != cur.ProjectID) {
+		target := cur.ProjectID
+		if in.ProjectID != nil {
+			target = *in.ProjectID
+		}
+		statuses, err := models.ListProjectStatuses(ctx, uid, target)
+		if err != nil {
+			lg.Error("task.update.status_query_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+		}
+		category := models.StatusOpen
+		if cur.CompletedAt != nil {
+			category = models.StatusClosed
+		}
+		st, ok := resolveTaskStatus(statuses, in.Status, cur.Status, category)
+		if !ok {
+			lg.Warn("task.update.status_invalid", zap.String("status", getOrStr(in.Status, cur.Status)))
+			return nil, validationError([]utils.FieldError{utils.NewFieldError("status", "oneof", "task.status_invalid")})
+		}
+		for k, v := range models.StatusColumns(st) {
+			update[k] = v
+		}
+	}
 	if len(update) == 0 {
 		lg.Info("task.update.noop")
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "没有需要更新的字段"}
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "common.no_fields"}
 	}
 
-	updated, affected, err := models.UpdateTaskByIDAndUID(update, id, uid)
+	before, updated, affected, err := models.UpdateTaskWithRevision(ctx, update, id, uid, models.RevisionMeta{Reason: models.RevisionUpdate})
 	if err != nil {
 		if errors.Is(err, models.ErrTaskExists) {
 			lg.Info("task.update.duplicate_on_update")
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "任务已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
 		}
 		lg.Error("task.update.update_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "更新失败，请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
 	}
 
 	err = DelTaskDetailCache(ctx, uid, id)
@@ -248,38 +329,49 @@ func (t *TaskService) Update(ctx context.Context, lg *zap.Logger, uid, pid int,
 		lg.Warn("redis.del.task_detail_failed", zap.Error(err))
 	}
 
-	err = DelTaskSummaryCache(ctx, uid, pid, "all")
+	err = DelTaskListCache(ctx, uid, pid)
 	if err != nil {
 		lg.Warn("redis.del.task_oldsummary_failed", zap.Error(err), zap.Int("pid", pid))
 	}
 	if repid, ok := update["project_id"]; ok {
-		err = DelTaskSummaryCache(ctx, uid, repid.(int), "all")
+		err = DelTaskListCache(ctx, uid, repid.(int))
 		if err != nil {
 			lg.Warn("redis.del.task_resummary_failed", zap.Error(err), zap.Int("pid", repid.(int)))
 		}
 	}
-	return &UpdateTaskResult{Task: updated, Affected: affected}, nil
+	if in.ContentMD != nil {
+		syncTaskAssets(ctx, lg, uid, id, updated.ContentHtml)
+	}
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskUpdate(before, updated))
+	updated.ContentHtml = resolveAssets(ctx, lg, uid, updated.ContentHtml)
+	return &UpdateTaskResult{Task: updated, Affected: affected, UndoToken: token}, nil
 }
-func (t *TaskService) Delete(ctx context.Context, lg *zap.Logger, uid int, pid int, id int) (int64, error) {
+type DeleteTaskResult struct {
+	Affected  int64
+	UndoToken string
+}
+
+func (t *TaskService) Delete(ctx context.Context, lg *zap.Logger, uid int, pid int, id int) (*DeleteTaskResult, error) {
 	lg.Info("task.delete.begin", zap.Int("uid", uid), zap.Int("task_id", id), zap.Any("project_id", pid))
 	affected, err := models.DeleteByIDAndProjectIDAndUID(id, pid, uid)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Info("task.delete.not_found", zap.Int("task_id", id))
-			return 0, &AppError{Code: utils.ErrCodeNotFound, Message: "任务不存在或已删除"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found_or_deleted"}
 		}
 		lg.Error("task.delete.failed", zap.Error(err))
-		return 0, &AppError{Code: utils.ErrCodeInternalServer, Message: "删除失败请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.delete_failed"}
 	}
 	err = DelTaskDetailCache(ctx, uid, id)
 	if err != nil {
 		lg.Warn("redis.del.task_detail_failed", zap.Error(err))
 	}
-	err = DelTaskSummaryCache(ctx, uid, pid, "all")
+	err = DelTaskListCache(ctx, uid, pid)
 	if err != nil {
 		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", pid))
 	}
-	return affected, nil
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskDelete(id))
+	return &DeleteTaskResult{Affected: affected, UndoToken: token}, nil
 }
 func (t *TaskService) Search(ctx context.Context, lg *zap.Logger, id, uid, pid int) (*TaskDetail, error) {
 	lg.Info("task.search.begin", zap.Int("uid", uid), zap.Int("task_id", id))
@@ -288,6 +380,7 @@ func (t *TaskService) Search(ctx context.Context, lg *zap.Logger, id, uid, pid i
 	if err != nil {
 		lg.Warn("redis.get.task_detail_failed", zap.Error(err))
 	} else {
+		td.ContentHtml = resolveAssets(ctx, lg, uid, td.ContentHtml)
 		return td, nil
 	}
 	//降级查db
@@ -295,10 +388,10 @@ func (t *TaskService) Search(ctx context.Context, lg *zap.Logger, id, uid, pid i
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Info("task.search.not_found", zap.Int("task_id", id))
-			return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "任务不存在"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "task.not_found"}
 		}
 		lg.Error("task.search.query_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
 	}
 	//回填redis
 	td = &TaskDetail{
@@ -307,13 +400,16 @@ func (t *TaskService) Search(ctx context.Context, lg *zap.Logger, id, uid, pid i
 		ProjectID: task.ProjectID,
 		Title:       task.Title,
 		Status:      task.Status,
+		Tags:        task.Tags,
 		ContentHtml: task.ContentHtml ,
 		DueAt:       task.DueAt,
+		ArchivedAt:  task.ArchivedAt,
 	}
 	err = SetaskDetailCache(ctx, td)
 	if err != nil {
 		lg.Warn("redis.get.task_detail_failed", zap.Error(err))
 	}
+	td.ContentHtml = resolveAssets(ctx, lg, uid, td.ContentHtml)
 	return td, err
 }
 
@@ -321,6 +417,7 @@ type TaskListInput struct {
 	Page   int
 	Size   int
 	Status string
+	Tag    string
 	Pid    int
 }
 type TaskListResult struct {
@@ -329,20 +426,14 @@ type TaskListResult struct {
 }
 
 func (t *TaskService) List(ctx context.Context, lg *zap.Logger, uid int, in TaskListInput) (*TaskListResult, error) {
-	//查询redis缓存的当前uid和pid所属的allTask
-	allts, err := GetTaskSummaryCache(ctx, uid, in.Pid, in.Status)
-	if err != nil {
-		lg.Warn("task.list.getcachesummary_error", zap.Int("Uid", uid), zap.Int("Pid", in.Pid))
-	}
-	rts, rtotal, err := PageTaskSummaries(allts.Items, in.Page, in.Size)
-	if err == nil {
-		return &TaskListResult{Tasks: rts, Total: rtotal}, err
-	}
-
-	//降级查询mysql
-	if in.Status != "todo" && in.Status != "done" && in.Status != "" {
+	if in.Status != "" && !statusKeyPattern.MatchString(in.Status) {
 		lg.Warn("task.list.status_invalid", zap.String("status", in.Status))
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "任务状态错误"}
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.status_invalid"}
+	}
+	in.Tag = strings.TrimSpace(in.Tag)
+	if utf8.RuneCountInString(in.Tag) > models.TaskTagMaxLen {
+		lg.Warn("task.list.tag_invalid", zap.Int("tag_len", len(in.Tag)))
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "task.tag_too_long", Args: []any{in.Tag, models.TaskTagMaxLen}}
 	}
 	if in.Page < 1 {
 		in.Page = 1
@@ -350,21 +441,35 @@ func (t *TaskService) List(ctx context.Context, lg *zap.Logger, uid int, in Task
 	if in.Size <= 0 || in.Size > 100 {
 		in.Size = 20
 	}
+	//查询redis缓存的当前uid和pid所属的allTask；按标签筛选时不使用缓存
+	allts, err := GetTaskSummaryCache(ctx, uid, in.Pid, in.Status)
+	if in.Tag != "" {
+		err = redis.Nil
+	}
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			lg.Warn("task.list.getcachesummary_error", zap.Int("Uid", uid), zap.Int("Pid", in.Pid), zap.Error(err))
+		}
+	} else {
+		rts, rtotal, _ := PageTaskSummaries(allts.Items, in.Page, in.Size)
+		return &TaskListResult{Tasks: rts, Total: rtotal}, nil
+	}
 
+	//降级查询mysql
 	_, err = models.GetProjectByID(uid, in.Pid)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			lg.Info("task.list.project_not_found", zap.Int("project_id", in.Pid))
-			return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "项目不存在"}
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "project.not_found"}
 		}
 		lg.Error("task.list.project_query_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
 	}
 
-	tasks, total, err := models.TaskListAll(uid, in.Pid, in.Status)
+	tasks, total, err := models.TaskListAll(uid, in.Pid, in.Status, in.Tag)
 	if err != nil {
 		lg.Error("task.list.query_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "获取任务列表信息出错"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "task.list_failed"}
 	}
 
 	res := make([]TaskSummary, len(tasks))
@@ -373,6 +478,7 @@ func (t *TaskService) List(ctx context.Context, lg *zap.Logger, uid int, in Task
 			ID:     tasks[i].ID,
 			Title:  tasks[i].Title,
 			Status: tasks[i].Status,
+			Tags:   tasks[i].Tags,
 			DueAt:  tasks[i].DueAt,
 		}
 	}
@@ -380,15 +486,17 @@ func (t *TaskService) List(ctx context.Context, lg *zap.Logger, uid int, in Task
 	ts, total, err := PageTaskSummaries(res, in.Page, in.Size)
 	if err != nil {
 		lg.Error("task.list.page_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "获取任务列表信息出错"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "task.list_failed"}
 	}
 
-	err = SetTaskSummaryCache(ctx, uid, in.Pid, in.Status, total, res)
-	if err != nil {
-		lg.Warn("task.list.setsummarycache_error", zap.Int("Uid", uid), zap.Int("Pid", in.Pid))
+	if in.Tag == "" {
+		err = SetTaskSummaryCache(ctx, uid, in.Pid, in.Status, total, res)
+		if err != nil {
+			lg.Warn("task.list.setsummarycache_error", zap.Int("Uid", uid), zap.Int("Pid", in.Pid))
+		}
 	}
 
-	return &TaskListResult{Tasks: ts, Total: total}, err
+	return &TaskListResult{Tasks: ts, Total: total}, nil
 }
 
 func (t *TaskService) checkAndNotifyDue(ctx context.Context, lg *zap.Logger) {
@@ -419,7 +527,8 @@ func (t *TaskService) checkAndNotifyDue(ctx context.Context, lg *zap.Logger) {
         lg.Info("due_watcher.notify",
             zap.Int("task_id", t.ID),
             zap.Int("uid", t.UserID),
-            zap.Time("due_at", *t.DueAt),
+            zap.Timep("due_at", t.DueAt),
+            zap.Timep("remind_at", t.RemindAt),
         )
 	}
 }
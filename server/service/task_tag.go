@@ -0,0 +1,18 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+)
+
+// normalizeTags 整理请求中的标签，field 为出错时返回的字段名
+func normalizeTags(field string, tags []string) (models.TaskTags, []utils.FieldError) {
+	out, bad, ok := models.NormalizeTags(tags)
+	if !ok {
+		return nil, []utils.FieldError{utils.NewFieldError(field, "max", "task.tag_too_long", bad, models.TaskTagMaxLen)}
+	}
+	if len(out) > models.TaskTagLimit {
+		return nil, []utils.FieldError{utils.NewFieldError(field, "max", "task.tags_too_many", models.TaskTagLimit)}
+	}
+	return out, nil
+}
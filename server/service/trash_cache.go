@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+const trashPurgeLockKey = "trash:purge:lock"
+
+// AcquireTrashPurgeLock 同一时间只允许一个实例清理回收站
+func AcquireTrashPurgeLock(ctx context.Context, ttl time.Duration) (bool, error) {
+	return c.Rdb.SetNX(ctx, trashPurgeLockKey, 1, ttl).Result()
+}
+
+func ReleaseTrashPurgeLock(ctx context.Context) error {
+	return c.Rdb.Del(ctx, trashPurgeLockKey).Err()
+}
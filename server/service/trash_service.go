@@ -0,0 +1,186 @@
+package service
+
+import (
+	"ToDoList/server/async"
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	trashPurgeTimeout = 10 * time.Minute
+	trashPurgeBatch   = 200 // 每轮每类最多永久删除的条数
+	trashPurgeRounds  = 20
+)
+
+type TrashService struct {
+	bus *async.EventBus
+}
+
+func NewTrashService(bus *async.EventBus) *TrashService {
+	return &TrashService{bus: bus}
+}
+
+// TrashEntry 回收站条目，PurgeAt 为预计永久删除的时间
+type TrashEntry struct {
+	models.TrashItem
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+type TrashListResult struct {
+	Items []TrashEntry
+	Total int64
+}
+
+func (s *TrashService) List(ctx context.Context, lg *zap.Logger, uid int, kind string, page, size int) (*TrashListResult, error) {
+	if kind != "" && kind != models.TrashTask && kind != models.TrashProject {
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "trash.type_invalid"}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 20
+	}
+	items, total, err := models.ListTrash(ctx, uid, kind, (page-1)*size, size)
+	if err != nil {
+		lg.Error("trash.list.query_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	res := make([]TrashEntry, len(items))
+	for i, it := range items {
+		res[i] = TrashEntry{TrashItem: it, PurgeAt: it.DeletedAt.Add(config.TrashRetention)}
+	}
+	return &TrashListResult{Items: res, Total: total}, nil
+}
+
+type RestoreTaskResult struct {
+	Task      models.Task
+	UndoToken string
+}
+
+func (s *TrashService) RestoreTask(ctx context.Context, lg *zap.Logger, uid, id int) (*RestoreTaskResult, error) {
+	lg.Info("trash.restore_task.begin", zap.Int("uid", uid), zap.Int("task_id", id))
+	task, err := models.RestoreTask(ctx, uid, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "trash.not_found"}
+		case errors.Is(err, models.ErrProjectInTrash):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "trash.project_deleted"}
+		case errors.Is(err, models.ErrTaskExists):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
+		}
+		lg.Error("trash.restore_task.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	if err := DelTaskListCache(ctx, uid, task.ProjectID); err != nil {
+		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", task.ProjectID))
+	}
+	token := issueUndo(ctx, lg, uid, utils.ScopeTasksWrite, models.UndoTaskCreate(task))
+	task.ContentHtml = resolveAssets(ctx, lg, uid, task.ContentHtml)
+	lg.Info("trash.restore_task.ok", zap.Int("task_id", id))
+	return &RestoreTaskResult{Task: task, UndoToken: token}, nil
+}
+
+type RestoreProjectResult struct {
+	Project   models.Project
+	TaskIDs   []int
+	UndoToken string
+}
+
+func (s *TrashService) RestoreProject(ctx context.Context, lg *zap.Logger, uid, id int) (*RestoreProjectResult, error) {
+	lg.Info("trash.restore_project.begin", zap.Int("uid", uid), zap.Int("project_id", id))
+	project, taskIDs, err := models.RestoreProject(ctx, uid, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "trash.not_found"}
+		case errors.Is(err, models.ErrProjectExists):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "project.exists"}
+		case errors.Is(err, models.ErrTaskExists):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
+		}
+		lg.Error("trash.restore_project.failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+	if err := IncrProjectsVer(ctx, c.Rdb, uid); err != nil {
+		lg.Warn("trash.restore_project.incr_ver_failed", zap.Error(err))
+	}
+	if err := DelTaskListCache(ctx, uid, id); err != nil {
+		lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", id))
+	}
+	lg.Info("trash.restore_project.ok", zap.Int("project_id", id), zap.Int("tasks", len(taskIDs)))
+	return &RestoreProjectResult{
+		Project:   project,
+		TaskIDs:   taskIDs,
+		UndoToken: issueUndo(ctx, lg, uid, utils.ScopeProjectsWrite, models.UndoProjectCreate(project)),
+	}, nil
+}
+
+// StartTrashPurger 定期永久删除超过保留期的项目与任务
+func (s *TrashService) StartTrashPurger(ctx context.Context, lg *zap.Logger) {
+	if config.TrashPurgeInterval <= 0 {
+		lg.Info("trash_purger.disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(config.TrashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				lg.Info("trash_purger.stopped")
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), trashPurgeTimeout)
+				s.purge(ctx, lg)
+				cancel()
+			}
+		}
+	}()
+}
+
+// purge 分批删除直到没有过期条目，附件对象通过 DeleteCOS 异步删除；正文图片解除引用后由对象清理任务回收
+func (s *TrashService) purge(ctx context.Context, lg *zap.Logger) {
+	ok, err := AcquireTrashPurgeLock(ctx, trashPurgeTimeout)
+	if err != nil {
+		lg.Warn("trash_purger.lock_failed", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := ReleaseTrashPurgeLock(context.Background()); err != nil {
+			lg.Warn("trash_purger.unlock_failed", zap.Error(err))
+		}
+	}()
+
+	before := time.Now().Add(-config.TrashRetention)
+	var projects, tasks int64
+	for i := 0; i < trashPurgeRounds; i++ {
+		res, err := models.PurgeTrash(ctx, before, trashPurgeBatch)
+		projects += res.Projects
+		tasks += res.Tasks
+		for uid, keys := range res.ObjectKeys {
+			deleteObjects(s.bus, lg, uid, keys)
+		}
+		if err != nil {
+			lg.Error("trash_purger.db_failed", zap.Error(err))
+			break
+		}
+		if res.Projects == 0 && res.Tasks == 0 {
+			break
+		}
+	}
+	if projects > 0 || tasks > 0 {
+		audit(lg, "trash_purged", zap.Int64("projects", projects), zap.Int64("tasks", tasks))
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"ToDoList/server/models"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// UndoRecord 撤销令牌对应的逆操作，Scope 为原操作所需的权限
+type UndoRecord struct {
+	UserID int               `json:"uid"`
+	Scope  string            `json:"scope"`
+	Steps  []models.UndoStep `json:"steps"`
+}
+
+func undoKey(token string) string {
+	return "undo:" + token
+}
+
+// undoClaimedKey 执行中的令牌，保留原有效期；进程中途退出时随有效期自动过期
+func undoClaimedKey(token string) string {
+	return "undo:claimed:" + token
+}
+
+// moveUndoScript 源 key 存在时改名为目标 key，返回是否移动
+var moveUndoScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("RENAME", KEYS[1], KEYS[2])
+return 1
+`)
+
+func PutUndo(ctx context.Context, token string, rec UndoRecord, ttl time.Duration) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return c.Rdb.Set(ctx, undoKey(token), b, ttl).Err()
+}
+
+func GetUndo(ctx context.Context, token string) (*UndoRecord, error) {
+	b, err := c.Rdb.Get(ctx, undoKey(token)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var rec UndoRecord
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// ClaimUndo 原子地占用令牌，并发的两次撤销只有一次成功
+func ClaimUndo(ctx context.Context, token string) (bool, error) {
+	n, err := moveUndoScript.Run(ctx, c.Rdb, []string{undoKey(token), undoClaimedKey(token)}).Int()
+	return n == 1, err
+}
+
+// ReleaseUndo 撤销没有生效时归还令牌，之后可以再次撤销
+func ReleaseUndo(ctx context.Context, token string) error {
+	return moveUndoScript.Run(ctx, c.Rdb, []string{undoClaimedKey(token), undoKey(token)}).Err()
+}
+
+// ConsumeUndo 撤销提交后删除令牌
+func ConsumeUndo(ctx context.Context, token string) error {
+	return c.Rdb.Del(ctx, undoClaimedKey(token)).Err()
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"ToDoList/server/config"
+	"ToDoList/server/models"
+	"ToDoList/server/utils"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UndoService struct{}
+
+func NewUndoService() *UndoService {
+	return &UndoService{}
+}
+
+// issueUndo 保存逆操作并返回撤销令牌；保存失败不影响原操作，只是无法撤销
+func issueUndo(ctx context.Context, lg *zap.Logger, uid int, scope string, steps ...models.UndoStep) string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		lg.Warn("undo.token_failed", zap.Error(err))
+		return ""
+	}
+	token := hex.EncodeToString(buf)
+	if err := PutUndo(ctx, token, UndoRecord{UserID: uid, Scope: scope, Steps: steps}, config.UndoTTL); err != nil {
+		lg.Warn("undo.save_failed", zap.Error(err))
+		return ""
+	}
+	return token
+}
+
+// Undo 占用令牌并在一个事务中执行逆操作；提交后令牌作废，
+// 记录已被再次修改时令牌同样作废，其余失败归还令牌，可以重试
+func (s *UndoService) Undo(ctx context.Context, lg *zap.Logger, uid int, scopes []string, token string) (*models.UndoResult, error) {
+	rec, err := GetUndo(ctx, token)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "undo.expired"}
+		}
+		lg.Error("undo.load_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if rec.UserID != uid {
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "undo.expired"}
+	}
+	if !utils.HasScope(scopes, rec.Scope) {
+		lg.Warn("undo.scope_denied", zap.String("required", rec.Scope))
+		return nil, &AppError{Code: utils.ErrCodeForbidden, Key: "auth.forbidden"}
+	}
+	ok, err := ClaimUndo(ctx, token)
+	if err != nil {
+		lg.Error("undo.claim_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.retry_later"}
+	}
+	if !ok {
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "undo.expired"}
+	}
+
+	res, err := models.ApplyUndo(ctx, uid, rec.Steps)
+	// 请求被取消时仍要处理令牌
+	tctx := context.WithoutCancel(ctx)
+	if err != nil && !errors.Is(err, models.ErrUndoStale) && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if rerr := ReleaseUndo(tctx, token); rerr != nil {
+			lg.Warn("undo.release_failed", zap.Error(rerr))
+		}
+	} else if cerr := ConsumeUndo(tctx, token); cerr != nil {
+		lg.Warn("undo.consume_failed", zap.Error(cerr))
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUndoStale), errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "undo.stale"}
+		case errors.Is(err, models.ErrTaskExists):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "task.exists"}
+		case errors.Is(err, models.ErrProjectExists):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "project.exists"}
+		case errors.Is(err, models.ErrStatusExists):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "status.exists"}
+		case errors.Is(err, models.ErrProjectInTrash):
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "trash.project_deleted"}
+		}
+		lg.Error("undo.apply_failed", zap.Error(err))
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
+	}
+
+	for _, id := range res.TaskIDs {
+		if err := DelTaskDetailCache(ctx, uid, id); err != nil {
+			lg.Warn("redis.del.task_detail_failed", zap.Error(err), zap.Int("task_id", id))
+		}
+	}
+	for _, pid := range res.ProjectIDs {
+		if err := DelTaskListCache(ctx, uid, pid); err != nil {
+			lg.Warn("redis.del.task_summary_failed", zap.Error(err), zap.Int("pid", pid))
+		}
+	}
+	if res.ProjectsChanged {
+		if err := IncrProjectsVer(ctx, c.Rdb, uid); err != nil {
+			lg.Warn("undo.incr_projects_ver_failed", zap.Error(err))
+		}
+		if err := DelPreferencesCache(ctx, uid); err != nil {
+			lg.Warn("redis.del.prefs_failed", zap.Error(err))
+		}
+	}
+	for _, t := range res.Updated {
+		syncTaskAssets(ctx, lg, uid, t.ID, t.ContentHtml)
+	}
+	lg.Info("undo.ok", zap.Int("steps", len(rec.Steps)), zap.Ints("task_ids", res.TaskIDs), zap.Ints("project_ids", res.ProjectIDs))
+	return &res, nil
+}
@@ -16,54 +16,98 @@ import (
 	"gorm.io/gorm"
 )
 
+// AppError 业务错误，Key 为稳定的错误键，文案在响应时按请求语言翻译
 type AppError struct {
-	Code    int
-	Message string
+	Code   int
+	Key    string
+	Args   []any
+	Fields []utils.FieldError // 字段级校验错误
 }
 
-func (e *AppError) Error() string { return e.Message }
+func (e *AppError) Error() string { return utils.Translate(utils.DefaultLocale, e.Key, e.Args...) }
+
+// validationError 汇总字段级校验错误
+func validationError(fields []utils.FieldError) *AppError {
+	return &AppError{Code: utils.ErrCodeValidation, Key: "common.validation_failed", Fields: fields}
+}
 
 type UserService struct {
-	bus *async.EventBus
+	bus  *async.EventBus
+	totp utils.TOTP
 }
 
 func NewUserService(bus *async.EventBus) *UserService {
-	return &UserService{bus: bus}
+	return &UserService{bus: bus, totp: utils.DefaultTOTP}
 }
 
 type LoginResult struct {
 	AccessToken    string
 	AccessExpireAt time.Time
+	// 开启两步验证时只返回挑战令牌
+	MFARequired bool
+	MFAToken    string
+	MFAExpireAt time.Time
+	// 宽限期内登录会撤销注销申请
+	DeletionCanceled bool
 }
 
-func (s *UserService) Login(ctx context.Context, lg *zap.Logger, username, password string) (*LoginResult, error) {
+func (s *UserService) Login(ctx context.Context, lg *zap.Logger, username, password, ip string) (*LoginResult, error) {
 	username = strings.TrimSpace(username)
 	lg = lg.With(zap.String("username", username))
 	lg.Info("login.begin")
+
+	if err := s.checkLoginAllowed(ctx, lg, username, ip); err != nil {
+		return nil, err
+	}
 	
 	user, err := models.GetUserInfoByUsername(ctx, username)
 	if err != nil {
 		lg.Error("login.query_user_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "系统错误，请稍后重试"} 
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"} 
 	}
 	if user.ID == 0 {
 		lg.Warn("login.user_not_found")
-		return nil, &AppError{Code: utils.ErrCodeNotFound, Message: "用户不存在"} 
+		s.recordLoginFailure(ctx, lg, username, ip, "user_not_found")
+		return nil, &AppError{Code: utils.ErrCodeNotFound, Key: "user.not_found"} 
 	}
 	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
 		lg.Warn("login.password_mismatch")
-		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Message: "用户名或密码有误，请重新输入"} 
+		s.recordLoginFailure(ctx, lg, username, ip, "password_mismatch")
+		return nil, &AppError{Code: utils.ErrCodeAuthFailed, Key: "auth.bad_credentials"} 
+	}
+
+	return s.issueLogin(ctx, lg, user, ip, "password")
+}
+
+// issueLogin 身份校验通过后签发令牌，开启两步验证时改为返回挑战令牌
+func (s *UserService) issueLogin(ctx context.Context, lg *zap.Logger, user models.User, ip string, method string) (*LoginResult, error) {
+	if user.TOTPEnabled {
+		mfaToken, mfaExp, err := utils.GenerateMFAToken(user.ID, user.Username, user.TokenVersion)
+		if err != nil {
+			lg.Error("login.mfa_token_issue_failed", zap.Error(err))
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "auth.token_issue_failed"}
+		}
+		lg.Info("login.mfa_required", zap.Int("uid", user.ID))
+		return &LoginResult{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			MFAExpireAt: mfaExp,
+		}, nil
 	}
 
-	token, exp, err := utils.GenerateAccessToken(user.ID, user.Username, user.TokenVersion)
+	token, exp, err := utils.GenerateAccessToken(user.ID, user.Username, user.Role, user.TokenVersion)
 	if err != nil {
 		lg.Error("login.jwt_issue_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "令牌生成失败"} 
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "auth.token_issue_failed"} 
 	}
+	s.clearLoginFailures(ctx, lg, user.Username)
+	canceled := s.cancelPendingDeletion(ctx, lg, user)
+	audit(lg, "login_succeeded", zap.Int("uid", user.ID), zap.String("ip", ip), zap.String("method", method))
 	lg.Info("login.success", zap.Int("uid", user.ID), zap.Time("access_exp", exp))
 	return &LoginResult{
-		AccessToken:    token,
-		AccessExpireAt: exp,
+		AccessToken:      token,
+		AccessExpireAt:   exp,
+		DeletionCanceled: canceled,
 	}, nil
 }
 
@@ -80,55 +124,57 @@ func (s *UserService) Register(ctx context.Context, lg *zap.Logger, email, usern
 	exists, err := models.GetUserInfoByUsername(ctx, username)
 	if err != nil {
 		lg.Error("register.check_username_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "系统错误，请稍后再试"} 
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"} 
 	}
 	if exists.ID != 0 {
 		lg.Info("register.username_exists")
-		return nil, &AppError{Code: utils.ErrCodeConflict, Message: "用户名已存在"} 
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "user.username_exists"} 
 	}
 
 	exists, err = models.GetUserInfoByEmail(ctx, email)
 	if err != nil {
 		lg.Error("register.check_email_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "系统错误，请稍后再试"} 
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"} 
 	}
 	if exists.ID != 0 {
 		lg.Info("register.email_exists")
-		return nil, &AppError{Code: utils.ErrCodeConflict, Message: "邮箱已被注册"} 
+		return nil, &AppError{Code: utils.ErrCodeConflict, Key: "user.email_registered"} 
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		lg.Error("register.password_hash_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "密码处理失败"} 
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "user.password_hash_failed"} 
 	}
 
 	if avatarFile == nil {
 		lg.Error("register.avatar_missing")
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "请上传头像"} 
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.avatar_required"} 
 	}
 
-	avatarKey, _, err := utils.PutObj(ctx, avatarFile)
+	variants, err := storeAvatar(ctx, lg, avatarFile)
 	if err != nil {
-		lg.Error("register.Avatar_post_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "头像存储失败"} 
+		return nil, err
 	}
+	avatarKey := avatarMainKey(variants)
 
 	u := models.User{
-		Email:     email,
-		Password:  string(hash),
-		Username:  username,
-		AvatarURL: avatarKey,
+		Email:          email,
+		Password:       string(hash),
+		Username:       username,
+		AvatarURL:      avatarKey,
+		AvatarVariants: variants,
 	}
 
 	created, err := models.AddUser(ctx, u)
 	if err != nil {
+		s.deleteAvatarObjects(lg, 0, u.AvatarObjectKeys())
 		if errors.Is(err, models.ErrUserExists) {
 			lg.Info("register.duplicate_on_insert")
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "该用户已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "user.exists"}
 		}
 		lg.Error("register.insert_failed", zap.Error(err))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "保存失败，请联系管理员"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.save_failed"}
 	}
 	infra.Publish(s.bus, lg, "PutAvatar", struct {
 		UID       int    `json:"uid"`
@@ -143,7 +189,7 @@ func (s *UserService) Register(ctx context.Context, lg *zap.Logger, email, usern
 func (s *UserService) Logout(ctx context.Context, lg *zap.Logger, uid int, claims *utils.Claims) error {
 	if uid <= 0 || claims == nil {
 		lg.Warn("logout.invalid_input", zap.Int("uid", uid))
-		return &AppError{Code: utils.ErrCodeAuthFailed, Message: "未授权"}
+		return &AppError{Code: utils.ErrCodeAuthFailed, Key: "auth.unauthorized"}
 	}
 	lg = lg.With(zap.Int("uid", uid))
 	lg.Info("logout.begin")
@@ -155,10 +201,10 @@ func (s *UserService) Logout(ctx context.Context, lg *zap.Logger, uid int, claim
 	if err != nil {
 		if errors.Is(err, ErrTokenExpire) {
 			lg.Warn("logout.ErrTokenExpire")
-			return &AppError{Code: utils.ErrCodeAuthFailed, Message: "token到期"}
+			return &AppError{Code: utils.ErrCodeAuthFailed, Key: "auth.token_expired"}
 		}
 		lg.Warn("logout.redis_put_error", zap.Error(err))
-		return &AppError{Code:utils.ErrCodeInternalServer, Message: "写入Redis出错"}
+		return &AppError{Code:utils.ErrCodeInternalServer, Key: "common.cache_failed"}
 	}
 	lg.Info("logout.success")
 	return nil
@@ -192,11 +238,11 @@ func (s *UserService) UpdateUser(ctx context.Context, lg *zap.Logger, uid int, i
 		exists, err := models.GetUserInfoByUsername(ctx, username)
 		if err != nil {
 			lg.Error("user.update.check_username_failed", zap.Error(err))
-			return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后再试：" + err.Error()}
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
 		}
 		if exists.ID != uid && exists.ID != 0 {
 			lg.Info("user.update.username_exists", zap.String("username", username))
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "用户已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "user.exists"}
 		}
 		update["username"] = username
 	}
@@ -205,79 +251,69 @@ func (s *UserService) UpdateUser(ctx context.Context, lg *zap.Logger, uid int, i
 		exists, err := models.GetUserInfoByEmail(ctx, email)
 		if err != nil {
 			lg.Error("user.update.check_email_failed", zap.Error(err))
-			return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后再试：" + err.Error()}
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
 		}
 		if exists.ID != uid && exists.ID != 0 {
 			lg.Info("user.update.email_exists", zap.String("email", email))
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "邮箱已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "user.email_exists"}
 		}
 		update["email"] = email
 	}
 
-	oldKey := ""
+	var oldKeys, newKeys []string
 	newKey := ""
 	if in.AvatarFile != nil {
 		oldUser, err := models.GetUserInfoByID(ctx, uid)
 		if err != nil {
 			lg.Error("user.update.get_old_avatar_failed", zap.Error(err))
-			return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "请稍后再试：" + err.Error()}
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.internal"}
 		}
-		oldKey = strings.TrimSpace(oldUser.AvatarURL)
+		oldKeys = oldUser.AvatarObjectKeys()
 
-		key, _, err := utils.PutObj(ctx, in.AvatarFile)
+		variants, err := storeAvatar(ctx, lg, in.AvatarFile)
 		if err != nil {
-			lg.Warn("user.update.avatar_put_failed", zap.Error(err))
-			return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "更新头像失败，请重新再试"}
+			return nil, err
 		}
-		newKey = key
+		newKey = avatarMainKey(variants)
+		newKeys = models.User{AvatarVariants: variants}.AvatarObjectKeys()
 		update["avatar_url"] = newKey
+		update["avatar_variants"] = variants
 	}
 
 	if in.Password != nil && in.ConfirmPassword != nil {
 		if *in.Password != *in.ConfirmPassword {
 			lg.Warn("user.update.password_mismatch")
-			return nil, &AppError{Code: utils.ErrCodeValidation, Message: "两次输入密码不一致，请重新输入"}
+			return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.password_mismatch"}
 		}
 		hash, err := bcrypt.GenerateFromPassword([]byte(*in.Password), bcrypt.DefaultCost)
 		if err != nil {
 			lg.Error("user.update.password_hash_failed", zap.Error(err))
-			return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "密码处理失败"}
+			return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "user.password_hash_failed"}
 		}
 		update["password"] = string(hash)
 		update["token_version"] = gorm.Expr("token_version + 1")
 	} else if in.Password != nil || in.ConfirmPassword != nil {
 		lg.Warn("user.update.password_half_provided")
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "请同时提供密码与确认密码"}
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "user.password_pair_required"}
 	}
 
 	if len(update) == 0 {
 		lg.Info("user.update.no_fields")
-		return nil, &AppError{Code: utils.ErrCodeValidation, Message: "没有需要更新的字段"}
+		return nil, &AppError{Code: utils.ErrCodeValidation, Key: "common.no_fields"}
 	}
 
 	updated, err, affected := models.UpdateUser(ctx, update, uid)
 	if err != nil {
-		if newKey != "" && s.bus != nil {
-			infra.Publish(s.bus, lg, "DeleteCOS", struct {
-				Key string `json:"key"`
-			}{Key: newKey}, 300*time.Millisecond, zap.Int("uid", uid),
-				zap.String("COSKey", newKey))
-
-		}
+		s.deleteAvatarObjects(lg, uid, newKeys)
 		if errors.Is(err, models.ErrUserExists) {
 			lg.Error("user.update.duplicate_on_update", zap.Any("update", sanitize(update)))
-			return nil, &AppError{Code: utils.ErrCodeConflict, Message: "用户已存在"}
+			return nil, &AppError{Code: utils.ErrCodeConflict, Key: "user.exists"}
 		}
 		lg.Error("user.update.db_failed", zap.Error(err), zap.Any("update", sanitize(update)))
-		return nil, &AppError{Code: utils.ErrCodeInternalServer, Message: "更新失败，请稍后重试"}
+		return nil, &AppError{Code: utils.ErrCodeInternalServer, Key: "common.update_failed"}
 	}
-	if newKey != "" && oldKey != "" && oldKey != newKey {
-		if s.bus != nil {
-			infra.Publish(s.bus, lg, "DeleteCOS", struct {
-				Key string `json:"key"`
-			}{Key: oldKey}, 300*time.Millisecond, zap.Int("uid", uid),
-				zap.String("COSKey", oldKey))
-		}
+	if newKey != "" {
+		s.deleteAvatarObjects(lg, uid, oldKeys)
 	}
 	if newKey != "" {
 		if s.bus != nil {
@@ -312,8 +348,10 @@ func (s *UserService) UpdateUser(ctx context.Context, lg *zap.Logger, uid int, i
 	if err := PutVersion(ctxRedis, updated.ID, updated.TokenVersion); err != nil {
 		lg.Warn("user.update.putTokenVersion_redis_failed", zap.Error(err))
 	}
+	s.clearLoginFailures(ctx, lg, updated.Username)
+	audit(lg, "password_changed", zap.Int("uid", updated.ID))
 
-	tokenStr, exp, _ := utils.GenerateAccessToken(updated.ID, updated.Username, updated.TokenVersion)
+	tokenStr, exp, _ := utils.GenerateAccessToken(updated.ID, updated.Username, updated.Role, updated.TokenVersion)
 	lg.Info("user.update.password_changed", zap.Time("new_access_exp", exp))
 	return &UpdateUserResult{
 		User:     updated,
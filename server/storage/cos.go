@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// COS 腾讯云对象存储
+type COS struct {
+	client    *cos.Client
+	secretID  string
+	secretKey string
+}
+
+func NewCOS(bucketURL, secretID, secretKey string) (*COS, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("cos: bucket-url is required")
+	}
+	client := cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  secretID,
+			SecretKey: secretKey,
+		},
+	})
+	return &COS{client: client, secretID: secretID, secretKey: secretKey}, nil
+}
+
+func (s *COS) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	opt := &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: contentType,
+		},
+	}
+	if size > 0 {
+		opt.ContentLength = size
+	}
+	resp, err := s.client.Object.Put(ctx, key, r, opt)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func (s *COS) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	resp, err := s.client.Object.Get(ctx, key, nil)
+	if err != nil {
+		if cos.IsNotFoundError(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	return resp.Body, &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+func (s *COS) Delete(ctx context.Context, key string) error {
+	_, err := s.client.Object.Delete(ctx, key)
+	return err
+}
+
+func (s *COS) SignedURL(ctx context.Context, key string, ttl time.Duration, opt SignOptions) (string, error) {
+	var popt *cos.PresignedURLOptions
+	if opt.Filename != "" {
+		q := url.Values{}
+		q.Set("response-content-disposition", attachment(opt.Filename))
+		popt = &cos.PresignedURLOptions{Query: &q}
+	}
+	u, err := s.client.Object.GetPresignedURL(ctx, http.MethodGet, key, s.secretID, s.secretKey, ttl, popt)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *COS) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	opt := &cos.BucketGetOptions{Prefix: prefix, MaxKeys: 1000}
+	for {
+		res, _, err := s.client.Bucket.Get(ctx, opt)
+		if err != nil {
+			return err
+		}
+		for _, o := range res.Contents {
+			info := ObjectInfo{Key: o.Key, Size: o.Size}
+			if t, err := time.Parse(time.RFC3339, o.LastModified); err == nil {
+				info.LastModified = t
+			}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+		if !res.IsTruncated {
+			return nil
+		}
+		opt.Marker = res.NextMarker
+		if opt.Marker == "" && len(res.Contents) > 0 {
+			opt.Marker = res.Contents[len(res.Contents)-1].Key
+		}
+	}
+}
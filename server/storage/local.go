@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrBadSignature = errors.New("invalid or expired signature")
+
+// Local 本地磁盘存储，用于开发与测试；签名链接由媒体代理接口校验后回源读取
+type Local struct {
+	dir     string
+	baseURL string
+	key     []byte
+}
+
+func NewLocal(dir, baseURL, signingKey string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Local{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), key: []byte(signingKey)}, nil
+}
+
+// path 将对象 key 映射为磁盘路径，拒绝越出根目录的 key
+func (s *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if key == "" || clean != key || strings.HasPrefix(key, "../") {
+		return "", fs.ErrInvalid
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	// 先写临时文件再改名，避免读到写了一半的对象
+	f, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+func (s *Local) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, nil, ErrNotFound
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	ct := mime.TypeByExtension(path.Ext(key))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	return f, &ObjectInfo{Key: key, Size: st.Size(), ContentType: ct, LastModified: st.ModTime()}, nil
+}
+
+func (s *Local) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 遍历目录，跳过上传中的临时文件；WalkDir 按字典序访问，与对象存储的 key 顺序一致
+func (s *Local) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	root := s.dir
+	// 从前缀所在目录开始遍历，避免扫描整个存储目录
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		p, err := s.path(prefix[:i])
+		if err != nil {
+			return err
+		}
+		root = p
+	}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		st, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		return fn(ObjectInfo{Key: key, Size: st.Size(), LastModified: st.ModTime()})
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// SignedURL 生成带 HMAC 签名与过期时间的代理链接
+func (s *Local) SignedURL(ctx context.Context, key string, ttl time.Duration, opt SignOptions) (string, error) {
+	exp := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	q := url.Values{}
+	q.Set("exp", exp)
+	if opt.Filename != "" {
+		q.Set("filename", opt.Filename)
+	}
+	q.Set("sig", s.sign(key, exp, opt.Filename))
+	return s.baseURL + "/" + escapePath(key) + "?" + q.Encode(), nil
+}
+
+// Verify 校验代理链接的签名与有效期
+func (s *Local) Verify(key, exp, filename, sig string) error {
+	ts, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > ts {
+		return ErrBadSignature
+	}
+	want := s.sign(key, exp, filename)
+	if !hmac.Equal([]byte(want), []byte(sig)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+func (s *Local) sign(key, exp, filename string) string {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(key + "\n" + exp + "\n" + filename))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// SignOptions 签名链接选项，Filename 非空时以附件形式下载
+type SignOptions struct {
+	Filename string
+}
+
+// Storage 对象存储后端
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, ttl time.Duration, opt SignOptions) (string, error)
+	// List 按 key 顺序遍历前缀下的对象，fn 返回错误时停止遍历
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+}
+
+// Default 当前使用的存储后端，由 initialize.InitStorage 按配置设置
+var Default Storage
+
+// ReadAll 读取整个对象，返回内容与 Content-Type
+func ReadAll(ctx context.Context, key string) ([]byte, string, error) {
+	rc, info, err := Default.Get(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	return b, info.ContentType, err
+}
+
+func attachment(filename string) string {
+	return `attachment; filename="` + filename + `"`
+}
@@ -12,20 +12,66 @@ var (
 	secret    = []byte(config.Secret)
 	issuer    = config.Issuer
 	audience  = config.Audience
-	accessTTL = config.AccessTTL // Access Token 有效期
+	accessTTL = config.AccessTTL   // Access Token 有效期
+	mfaTTL    = config.MFATokenTTL // 两步验证挑战令牌有效期
+)
+
+const (
+	subjectAccess = "access"
+	subjectMFA    = "mfa"
+
+	// PATPrefix 个人访问令牌前缀，用于和 JWT 区分
+	PATPrefix = "tdl_pat_"
 )
 
 type Claims struct {
-	UID      int    `json:"uid"`
-	Username string `json:"username"`
-	Ver      int    `json:"ver"` //
+	UID      int      `json:"uid"`
+	Username string   `json:"username"`
+	Ver      int      `json:"ver"` //
+	Role     string   `json:"role,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(uid int, username string, tokenVersion int) (string, time.Time, error) {
+func GenerateAccessToken(uid int, username, role string, tokenVersion int) (string, time.Time, error) {
 	now := time.Now().UTC()
 	exp := now.Add(accessTTL)
 	jti := fmt.Sprintf("acc_%d_%d", uid, now.UnixNano())
+	claims := &Claims{
+		UID:      uid,
+		Username: username,
+		Ver:      tokenVersion,
+		Role:     role,
+		Scopes:   RoleScopes(role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  []string{audience},
+			Subject:   subjectAccess,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(exp),
+			ID:        jti,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	signed, err := token.SignedString(secret)
+	return signed, exp, err
+}
+
+// GrantedScopes 令牌授予的权限，旧令牌未携带 scopes 时按角色推导
+func (c *Claims) GrantedScopes() []string {
+	if len(c.Scopes) > 0 {
+		return c.Scopes
+	}
+	return RoleScopes(c.Role)
+}
+
+// GenerateMFAToken 密码校验通过后签发的短期挑战令牌，只能用于提交两步验证码
+func GenerateMFAToken(uid int, username string, tokenVersion int) (string, time.Time, error) {
+	now := time.Now().UTC()
+	exp := now.Add(mfaTTL)
+	jti := fmt.Sprintf("mfa_%d_%d", uid, now.UnixNano())
 	claims := &Claims{
 		UID:      uid,
 		Username: username,
@@ -33,7 +79,7 @@ func GenerateAccessToken(uid int, username string, tokenVersion int) (string, ti
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    issuer,
 			Audience:  []string{audience},
-			Subject:   "access",
+			Subject:   subjectMFA,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(exp),
 			ID:        jti,
@@ -47,9 +93,18 @@ func GenerateAccessToken(uid int, username string, tokenVersion int) (string, ti
 }
 
 func Parse(tokenStr string) (*Claims, error) {
+	return parseWithSubject(tokenStr, subjectAccess)
+}
+
+func ParseMFAToken(tokenStr string) (*Claims, error) {
+	return parseWithSubject(tokenStr, subjectMFA)
+}
+
+func parseWithSubject(tokenStr string, subject string) (*Claims, error) {
 	tok, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
 		return secret, nil
-	}, jwt.WithAudience(audience), jwt.WithIssuer(issuer))
+	}, jwt.WithAudience(audience), jwt.WithIssuer(issuer), jwt.WithSubject(subject),
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		return nil, err
 	}
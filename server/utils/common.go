@@ -5,14 +5,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 )
+
 const (
-    CodeOK              = 0     
-    ErrCodeAuthFailed      = 4001  //认证失败（无效的JWT/未登录）
-    ErrCodeValidation      = 4002  //参数验证失败（email格式错、密码过短等）
-    ErrCodeNotFound        = 4004  //资源未找到（用户不存在、项目不存在）
-    ErrCodeConflict        = 4009  //冲突（邮箱已被注册、用户名已存在）
-    ErrCodeInternalServer  = 5001  //服务器内部错误（数据库异常、系统错误）
+	CodeOK                 = 0
+	ErrCodeAuthFailed      = 4001 //认证失败（无效的JWT/未登录）
+	ErrCodeValidation      = 4002 //参数验证失败（email格式错、密码过短等）
+	ErrCodeForbidden       = 4003 //权限不足（令牌缺少路由要求的权限范围）
+	ErrCodeNotFound        = 4004 //资源未找到（用户不存在、项目不存在）
+	ErrCodeConflict        = 4009 //冲突（邮箱已被注册、用户名已存在）
+	ErrCodeTooManyRequests = 4029 //请求过于频繁（登录失败次数过多被锁定）
+	ErrCodeInternalServer  = 5001 //服务器内部错误（数据库异常、系统错误）
 )
+
 type JsonStruct struct {
 	Code  int         `json:"code"`
 	Msg   interface{} `json:"msg"`
@@ -20,8 +24,10 @@ type JsonStruct struct {
 	Count int64       `json:"count"`
 }
 type JsonErrStruct struct {
-	Code int         `json:"code"`
-	Msg  interface{} `json:"msg"`
+	Code   int          `json:"code"`
+	Key    string       `json:"key"`              // 稳定的错误键，客户端可据此自行处理
+	Msg    string       `json:"msg"`              // 按请求语言翻译后的文案
+	Errors []FieldError `json:"errors,omitempty"` // 字段级校验错误
 }
 
 func ReturnSuccess(c *gin.Context, code int, msg interface{}, data interface{}, count int64) {
@@ -35,26 +41,40 @@ func ReturnSuccess(c *gin.Context, code int, msg interface{}, data interface{},
 	c.JSON(statusCode, json)
 }
 
-func ReturnError(c *gin.Context, code int, msg interface{}) {
-    var statusCode int
-    switch code {
-    case ErrCodeAuthFailed:
-        statusCode = http.StatusUnauthorized  
-    case ErrCodeValidation:
-        statusCode = http.StatusBadRequest   
-    case ErrCodeNotFound:
-        statusCode = http.StatusNotFound      
-    case ErrCodeConflict:
-        statusCode = http.StatusConflict      
-    case ErrCodeInternalServer:
-        statusCode = http.StatusInternalServerError  
-    default:
-        statusCode = http.StatusOK  
-    }
-
-    json := &JsonErrStruct{
-        Code: code,
-        Msg:  msg,
-    }
-    c.JSON(statusCode, json)
+func ReturnError(c *gin.Context, code int, key string, args ...any) {
+	writeError(c, code, &JsonErrStruct{
+		Code: code,
+		Key:  key,
+		Msg:  Translate(RequestLocale(c), key, args...),
+	})
+}
+
+// codeStatus 业务错误码对应的 HTTP 状态码
+var codeStatus = map[int]int{
+	CodeOK:                 http.StatusOK,
+	ErrCodeAuthFailed:      http.StatusUnauthorized,
+	ErrCodeValidation:      http.StatusBadRequest,
+	ErrCodeForbidden:       http.StatusForbidden,
+	ErrCodeNotFound:        http.StatusNotFound,
+	ErrCodeConflict:        http.StatusConflict,
+	ErrCodeTooManyRequests: http.StatusTooManyRequests,
+	ErrCodeInternalServer:  http.StatusInternalServerError,
+}
+
+// HTTPStatus 未登记的错误码按服务端错误处理
+func HTTPStatus(code int) int {
+	if st, ok := codeStatus[code]; ok {
+		return st
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(c *gin.Context, code int, json *JsonErrStruct) {
+	status := HTTPStatus(code)
+	c.Header("Vary", "Accept")
+	if WantsProblem(c) {
+		writeProblem(c, status, json)
+		return
+	}
+	c.JSON(status, json)
 }
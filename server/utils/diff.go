@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOp struct {
+	kind byte // ' '、'-'、'+'
+	line string
+}
+
+// UnifiedDiff 按行比较两段文本，输出带 context 行上下文的 unified diff；内容相同时返回空串
+func UnifiedDiff(fromName, toName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+	changed := false
+	for _, op := range ops {
+		changed = changed || op.kind != ' '
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	// 找出每个变更块，前后各保留 context 行，相距不超过 2*context 的块合并
+	i := 0
+	for i < len(ops) {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+		writeHunk(&sb, ops, start, end)
+		i = end
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp, start, end int) {
+	// 计算块起始行号
+	aLine, bLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			aLine++
+		}
+		if op.kind != '-' {
+			bLine++
+		}
+	}
+	aLen, bLen := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			aLen++
+		}
+		if op.kind != '-' {
+			bLen++
+		}
+	}
+	if aLen == 0 {
+		aLine--
+	}
+	if bLen == 0 {
+		bLine--
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aLine, aLen), hunkRange(bLine, bLen))
+	for _, op := range ops[start:end] {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.line)
+		sb.WriteByte('\n')
+	}
+}
+
+func hunkRange(start, n int) string {
+	if n == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+}
+
+// diffLines Myers 差分算法的线性空间版本，返回把 a 变成 b 的最短编辑序列
+// 每次找出最短编辑路径的中间蛇形段再对两侧递归，内存为 O(N+M)，时间为 O((N+M)·D)
+func diffLines(a, b []string) []diffOp {
+	n := len(a) + len(b)
+	d := &differ{
+		a:   a,
+		b:   b,
+		vf:  make([]int, n+5),
+		vb:  make([]int, n+5),
+		ops: make([]diffOp, 0, n),
+	}
+	d.compare(0, len(a), 0, len(b))
+	return d.ops
+}
+
+type differ struct {
+	a, b   []string
+	vf, vb []int // 正向、反向各对角线能到达的最远 x，下标偏移 len(vf)/2
+	ops    []diffOp
+}
+
+// compare 比较 a[a0:a1] 与 b[b0:b1]，按顺序追加编辑操作
+func (d *differ) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.a[a0] == d.b[b0] {
+		d.ops = append(d.ops, diffOp{' ', d.a[a0]})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a1-suffix > a0 && b1-suffix > b0 && d.a[a1-suffix-1] == d.b[b1-suffix-1] {
+		suffix++
+	}
+	a1 -= suffix
+	b1 -= suffix
+	switch {
+	case a0 == a1:
+		for _, line := range d.b[b0:b1] {
+			d.ops = append(d.ops, diffOp{'+', line})
+		}
+	case b0 == b1:
+		for _, line := range d.a[a0:a1] {
+			d.ops = append(d.ops, diffOp{'-', line})
+		}
+	default:
+		// 去掉首尾相同行后两侧都非空，编辑距离至少为 2，两侧递归的规模都严格变小
+		x, y, u, v := d.middleSnake(a0, a1, b0, b1)
+		d.compare(a0, x, b0, y)
+		for _, line := range d.a[x:u] {
+			d.ops = append(d.ops, diffOp{' ', line})
+		}
+		d.compare(u, a1, v, b1)
+	}
+	for i := a1; i < a1+suffix; i++ {
+		d.ops = append(d.ops, diffOp{' ', d.a[i]})
+	}
+}
+
+// middleSnake 同时从两端搜索，返回最短编辑路径中间的蛇形段 (x,y)→(u,v)
+func (d *differ) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta&1 != 0
+	off := len(d.vf) / 2
+	vf, vb := d.vf, d.vb
+	vf[off+1], vb[off+1] = 0, 0
+	for D := 0; D <= (n+m+1)/2; D++ {
+		for k := -D; k <= D; k += 2 {
+			var px int
+			if k == -D || (k != D && vf[off+k-1] < vf[off+k+1]) {
+				px = vf[off+k+1]
+			} else {
+				px = vf[off+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && d.a[a0+px] == d.b[b0+py] {
+				px++
+				py++
+			}
+			vf[off+k] = px
+			// 反向对角线 kr 上的点 (n-xr, m-yr) 对应正向对角线 delta-kr
+			if kr := delta - k; odd && kr >= -(D-1) && kr <= D-1 && px+vb[off+kr] >= n {
+				return a0 + sx, b0 + sy, a0 + px, b0 + py
+			}
+		}
+		for kr := -D; kr <= D; kr += 2 {
+			var rx int
+			if kr == -D || (kr != D && vb[off+kr-1] < vb[off+kr+1]) {
+				rx = vb[off+kr+1]
+			} else {
+				rx = vb[off+kr-1] + 1
+			}
+			ry := rx - kr
+			sx, sy := rx, ry
+			for rx < n && ry < m && d.a[a1-rx-1] == d.b[b1-ry-1] {
+				rx++
+				ry++
+			}
+			vb[off+kr] = rx
+			if k := delta - kr; !odd && k >= -D && k <= D && rx+vf[off+k] >= n {
+				return a1 - rx, b1 - ry, a1 - sx, b1 - sy
+			}
+		}
+	}
+	// 两侧都非空时必然在上面的循环中相遇
+	panic("diff: middle snake not found")
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func numbered(n int, replace map[int]string) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		line := strconv.Itoa(i)
+		if r, ok := replace[i]; ok {
+			line = r
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	ten := numbered(10, nil)
+	cases := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{"identical", ten, ten, 3, ""},
+		{"both empty", "", "", 3, ""},
+		{"line endings only", "a\nb\n", "a\r\nb", 3, ""},
+		{"from empty", "", "x\ny\n", 3, "@@ -0,0 +1,2 @@\n+x\n+y\n"},
+		{"to empty", "x\ny\n", "", 3, "@@ -1,2 +0,0 @@\n-x\n-y\n"},
+		{"middle", ten, numbered(10, map[int]string{5: "five"}), 2,
+			"@@ -3,5 +3,5 @@\n 3\n 4\n-5\n+five\n 6\n 7\n"},
+		{"context clipped at start", ten, numbered(10, map[int]string{1: "one"}), 2,
+			"@@ -1,3 +1,3 @@\n-1\n+one\n 2\n 3\n"},
+		{"context clipped at end", ten, numbered(10, map[int]string{10: "ten"}), 2,
+			"@@ -8,3 +8,3 @@\n 8\n 9\n-10\n+ten\n"},
+		{"close changes merged", ten, numbered(10, map[int]string{3: "c", 7: "g"}), 2,
+			"@@ -1,9 +1,9 @@\n 1\n 2\n-3\n+c\n 4\n 5\n 6\n-7\n+g\n 8\n 9\n"},
+		{"distant changes split", ten, numbered(10, map[int]string{2: "b", 9: "i"}), 1,
+			"@@ -1,3 +1,3 @@\n 1\n-2\n+b\n 3\n@@ -8,3 +8,3 @@\n 8\n-9\n+i\n 10\n"},
+		{"zero context", ten, numbered(10, map[int]string{5: "five"}), 0,
+			"@@ -5 +5 @@\n-5\n+five\n"},
+		{"zero context insertion", "1\n2\n3\n", "1\n2\nx\n3\n", 0,
+			"@@ -2,0 +3 @@\n+x\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := tc.want
+			if want != "" {
+				want = "--- a\n+++ b\n" + want
+			}
+			if got := UnifiedDiff("a", "b", tc.a, tc.b, tc.context); got != want {
+				t.Fatalf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// lcsLen 动态规划求最长公共子序列长度，用于校验编辑序列最短
+func lcsLen(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(cur[j], prev[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func checkOps(t *testing.T, a, b []string, ops []diffOp) {
+	t.Helper()
+	var gotA, gotB []string
+	edits := 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			gotA = append(gotA, op.line)
+		}
+		if op.kind != '-' {
+			gotB = append(gotB, op.line)
+		}
+		if op.kind != ' ' {
+			edits++
+		}
+	}
+	if strings.Join(gotA, "\n") != strings.Join(a, "\n") || strings.Join(gotB, "\n") != strings.Join(b, "\n") {
+		t.Fatalf("ops do not reproduce inputs: a=%q b=%q ops=%v", a, b, ops)
+	}
+	if want := len(a) + len(b) - 2*lcsLen(a, b); edits != want {
+		t.Fatalf("a=%q b=%q: %d edits, want %d", a, b, edits, want)
+	}
+}
+
+func TestDiffLinesMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		out := make([]string, rng.Intn(12))
+		for i := range out {
+			out[i] = string(rune('a' + rng.Intn(3)))
+		}
+		return out
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := gen(), gen()
+		checkOps(t, a, b, diffLines(a, b))
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	a := make([]string, 20000)
+	b := make([]string, 0, len(a))
+	for i := range a {
+		a[i] = "line " + strconv.Itoa(i)
+		switch i % 50 {
+		case 0:
+			b = append(b, "changed "+strconv.Itoa(i))
+		case 25:
+			// 删除
+		default:
+			b = append(b, a[i])
+		}
+	}
+	start := time.Now()
+	ops := diffLines(a, b)
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("diff took %v", d)
+	}
+	if len(ops) != len(a)+len(a)/50 {
+		t.Fatalf("%d ops, want %d", len(ops), len(a)+len(a)/50)
+	}
+}
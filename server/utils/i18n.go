@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs 语言 -> (错误键 -> 文案)
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	out := make(map[string]map[string]string, len(SupportedLocales))
+	for _, loc := range SupportedLocales {
+		b, err := localeFS.ReadFile("locales/" + loc + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog %s: %v", loc, err))
+		}
+		m := map[string]string{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", loc, err))
+		}
+		out[loc] = m
+	}
+	return out
+}
+
+// Translate 按语言翻译错误键，缺失时回退默认语言，再缺失返回键本身
+func Translate(locale, key string, args ...any) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		if msg, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// MatchAcceptLanguage 解析 Accept-Language，返回支持的语言中权重最高的一个；无匹配返回空串
+func MatchAcceptLanguage(header string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		if q > 0 {
+			tags = append(tags, tag{lang: lang, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	for _, t := range tags {
+		if loc := normalizeLocale(t.lang); loc != "" {
+			return loc
+		}
+	}
+	return ""
+}
+
+func normalizeLocale(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	switch base {
+	case "zh":
+		return "zh-CN"
+	case "en":
+		return "en-US"
+	}
+	return ""
+}
+
+// RequestLocale 请求语言：Accept-Language 优先，其次用户偏好，最后默认语言
+func RequestLocale(c *gin.Context) string {
+	if loc := MatchAcceptLanguage(c.GetHeader("Accept-Language")); loc != "" {
+		return loc
+	}
+	if loc := c.GetString("locale"); IsSupportedLocale(loc) {
+		return loc
+	}
+	return DefaultLocale
+}
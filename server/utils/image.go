@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	AvatarMaxBytes  = 5 << 20    // 上传头像最大字节数
+	AvatarMaxPixels = 25_000_000 // 上传头像最大像素数（宽×高）
+	AvatarMinSide   = 32         // 上传头像最短边
+	avatarQuality   = 85
+)
+
+// AvatarJPEG 头像输出格式
+const AvatarJPEG = "jpeg"
+
+// AvatarSizes 生成的头像边长
+var AvatarSizes = []int{64, 256, 512}
+
+// AvatarFormats 每个尺寸生成的格式
+var AvatarFormats = []string{AvatarJPEG}
+
+var (
+	ErrImageTooLarge   = errors.New("image too large")
+	ErrImageType       = errors.New("unsupported image type")
+	ErrImageDimensions = errors.New("image dimensions out of range")
+)
+
+// SniffImageType 根据文件头魔数识别图片格式，不信任客户端的扩展名与 Content-Type
+func SniffImageType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return "gif"
+	case len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		return "webp"
+	}
+	return ""
+}
+
+// ProcessAvatar 校验并处理头像：按 EXIF 方向摆正、居中裁成正方形，输出各尺寸的 JPEG
+// 返回 边长 -> 格式 -> 图片数据
+func ProcessAvatar(r io.Reader) (map[int]map[string][]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, AvatarMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > AvatarMaxBytes {
+		return nil, ErrImageTooLarge
+	}
+	typ := SniffImageType(data)
+	if typ == "" {
+		return nil, ErrImageType
+	}
+	// 解码前先看尺寸，避免超大图片撑爆内存
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != typ {
+		return nil, ErrImageType
+	}
+	if cfg.Width < AvatarMinSide || cfg.Height < AvatarMinSide ||
+		int64(cfg.Width)*int64(cfg.Height) > AvatarMaxPixels {
+		return nil, ErrImageDimensions
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageType
+	}
+	orientation := 1
+	if typ == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	// 旋转/翻转不改变中心正方形，先裁剪缩放再摆正，减少像素运算
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	crop := image.Rect(x0, y0, x0+side, y0+side)
+
+	largest := AvatarSizes[len(AvatarSizes)-1]
+	base := image.NewRGBA(image.Rect(0, 0, largest, largest))
+	draw.Draw(base, base.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(base, base.Bounds(), src, crop, draw.Over, nil)
+	base = orient(base, orientation)
+
+	out := make(map[int]map[string][]byte, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		img := image.Image(base)
+		if size != largest {
+			dst := image.NewRGBA(image.Rect(0, 0, size, size))
+			draw.CatmullRom.Scale(dst, dst.Bounds(), base, base.Bounds(), draw.Src, nil)
+			img = dst
+		}
+		var jpg bytes.Buffer
+		if err := jpeg.Encode(&jpg, img, &jpeg.Options{Quality: avatarQuality}); err != nil {
+			return nil, err
+		}
+		out[size] = map[string][]byte{AvatarJPEG: jpg.Bytes()}
+	}
+	return out, nil
+}
+
+// jpegOrientation 读取 JPEG 的 EXIF Orientation，缺失或解析失败时返回 1
+func jpegOrientation(data []byte) int {
+	p := 2
+	for p+4 <= len(data) {
+		if data[p] != 0xFF {
+			return 1
+		}
+		marker := data[p+1]
+		if marker == 0xD9 || marker == 0xDA { // EOI / SOS 之后不会再有 APP 段
+			return 1
+		}
+		n := int(binary.BigEndian.Uint16(data[p+2:]))
+		if n < 2 || p+2+n > len(data) {
+			return 1
+		}
+		seg := data[p+4 : p+2+n]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return exifOrientation(seg[6:])
+		}
+		p += 2 + n
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(bo.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(bo.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		e := ifd + 2 + i*12
+		if e+12 > len(tiff) {
+			return 1
+		}
+		if bo.Uint16(tiff[e:]) == 0x0112 {
+			if v := int(bo.Uint16(tiff[e+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient 按 EXIF Orientation 变换正方形图片
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o <= 1 || o > 8 {
+		return src
+	}
+	n := src.Bounds().Dx()
+	dst := image.NewRGBA(src.Bounds())
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var dx, dy int
+			switch o {
+			case 2: // 水平翻转
+				dx, dy = n-1-x, y
+			case 3: // 旋转 180°
+				dx, dy = n-1-x, n-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, n-1-y
+			case 5: // 沿主对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针 90°
+				dx, dy = n-1-y, x
+			case 7: // 沿副对角线翻转
+				dx, dy = n-1-y, n-1-x
+			case 8: // 逆时针 90°
+				dx, dy = y, n-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestProcessAvatarFormats(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 120, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 120; x++ {
+			src.SetRGBA(x, y, color.RGBA{uint8(x * 2), uint8(y * 3), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ProcessAvatar(&buf)
+	if err != nil {
+		t.Fatalf("ProcessAvatar: %v", err)
+	}
+	for _, size := range AvatarSizes {
+		if len(out[size]) != len(AvatarFormats) {
+			t.Fatalf("%d: formats %d, want %d", size, len(out[size]), len(AvatarFormats))
+		}
+		b := out[size][AvatarJPEG]
+		if SniffImageType(b) != AvatarJPEG {
+			t.Fatalf("%d: sniffed %q", size, SniffImageType(b))
+		}
+		m, err := jpeg.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%d: decode: %v", size, err)
+		}
+		if m.Bounds().Dx() != size || m.Bounds().Dy() != size {
+			t.Fatalf("%d: bounds %v", size, m.Bounds())
+		}
+	}
+}
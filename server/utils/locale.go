@@ -0,0 +1,11 @@
+package utils
+
+import "slices"
+
+const DefaultLocale = "zh-CN"
+
+var SupportedLocales = []string{"zh-CN", "en-US"}
+
+func IsSupportedLocale(locale string) bool {
+	return slices.Contains(SupportedLocales, locale)
+}
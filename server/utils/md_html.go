@@ -2,6 +2,10 @@ package utils
 
 import (
 	"bytes"
+	stdhtml "html"
+	"regexp"
+	"strconv"
+
 	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/yuin/goldmark"
@@ -10,12 +14,19 @@ import (
 	"github.com/yuin/goldmark/renderer/html"
 )
 
+// AssetScheme 正文中引用已上传图片的方式：![](asset:123)
+const AssetScheme = "asset"
+
 var (
 	policy = bluemonday.UGCPolicy().
 		AllowAttrs("class").OnElements("code", "pre", "span").
 		AllowAttrs("target", "rel").OnElements("a").
+		AllowURLSchemes(AssetScheme).
 		AddTargetBlankToFullyQualifiedLinks(true)
 
+	// 净化后的 HTML 中属性值里的引号都已转义，只有真实的 src/href 才会匹配
+	assetRefRe = regexp.MustCompile(`(src|href)="` + AssetScheme + `:(\d+)"`)
+
 	md = goldmark.New(
 		goldmark.WithExtensions(
 			extension.GFM,
@@ -40,3 +51,34 @@ func RenderSafeHTML(src []byte) (string, error) {
 	safe := policy.SanitizeBytes(buf.Bytes())
 	return string(safe), nil
 }
+
+// AssetIDs 提取渲染结果中引用的图片ID，去重并保持出现顺序
+func AssetIDs(safeHTML string) []int {
+	var ids []int
+	seen := map[int]bool{}
+	for _, m := range assetRefRe.FindAllStringSubmatch(safeHTML, -1) {
+		id, err := strconv.Atoi(m[2])
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// RewriteAssetURLs 将 asset:<id> 替换为 resolve 返回的链接，resolve 返回 false 时保持原样
+func RewriteAssetURLs(safeHTML string, resolve func(id int) (string, bool)) string {
+	return assetRefRe.ReplaceAllStringFunc(safeHTML, func(m string) string {
+		sub := assetRefRe.FindStringSubmatch(m)
+		id, err := strconv.Atoi(sub[2])
+		if err != nil {
+			return m
+		}
+		u, ok := resolve(id)
+		if !ok {
+			return m
+		}
+		return sub[1] + `="` + stdhtml.EscapeString(u) + `"`
+	})
+}
@@ -0,0 +1,298 @@
+package utils
+
+import (
+	"ToDoList/server/config"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcDiscoveryTTL   = time.Hour
+	oidcJWKSMinRefresh = time.Minute
+)
+
+var ErrOIDCUnknownKey = errors.New("oidc: unknown signing key")
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCClaims ID Token 中关心的字段
+type OIDCClaims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"` // 部分提供方返回字符串 "true"
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+func (c *OIDCClaims) IsEmailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// OIDCClient 单个身份提供方的授权码 + PKCE 客户端，HTTP 与 Now 可替换以便对接本地模拟提供方
+type OIDCClient struct {
+	cfg  config.OIDCProviderConfig
+	HTTP *http.Client
+	Now  func() time.Time
+
+	mu     sync.Mutex
+	disc   *oidcDiscovery
+	discAt time.Time
+	keys   map[string]*rsa.PublicKey
+	keysAt time.Time
+}
+
+func NewOIDCClient(cfg config.OIDCProviderConfig) *OIDCClient {
+	return &OIDCClient{
+		cfg:  cfg,
+		HTTP: &http.Client{Timeout: 10 * time.Second},
+		Now:  time.Now,
+	}
+}
+
+// NewPKCE 生成 code_verifier 与 S256 code_challenge
+func NewPKCE() (verifier string, challenge string, err error) {
+	verifier, err = RandomURLToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomURLToken n 字节随机数的 base64url 编码
+func RandomURLToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// AuthCodeURL 拼接跳转到提供方的授权地址
+func (o *OIDCClient) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	disc, err := o.discovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	scopes := o.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", o.cfg.ClientID)
+	q.Set("redirect_uri", o.cfg.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(disc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return disc.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange 用授权码换取并校验 ID Token
+func (o *OIDCClient) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCClaims, error) {
+	disc, err := o.discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", o.cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", o.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, disc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if o.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(o.cfg.ClientSecret))
+	}
+	resp, err := o.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token endpoint returned %d: %s", resp.StatusCode, body)
+	}
+	var tok struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return nil, fmt.Errorf("oidc: decode token response: %w", err)
+	}
+	if tok.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+	return o.VerifyIDToken(ctx, tok.IDToken, nonce)
+}
+
+// VerifyIDToken 校验签名、iss、aud、exp 与 nonce
+func (o *OIDCClient) VerifyIDToken(ctx context.Context, raw, nonce string) (*OIDCClaims, error) {
+	disc, err := o.discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := &OIDCClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return o.key(ctx, kid)
+	}, jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(disc.Issuer),
+		jwt.WithAudience(o.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(o.Now),
+		jwt.WithLeeway(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("oidc: nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("oidc: id_token has no sub")
+	}
+	return claims, nil
+}
+
+func (o *OIDCClient) discovery(ctx context.Context) (*oidcDiscovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.disc != nil && o.Now().Sub(o.discAt) < oidcDiscoveryTTL {
+		return o.disc, nil
+	}
+	var disc oidcDiscovery
+	wellKnown := strings.TrimSuffix(o.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := o.getJSON(ctx, wellKnown, &disc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(disc.Issuer, "/") != strings.TrimSuffix(o.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("oidc: issuer mismatch, got %q", disc.Issuer)
+	}
+	if disc.AuthorizationEndpoint == "" || disc.TokenEndpoint == "" || disc.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is incomplete")
+	}
+	o.disc = &disc
+	o.discAt = o.Now()
+	return o.disc, nil
+}
+
+// key 按 kid 取公钥，遇到未知 kid 时刷新 JWKS（限频）
+func (o *OIDCClient) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if k := o.pickKey(kid); k != nil {
+		return k, nil
+	}
+	if o.keys != nil && o.Now().Sub(o.keysAt) < oidcJWKSMinRefresh {
+		return nil, ErrOIDCUnknownKey
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := o.getJSON(ctx, o.disc.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	o.keys = keys
+	o.keysAt = o.Now()
+	if k := o.pickKey(kid); k != nil {
+		return k, nil
+	}
+	return nil, ErrOIDCUnknownKey
+}
+
+func (o *OIDCClient) pickKey(kid string) *rsa.PublicKey {
+	if kid != "" {
+		return o.keys[kid]
+	}
+	// 未声明 kid 时只在唯一一把密钥的情况下使用
+	if len(o.keys) == 1 {
+		for _, k := range o.keys {
+			return k
+		}
+	}
+	return nil
+}
+
+func (o *OIDCClient) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := o.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s returned %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,400 @@
+package utils
+
+import (
+	"ToDoList/server/config"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	mockClientID     = "todo-app"
+	mockClientSecret = "s3cret/+="
+	mockRedirectURL  = "http://app.local/api/v1/auth/oidc/mock/callback"
+)
+
+type mockGrant struct {
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+// mockOIDCProvider 本地模拟的身份提供方：发现文档、JWKS 与校验 PKCE 的 token 端点
+type mockOIDCProvider struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	at            time.Time
+	keys          map[string]*rsa.PrivateKey
+	signKID       string
+	nextKID       int
+	codes         map[string]mockGrant
+	issuer        string // 非空时发现文档返回该 issuer
+	incomplete    bool
+	discoveryHits int
+	jwksHits      int
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+	p := &mockOIDCProvider{t: t, at: time.Now(), codes: make(map[string]mockGrant)}
+	p.rotate()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("GET /jwks", p.handleJWKS)
+	mux.HandleFunc("POST /token", p.handleToken)
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+func (p *mockOIDCProvider) now() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.at
+}
+
+func (p *mockOIDCProvider) hits() (discovery, jwks int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.discoveryHits, p.jwksHits
+}
+
+func (p *mockOIDCProvider) advance(d time.Duration) {
+	p.mu.Lock()
+	p.at = p.at.Add(d)
+	p.mu.Unlock()
+}
+
+// rotate 换用新的签名密钥，JWKS 中只保留新密钥
+func (p *mockOIDCProvider) rotate() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nextKID++
+	p.signKID = "k" + strconv.Itoa(p.nextKID)
+	p.keys = map[string]*rsa.PrivateKey{p.signKID: key}
+}
+
+func (p *mockOIDCProvider) client() *OIDCClient {
+	c := NewOIDCClient(config.OIDCProviderConfig{
+		Name:         "mock",
+		Issuer:       p.srv.URL,
+		ClientID:     mockClientID,
+		ClientSecret: mockClientSecret,
+		RedirectURL:  mockRedirectURL,
+	})
+	c.HTTP = p.srv.Client()
+	c.Now = p.now
+	return c
+}
+
+// authorize 模拟用户在提供方同意授权，返回回调中的 code
+func (p *mockOIDCProvider) authorize(authURL string, claims jwt.MapClaims) string {
+	p.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Path != "/authorize" || q.Get("response_type") != "code" || q.Get("client_id") != mockClientID ||
+		q.Get("redirect_uri") != mockRedirectURL || q.Get("code_challenge_method") != "S256" ||
+		q.Get("code_challenge") == "" || q.Get("state") == "" {
+		p.t.Fatalf("unexpected authorization request: %s", authURL)
+	}
+	code, err := RandomURLToken(16)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.mu.Lock()
+	p.codes[code] = mockGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), claims: claims}
+	p.mu.Unlock()
+	return code
+}
+
+func (p *mockOIDCProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.discoveryHits++
+	issuer, incomplete := p.issuer, p.incomplete
+	p.mu.Unlock()
+	if issuer == "" {
+		issuer = p.srv.URL
+	}
+	doc := map[string]string{
+		"issuer":                 issuer,
+		"authorization_endpoint": p.srv.URL + "/authorize",
+		"token_endpoint":         p.srv.URL + "/token",
+		"jwks_uri":               p.srv.URL + "/jwks",
+	}
+	if incomplete {
+		delete(doc, "jwks_uri")
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (p *mockOIDCProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jwksHits++
+	keys := []map[string]string{
+		// 不是 RSA 签名密钥的条目应被忽略
+		{"kty": "EC", "kid": "ec", "crv": "P-256"},
+	}
+	for kid, k := range p.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func (p *mockOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	}
+	if !ok || id != mockClientID || secret != mockClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	grant, ok := p.codes[r.PostForm.Get("code")]
+	// code 只能使用一次
+	delete(p.codes, r.PostForm.Get("code"))
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("redirect_uri") != mockRedirectURL ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	claims := jwt.MapClaims{
+		"iss":   p.srv.URL,
+		"aud":   mockClientID,
+		"iat":   p.at.Unix(),
+		"exp":   p.at.Add(5 * time.Minute).Unix(),
+		"nonce": grant.nonce,
+	}
+	for k, v := range grant.claims {
+		claims[k] = v
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = p.signKID
+	raw, err := tok.SignedString(p.keys[p.signKID])
+	if err != nil {
+		p.t.Errorf("sign id_token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": raw})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// login 走一遍完整的授权码 + PKCE 流程
+func (p *mockOIDCProvider) login(c *OIDCClient, claims jwt.MapClaims) (*OIDCClaims, error) {
+	p.t.Helper()
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	authURL, err := c.AuthCodeURL(context.Background(), "state-1", "nonce-1", challenge)
+	if err != nil {
+		p.t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code := p.authorize(authURL, claims)
+	return c.Exchange(context.Background(), code, verifier, "nonce-1")
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	p := newMockOIDCProvider(t)
+	c := p.client()
+	authURL, err := c.AuthCodeURL(context.Background(), "st", "nn", "ch")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if u.Host != strings.TrimPrefix(p.srv.URL, "http://") || u.Path != "/authorize" {
+		t.Fatalf("authorization endpoint = %s", authURL)
+	}
+	want := map[string]string{
+		"state": "st", "nonce": "nn", "code_challenge": "ch", "code_challenge_method": "S256",
+		"scope": "openid email profile", "client_id": mockClientID, "redirect_uri": mockRedirectURL,
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Fatalf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+
+	// 发现文档在有效期内复用，过期后重新获取
+	if _, err := c.AuthCodeURL(context.Background(), "st", "nn", "ch"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := p.hits(); n != 1 {
+		t.Fatalf("discovery fetched %d times, want 1", n)
+	}
+	p.advance(oidcDiscoveryTTL + time.Second)
+	if _, err := c.AuthCodeURL(context.Background(), "st", "nn", "ch"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := p.hits(); n != 2 {
+		t.Fatalf("discovery fetched %d times after TTL, want 2", n)
+	}
+}
+
+func TestOIDCDiscoveryRejected(t *testing.T) {
+	t.Run("issuer mismatch", func(t *testing.T) {
+		p := newMockOIDCProvider(t)
+		p.issuer = "https://evil.example"
+		if _, err := p.client().AuthCodeURL(context.Background(), "st", "nn", "ch"); err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+			t.Fatalf("err = %v, want issuer mismatch", err)
+		}
+	})
+	t.Run("incomplete", func(t *testing.T) {
+		p := newMockOIDCProvider(t)
+		p.incomplete = true
+		if _, err := p.client().AuthCodeURL(context.Background(), "st", "nn", "ch"); err == nil || !strings.Contains(err.Error(), "incomplete") {
+			t.Fatalf("err = %v, want incomplete discovery", err)
+		}
+	})
+}
+
+func TestOIDCExchangePKCE(t *testing.T) {
+	p := newMockOIDCProvider(t)
+	c := p.client()
+	claims, err := p.login(c, jwt.MapClaims{"sub": "u-1", "email": "Ann@Example.com", "email_verified": "true", "preferred_username": "ann"})
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "u-1" || claims.Email != "Ann@Example.com" || !claims.IsEmailVerified() || claims.PreferredUsername != "ann" {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := c.AuthCodeURL(context.Background(), "st", "nonce-1", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := p.authorize(authURL, jwt.MapClaims{"sub": "u-1"})
+	other, _, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Exchange(context.Background(), code, other, "nonce-1"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("wrong verifier: err = %v, want invalid_grant", err)
+	}
+	// 校验失败后 code 已作废
+	if _, err := c.Exchange(context.Background(), code, verifier, "nonce-1"); err == nil {
+		t.Fatal("reused code accepted")
+	}
+}
+
+func TestOIDCVerifyRejected(t *testing.T) {
+	p := newMockOIDCProvider(t)
+	c := p.client()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := c.AuthCodeURL(context.Background(), "st", "nonce-1", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := p.authorize(authURL, jwt.MapClaims{"sub": "u-1"})
+	if _, err := c.Exchange(context.Background(), code, verifier, "nonce-2"); err == nil || !strings.Contains(err.Error(), "nonce mismatch") {
+		t.Fatalf("err = %v, want nonce mismatch", err)
+	}
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"no subject", jwt.MapClaims{"email": "a@example.com"}},
+		{"wrong audience", jwt.MapClaims{"sub": "u-1", "aud": "other-app"}},
+		{"wrong issuer", jwt.MapClaims{"sub": "u-1", "iss": "https://evil.example"}},
+		{"expired", jwt.MapClaims{"sub": "u-1", "exp": p.now().Add(-2 * time.Minute).Unix()}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := p.login(c, tc.claims); err == nil {
+				t.Fatal("id_token accepted")
+			}
+		})
+	}
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	p := newMockOIDCProvider(t)
+	c := p.client()
+	sub := jwt.MapClaims{"sub": "u-1"}
+	for i := 0; i < 2; i++ {
+		if _, err := p.login(c, sub); err != nil {
+			t.Fatalf("login %d: %v", i, err)
+		}
+	}
+	if _, n := p.hits(); n != 1 {
+		t.Fatalf("jwks fetched %d times, want 1", n)
+	}
+
+	// 轮换后遇到未知 kid，距上次刷新不足间隔时不重复拉取 JWKS
+	p.rotate()
+	if _, err := p.login(c, sub); !errors.Is(err, ErrOIDCUnknownKey) {
+		t.Fatalf("err = %v, want ErrOIDCUnknownKey", err)
+	}
+	if _, n := p.hits(); n != 1 {
+		t.Fatalf("jwks fetched %d times within refresh interval, want 1", n)
+	}
+
+	p.advance(oidcJWKSMinRefresh + time.Second)
+	if _, err := p.login(c, sub); err != nil {
+		t.Fatalf("login after refresh: %v", err)
+	}
+	if _, n := p.hits(); n != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", n)
+	}
+	if _, err := p.login(c, sub); err != nil {
+		t.Fatalf("login with cached rotated key: %v", err)
+	}
+	if _, n := p.hits(); n != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", n)
+	}
+}
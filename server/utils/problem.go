@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:todolist:problem:"
+)
+
+// Problem RFC 7807 错误响应，code/key/errors 为扩展成员，与旧格式保持一致
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail"`
+	Instance string       `json:"instance,omitempty"`
+	Code     int          `json:"code"`
+	Key      string       `json:"key"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// WantsProblem 客户端在 Accept 中显式接受 application/problem+json 时返回 true
+func WantsProblem(c *gin.Context) bool {
+	return AcceptsMediaType(c.GetHeader("Accept"), ProblemContentType)
+}
+
+// AcceptsMediaType Accept 中显式列出 mediaType 且 q > 0 时返回 true，不匹配通配符
+func AcceptsMediaType(accept, mediaType string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mt != mediaType {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(q, 64); err != nil || f <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func writeProblem(c *gin.Context, status int, e *JsonErrStruct) {
+	titleKey := "status." + strconv.Itoa(status)
+	title := Translate(RequestLocale(c), titleKey)
+	if title == titleKey {
+		title = http.StatusText(status)
+	}
+	c.Header("Content-Type", ProblemContentType+"; charset=utf-8")
+	c.Render(status, render.JSON{Data: &Problem{
+		Type:     problemTypePrefix + e.Key,
+		Title:    title,
+		Status:   status,
+		Detail:   e.Msg,
+		Instance: c.GetString("request_id"),
+		Code:     e.Code,
+		Key:      e.Key,
+		Errors:   e.Errors,
+	}})
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func testContext(accept string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+	return c, w
+}
+
+func TestWantsProblem(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"Application/Problem+JSON", true},
+		{"application/json, application/problem+json", true},
+		{"application/json,application/problem+json;q=0.5", true},
+		{" application/problem+json ; q=1 ", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+json;q=0.0", false},
+		{"application/problem+json;q=-1", false},
+		{"application/problem+json;q=abc", false},
+		{"application/problem+json;q=0, application/problem+json", true},
+		{"application/problem+json;;", false},
+		{"application/problem", false},
+		{"application/problem+xml", false},
+		{";;;, ,", false},
+	}
+	for _, tc := range cases {
+		c, _ := testContext(tc.accept)
+		if got := WantsProblem(c); got != tc.want {
+			t.Errorf("WantsProblem(%q) = %v, want %v", tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestReturnErrorNegotiation(t *testing.T) {
+	t.Run("problem", func(t *testing.T) {
+		c, w := testContext("application/problem+json")
+		c.Set("request_id", "req-1")
+		ReturnError(c, ErrCodeNotFound, "task.not_found")
+		if ct := w.Header().Get("Content-Type"); ct != ProblemContentType+"; charset=utf-8" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if v := w.Header().Get("Vary"); v != "Accept" {
+			t.Errorf("Vary = %q", v)
+		}
+		var p Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Status != w.Code || p.Type != problemTypePrefix+"task.not_found" || p.Key != "task.not_found" ||
+			p.Code != ErrCodeNotFound || p.Instance != "req-1" || p.Title == "" || p.Detail == "" {
+			t.Errorf("unexpected problem: %+v (status %d)", p, w.Code)
+		}
+	})
+	t.Run("legacy json", func(t *testing.T) {
+		c, w := testContext("application/json")
+		ReturnError(c, ErrCodeNotFound, "task.not_found")
+		if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var body JsonErrStruct
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Code != ErrCodeNotFound || body.Key != "task.not_found" {
+			t.Errorf("unexpected body: %+v", body)
+		}
+	})
+	t.Run("field errors", func(t *testing.T) {
+		c, w := testContext("application/problem+json")
+		c.Request.Header.Set("Accept-Language", "en-US")
+		ReturnFieldErrors(c, ErrCodeValidation, []FieldError{NewFieldError("title", "required", "validation.required")}, "common.validation_failed")
+		var p Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Errors) != 1 || p.Errors[0].Field != "title" || p.Errors[0].Message == "" || p.Errors[0].Message == "validation.required" {
+			t.Errorf("unexpected errors: %+v", p.Errors)
+		}
+	})
+}
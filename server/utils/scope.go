@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+)
+
+// 角色
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// 权限范围，格式为 资源:级别，级别从低到高为 read < write < admin
+const (
+	ScopeTasksRead     = "tasks:read"
+	ScopeTasksWrite    = "tasks:write"
+	ScopeProjectsRead  = "projects:read"
+	ScopeProjectsWrite = "projects:write"
+	ScopeProjectsAdmin = "projects:admin"
+	ScopeAccountRead   = "account:read"
+	ScopeAccountWrite  = "account:write"
+	ScopeAdminSystem   = "admin:system" // 系统管理，隐含全部权限
+)
+
+var AllScopes = []string{
+	ScopeTasksRead, ScopeTasksWrite,
+	ScopeProjectsRead, ScopeProjectsWrite, ScopeProjectsAdmin,
+	ScopeAccountRead, ScopeAccountWrite,
+	ScopeAdminSystem,
+}
+
+var roleScopes = map[string][]string{
+	RoleUser: {
+		ScopeTasksWrite,
+		ScopeProjectsAdmin,
+		ScopeAccountWrite,
+	},
+	RoleAdmin: {ScopeAdminSystem},
+}
+
+// 早期个人访问令牌使用的权限名
+var legacyScopes = map[string][]string{
+	"read:tasks":  {ScopeTasksRead, ScopeProjectsRead},
+	"write:tasks": {ScopeTasksWrite, ScopeProjectsWrite},
+	"admin":       {ScopeTasksWrite, ScopeProjectsAdmin, ScopeAccountWrite},
+}
+
+var scopeLevels = []string{"read", "write", "admin"}
+
+// IsValidRole 校验角色名
+func IsValidRole(role string) bool {
+	_, ok := roleScopes[role]
+	return ok
+}
+
+// RoleScopes 角色对应的权限范围，未知或空角色按普通用户处理
+func RoleScopes(role string) []string {
+	if s, ok := roleScopes[role]; ok {
+		return s
+	}
+	return roleScopes[RoleUser]
+}
+
+// IsValidScope 校验权限名，兼容旧权限名
+func IsValidScope(scope string) bool {
+	_, legacy := legacyScopes[scope]
+	return legacy || slices.Contains(AllScopes, scope)
+}
+
+// ExpandScopes 把旧权限名展开为当前权限名
+func ExpandScopes(scopes []string) []string {
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if ex, ok := legacyScopes[s]; ok {
+			out = append(out, ex...)
+		} else {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// HasScope 判断已授予的权限是否满足要求：admin:system 满足一切，同一资源的高级别满足低级别
+func HasScope(granted []string, required string) bool {
+	res, level, ok := strings.Cut(required, ":")
+	need := slices.Index(scopeLevels, level)
+	for _, g := range granted {
+		if g == ScopeAdminSystem || g == required {
+			return true
+		}
+		if !ok || need < 0 {
+			continue
+		}
+		gres, glevel, _ := strings.Cut(g, ":")
+		if gres == res && slices.Index(scopeLevels, glevel) >= need {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitScopes 把令牌权限限制在角色权限之内
+func LimitScopes(scopes []string, role string) []string {
+	allowed := RoleScopes(role)
+	out := make([]string, 0, len(scopes))
+	for _, s := range ExpandScopes(scopes) {
+		if HasScope(allowed, s) && !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+	_ "time/tzdata" // 内置时区数据，不依赖宿主机 zoneinfo
+)
+
+const DefaultTimezone = "Asia/Shanghai"
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// LoadLocation 校验并加载 IANA 时区名，拒绝空值与 Local
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// UserLocation 加载用户时区，非法时回退默认时区
+func UserLocation(name string) *time.Location {
+	if loc, err := LoadLocation(name); err == nil {
+		return loc
+	}
+	loc, _ := time.LoadLocation(DefaultTimezone)
+	return loc
+}
+
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// ParseUserTime 解析时间：带时区偏移的 RFC3339 原样使用，不带偏移的按用户时区解释；
+// 只有日期时 dateOnly 为 true，返回当天 23:59:59
+func ParseUserTime(s string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	s = strings.TrimSpace(s)
+	if t, err = time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	d, err := time.ParseInLocation(time.DateOnly, s, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	y, m, day := d.Date()
+	return time.Date(y, m, day, 23, 59, 59, 0, loc), true, nil
+}
+
+// StartOfDay 用户时区内当天零点
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// StartOfWeek 用户时区内本周第一天零点，weekStart 为一周起始日
+func StartOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	day := StartOfDay(t, loc)
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA-1 测试向量，密钥为 ASCII "12345678901234567890"
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "94287082"},
+	{1111111109, "07081804"},
+	{1111111111, "14050471"},
+	{1234567890, "89005924"},
+	{2000000000, "69279037"},
+	{20000000000, "65353130"},
+}
+
+var rfc6238Secret = b32.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	totp := TOTP{Period: 30, Digits: 8}
+	for _, v := range rfc6238Vectors {
+		got, err := totp.Code(rfc6238Secret, time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", v.unix, err)
+		}
+		if got != v.code {
+			t.Errorf("Code(%d) = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestTOTPValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	totp := TOTP{Period: 30, Digits: 8, Skew: 1, Now: func() time.Time { return now }}
+	step := now.Unix() / 30
+	code := func(at time.Time) string {
+		c, err := totp.Code(rfc6238Secret, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	cases := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current", "14050471", step, true},
+		{"previous step", code(now.Add(-30 * time.Second)), step - 1, true},
+		{"next step", code(now.Add(30 * time.Second)), step + 1, true},
+		{"outside skew", code(now.Add(-60 * time.Second)), 0, false},
+		{"surrounding spaces", " 14050471 ", step, true},
+		{"wrong length", "1405047", 0, false},
+		{"wrong code", "00000000", 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotStep, ok := totp.Validate(rfc6238Secret, tc.code)
+			if ok != tc.wantOK || gotStep != tc.wantStep {
+				t.Errorf("Validate(%q) = (%d, %v), want (%d, %v)", tc.code, gotStep, ok, tc.wantStep, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestTOTPValidateBadSecret(t *testing.T) {
+	totp := TOTP{Period: 30, Digits: 6, Skew: 1, Now: time.Now}
+	if _, ok := totp.Validate("not base32!", "123456"); ok {
+		t.Error("Validate accepted an invalid secret")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	s, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := b32.DecodeString(s)
+	if err != nil || len(key) != 20 {
+		t.Errorf("secret %q decodes to %d bytes, err %v", s, len(key), err)
+	}
+}
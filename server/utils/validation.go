@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError 字段级校验错误，Message 在响应时按请求语言翻译
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	key     string
+	args    []any
+}
+
+func NewFieldError(field, rule, key string, args ...any) FieldError {
+	return FieldError{Field: field, Rule: rule, key: key, args: args}
+}
+
+func init() {
+	// 校验错误中的字段名使用 json/form 标签名，与请求体保持一致
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
+
+// BindErrors 将 ShouldBind 返回的错误转换为字段级错误
+func BindErrors(err error) []FieldError {
+	var (
+		ve validator.ValidationErrors
+		te *json.UnmarshalTypeError
+		se *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &ve):
+		out := make([]FieldError, 0, len(ve))
+		for _, fe := range ve {
+			out = append(out, validatorFieldError(fe))
+		}
+		return out
+	case errors.As(err, &te):
+		return []FieldError{NewFieldError(te.Field, "type", "validation.type", te.Type.String())}
+	case errors.As(err, &se):
+		return []FieldError{NewFieldError("", "json", "validation.json")}
+	}
+	return []FieldError{NewFieldError("", "format", "validation.json")}
+}
+
+func validatorFieldError(fe validator.FieldError) FieldError {
+	field := fieldPath(fe.Namespace())
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required", "email", "numeric":
+		return NewFieldError(field, fe.Tag(), "validation."+fe.Tag())
+	case "min", "max", "len":
+		// 字符串按长度，数值按大小
+		if fe.Kind() == reflect.String {
+			return NewFieldError(field, fe.Tag(), "validation."+fe.Tag()+"_len", param)
+		}
+		return NewFieldError(field, fe.Tag(), "validation."+fe.Tag(), param)
+	case "gt", "gte", "lt", "lte":
+		return NewFieldError(field, fe.Tag(), "validation."+fe.Tag(), param)
+	case "oneof":
+		return NewFieldError(field, fe.Tag(), "validation.oneof", strings.ReplaceAll(param, " ", ", "))
+	case "eqfield":
+		return NewFieldError(field, fe.Tag(), "validation.eqfield", param)
+	case "required_with":
+		return NewFieldError(field, fe.Tag(), "validation.required_with", param)
+	}
+	return NewFieldError(field, fe.Tag(), "validation.invalid")
+}
+
+// fieldPath 去掉命名空间中的顶层结构体名，如 CreateTaskRequest.title -> title
+func fieldPath(ns string) string {
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return ns
+}
+
+// ReturnBindError 请求参数绑定失败时返回字段级错误
+func ReturnBindError(c *gin.Context, err error) {
+	ReturnFieldErrors(c, ErrCodeValidation, BindErrors(err), "common.validation_failed")
+}
+
+// ReturnFieldErrors 返回附带字段级错误的错误响应
+func ReturnFieldErrors(c *gin.Context, code int, fields []FieldError, key string, args ...any) {
+	loc := RequestLocale(c)
+	out := make([]FieldError, len(fields))
+	for i, f := range fields {
+		f.Message = Translate(loc, f.key, f.args...)
+		out[i] = f
+	}
+	writeError(c, code, &JsonErrStruct{
+		Code:   code,
+		Key:    key,
+		Msg:    Translate(loc, key, args...),
+		Errors: out,
+	})
+}
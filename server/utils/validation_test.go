@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindItem struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type bindRequest struct {
+	Title    string     `json:"title"    binding:"required,min=2,max=5"`
+	Email    string     `json:"email"    binding:"omitempty,email"`
+	Priority int        `json:"priority" binding:"omitempty,min=1,max=5"`
+	Count    int        `json:"count"    binding:"omitempty,gt=0"`
+	Kind     string     `json:"kind"     binding:"omitempty,oneof=a b c"`
+	Code     string     `json:"code"     binding:"omitempty,len=4"`
+	Password string     `json:"password"`
+	Confirm  string     `json:"confirm"  binding:"omitempty,eqfield=Password"`
+	Pattern  string     `json:"pattern"  binding:"omitempty,alphanum"`
+	Items    []bindItem `json:"items"    binding:"omitempty,dive"`
+}
+
+func bindJSON(t *testing.T, body string) error {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	var req bindRequest
+	return c.ShouldBindJSON(&req)
+}
+
+func TestBindErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want []FieldError
+	}{
+		{"required", `{}`,
+			[]FieldError{{Field: "title", Rule: "required", key: "validation.required"}}},
+		{"string min uses length", `{"title":"a"}`,
+			[]FieldError{{Field: "title", Rule: "min", key: "validation.min_len", args: []any{"2"}}}},
+		{"string max uses length", `{"title":"abcdef"}`,
+			[]FieldError{{Field: "title", Rule: "max", key: "validation.max_len", args: []any{"5"}}}},
+		{"numeric min uses value", `{"title":"ok","priority":-1}`,
+			[]FieldError{{Field: "priority", Rule: "min", key: "validation.min", args: []any{"1"}}}},
+		{"numeric max uses value", `{"title":"ok","priority":9}`,
+			[]FieldError{{Field: "priority", Rule: "max", key: "validation.max", args: []any{"5"}}}},
+		{"string len", `{"title":"ok","code":"12"}`,
+			[]FieldError{{Field: "code", Rule: "len", key: "validation.len_len", args: []any{"4"}}}},
+		{"gt", `{"title":"ok","count":-3}`,
+			[]FieldError{{Field: "count", Rule: "gt", key: "validation.gt", args: []any{"0"}}}},
+		{"email", `{"title":"ok","email":"nope"}`,
+			[]FieldError{{Field: "email", Rule: "email", key: "validation.email"}}},
+		{"oneof lists choices", `{"title":"ok","kind":"z"}`,
+			[]FieldError{{Field: "kind", Rule: "oneof", key: "validation.oneof", args: []any{"a, b, c"}}}},
+		{"eqfield", `{"title":"ok","password":"x","confirm":"y"}`,
+			[]FieldError{{Field: "confirm", Rule: "eqfield", key: "validation.eqfield", args: []any{"Password"}}}},
+		{"unmapped tag", `{"title":"ok","pattern":"a-b"}`,
+			[]FieldError{{Field: "pattern", Rule: "alphanum", key: "validation.invalid"}}},
+		{"nested path", `{"title":"ok","items":[{"name":"x"},{}]}`,
+			[]FieldError{{Field: "items[1].name", Rule: "required", key: "validation.required"}}},
+		{"multiple fields", `{"title":"a","priority":9}`,
+			[]FieldError{
+				{Field: "title", Rule: "min", key: "validation.min_len", args: []any{"2"}},
+				{Field: "priority", Rule: "max", key: "validation.max", args: []any{"5"}},
+			}},
+		{"type mismatch", `{"title":"ok","priority":"high"}`,
+			[]FieldError{{Field: "priority", Rule: "type", key: "validation.type", args: []any{"int"}}}},
+		{"syntax error", `{"title":`,
+			[]FieldError{{Field: "", Rule: "format", key: "validation.json"}}},
+		{"malformed json", `{"title" "ok"}`,
+			[]FieldError{{Field: "", Rule: "json", key: "validation.json"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := bindJSON(t, tc.body)
+			if err == nil {
+				t.Fatal("expected a bind error")
+			}
+			got := BindErrors(err)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("BindErrors(%s) = %+v, want %+v", tc.body, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBindErrorsValid(t *testing.T) {
+	if err := bindJSON(t, `{"title":"ok","priority":3,"kind":"b","items":[{"name":"x"}]}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	cases := map[string]string{
+		"CreateTaskRequest.title": "title",
+		"Req.items[0].name":       "items[0].name",
+		"title":                   "title",
+		"":                        "",
+	}
+	for in, want := range cases {
+		if got := fieldPath(in); got != want {
+			t.Errorf("fieldPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}